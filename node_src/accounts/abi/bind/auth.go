@@ -179,3 +179,13 @@ func NewClefTransactor(clef *external.ExternalSigner, account accounts.Account)
 		Context: context.Background(),
 	}
 }
+
+// NewKeyedMetaSigner is a utility method to easily create a meta transaction signer
+// covering feePercent of the fee of the transactions with a single private key,
+// until blockNumLimit. FeePercent is in hundredths of a percent, 10000 covering the
+// whole fee.
+func NewKeyedMetaSigner(key *ecdsa.PrivateKey, chainID *big.Int, feePercent uint64, blockNumLimit uint64) MetaSignerFn {
+	return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return types.SignMetaTx(tx, from, feePercent, blockNumLimit, chainID, key)
+	}
+}
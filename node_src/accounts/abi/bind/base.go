@@ -37,6 +37,11 @@ import (
 // sign the transaction before submission.
 type SignerFn func(common.Address, *types.Transaction) (*types.Transaction, error)
 
+// MetaSignerFn is a signer function callback when a contract requires a method to
+// be sent as a meta transaction, wrapping the fee cover of a fee address into the
+// transaction of the given sender before the sender signs it.
+type MetaSignerFn func(common.Address, *types.Transaction) (*types.Transaction, error)
+
 // CallOpts is the collection of options to fine tune a contract call request.
 type CallOpts struct {
 	Pending     bool            // Whether to operate on the pending state or the last known one
@@ -61,6 +66,8 @@ type TransactOpts struct {
 	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
 
 	NoSend bool // Do all transact steps but do not send the transaction
+
+	MetaSigner MetaSignerFn // Method to cover the fee with a fee address as a meta transaction (nil = no cover)
 }
 
 // FilterOpts is the collection of options to fine tune filtering for events
@@ -362,12 +369,15 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	if opts.GasPrice != nil && (opts.GasFeeCap != nil || opts.GasTipCap != nil) {
 		return nil, errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
 	}
-	// Create the transaction
+	if opts.MetaSigner != nil && (opts.GasFeeCap != nil || opts.GasTipCap != nil) {
+		return nil, types.ErrMetaTxType
+	}
+	// Create the transaction, meta transactions need a fixed gas price
 	var (
 		rawTx *types.Transaction
 		err   error
 	)
-	if opts.GasPrice != nil {
+	if opts.GasPrice != nil || opts.MetaSigner != nil {
 		rawTx, err = c.createLegacyTx(opts, contract, input)
 	} else {
 		// Only query for basefee if gasPrice not specified
@@ -383,6 +393,12 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	if err != nil {
 		return nil, err
 	}
+	// Cover the fee with the fee address, the sender signs the wrapped transaction
+	if opts.MetaSigner != nil {
+		if rawTx, err = opts.MetaSigner(opts.From, rawTx); err != nil {
+			return nil, err
+		}
+	}
 	// Sign the transaction and schedule it for execution
 	if opts.Signer == nil {
 		return nil, errors.New("no signer to authorize the transaction with")
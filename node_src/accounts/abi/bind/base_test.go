@@ -313,6 +313,36 @@ func TestTransactGasFee(t *testing.T) {
 	assert.True(mt.suggestGasPriceCalled)
 }
 
+func TestTransactMeta(t *testing.T) {
+	assert := assert.New(t)
+
+	key, _ := crypto.GenerateKey()
+	from := common.HexToAddress("0x5e11de7")
+
+	// Meta transactions stay legacy after London
+	mt := &mockTransactor{baseFee: big.NewInt(100), gasPrice: big.NewInt(5)}
+	bc := bind.NewBoundContract(common.Address{}, abi.ABI{}, nil, mt, nil)
+	opts := &bind.TransactOpts{From: from, Signer: mockSign, MetaSigner: bind.NewKeyedMetaSigner(key, big.NewInt(1), 5000, 100)}
+	tx, err := bc.RawTransact(opts, []byte{0x01, 0x02})
+	assert.Nil(err)
+	assert.Equal(uint8(types.LegacyTxType), tx.Type())
+	assert.True(mt.suggestGasPriceCalled)
+	assert.True(types.IsMetaTransaction(tx.Data()))
+
+	metadata, err := types.DecodeMetaData(tx.Data(), big.NewInt(1))
+	assert.Nil(err)
+	assert.Equal([]byte{0x01, 0x02}, metadata.Payload)
+	assert.Equal(uint64(5000), metadata.FeePercent)
+
+	addr, err := metadata.ParseMetaData(tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), metadata.Payload, from, big.NewInt(1))
+	assert.Nil(err)
+	assert.Equal(crypto.PubkeyToAddress(key.PublicKey), addr)
+
+	opts.GasTipCap = big.NewInt(1)
+	_, err = bc.RawTransact(opts, nil)
+	assert.Equal(types.ErrMetaTxType, err)
+}
+
 func unpackAndCheck(t *testing.T, bc *bind.BoundContract, expected map[string]interface{}, mockLog types.Log) {
 	received := make(map[string]interface{})
 	if err := bc.UnpackLogIntoMap(received, "received", mockLog); err != nil {
@@ -41,6 +41,8 @@ const (
 	MimetypeTypedData         = "data/typed"
 	MimetypeClique            = "application/x-clique-header"
 	MimetypeCongress          = "application/x-congress-header"
+	MimetypeCongressVote      = "application/x-congress-vote"
+	MimetypeMetaTransaction   = "application/x-meta-transaction"
 	MimetypeTextPlain         = "text/plain"
 )
 
@@ -0,0 +1,153 @@
+// gasprice-backtest is a utility that records snapshots of the pending pool of a
+// node, and replays them against the chain of a local datadir to evaluate the gas
+// price prediction and the jam index with alternative configs.
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/fdlimit"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+)
+
+var app *cli.App
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "gas price prediction and jam index backtesting tool")
+	app.Commands = []cli.Command{
+		commandRecord,
+		commandReplay,
+	}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+// Commonly used command line flags.
+var (
+	nodeURLFlag = cli.StringFlag{
+		Name:  "rpc",
+		Value: "http://localhost:8545",
+		Usage: "The rpc endpoint of the node whose pool is recorded",
+	}
+	snapshotsFlag = cli.StringFlag{
+		Name:  "snapshots",
+		Value: "pool-snapshots.rlp",
+		Usage: "The file the pool snapshots are recorded to and replayed from",
+	}
+	intervalFlag = cli.DurationFlag{
+		Name:  "interval",
+		Value: time.Duration(core.DefaultJamConfig.PeriodsSecs) * time.Second,
+		Usage: "How often the pool is recorded",
+	}
+	countFlag = cli.IntFlag{
+		Name:  "count",
+		Usage: "The number of snapshots to record, unlimited if zero",
+	}
+	dataDirFlag = cli.StringFlag{
+		Name:  "datadir",
+		Value: node.DefaultDataDir(),
+		Usage: "The data directory of the node whose chain is replayed",
+	}
+	configFlag = cli.StringFlag{
+		Name:  "config",
+		Usage: "JSON file listing the configs to compare, each overriding the flags",
+	}
+	statBlocksFlag = cli.IntFlag{
+		Name:  "statBlocks",
+		Value: ethconfig.FullNodeGPO.Blocks,
+		Usage: "The number of blocks the average tx count is taken over",
+	}
+	minTipFlag = cli.Uint64Flag{
+		Name:  "minTip",
+		Value: params.GWei,
+		Usage: "The minimum tip in wei accepted by the pool",
+	}
+	minTxCntFlag = cli.IntFlag{
+		Name:  "minTxCnt",
+		Value: ethconfig.DefaultPredictionConfig.MinTxCntPerBlock,
+		Usage: "The minimum average tx count per block",
+	}
+	fastFactorFlag = cli.IntFlag{
+		Name:  "fastFactor",
+		Value: ethconfig.DefaultPredictionConfig.FastFactor,
+		Usage: "How many times the average tx count the fast tip is taken at",
+	}
+	medianFactorFlag = cli.IntFlag{
+		Name:  "medianFactor",
+		Value: ethconfig.DefaultPredictionConfig.MedianFactor,
+		Usage: "How many times the average tx count the median tip is taken at",
+	}
+	lowFactorFlag = cli.IntFlag{
+		Name:  "lowFactor",
+		Value: ethconfig.DefaultPredictionConfig.LowFactor,
+		Usage: "How many times the average tx count the low tip is taken at",
+	}
+	minMedianIndexFlag = cli.IntFlag{
+		Name:  "minMedianIndex",
+		Value: ethconfig.DefaultPredictionConfig.MinMedianIndex,
+		Usage: "The minimum index in the pending txs the median tip is taken at",
+	}
+	minLowIndexFlag = cli.IntFlag{
+		Name:  "minLowIndex",
+		Value: ethconfig.DefaultPredictionConfig.MinLowIndex,
+		Usage: "The minimum index in the pending txs the low tip is taken at",
+	}
+	fastPercentileFlag = cli.IntFlag{
+		Name:  "fastPercentile",
+		Value: ethconfig.DefaultPredictionConfig.FastPercentile,
+		Usage: "The percentile of the pending txs the fast tip is taken at when there are few",
+	}
+	medianPercentileFlag = cli.IntFlag{
+		Name:  "medianPercentile",
+		Value: ethconfig.DefaultPredictionConfig.MeidanPercentile,
+		Usage: "The percentile of the pending txs the median tip is taken at when there are few",
+	}
+	maxPendingSecsFlag = cli.IntFlag{
+		Name:  "maxPendingSecs",
+		Value: ethconfig.DefaultPredictionConfig.MaxValidPendingSecs,
+		Usage: "The pending txs waiting for longer are ignored by the prediction",
+	}
+	jamSecsFlag = cli.IntFlag{
+		Name:  "jamSecs",
+		Value: core.DefaultJamConfig.JamSecs,
+		Usage: "How many seconds a pending tx has to wait for to count as jammed",
+	}
+	underPricedFactorFlag = cli.IntFlag{
+		Name:  "underPricedFactor",
+		Value: core.DefaultJamConfig.UnderPricedFactor,
+		Usage: "The weight of the underpriced txs in the jam index",
+	}
+	pendingFactorFlag = cli.IntFlag{
+		Name:  "pendingFactor",
+		Value: core.DefaultJamConfig.PendingFactor,
+		Usage: "The weight of the jammed pending txs in the jam index",
+	}
+	maxJamPendingSecsFlag = cli.IntFlag{
+		Name:  "maxJamPendingSecs",
+		Value: core.DefaultJamConfig.MaxValidPendingSecs,
+		Usage: "The pending txs waiting for longer are ignored by the jam index",
+	}
+)
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	fdlimit.Raise(2048)
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandRecord = cli.Command{
+	Name:  "record",
+	Usage: "Record snapshots of the pending pool of a node",
+	Flags: []cli.Flag{
+		nodeURLFlag,
+		snapshotsFlag,
+		intervalFlag,
+		countFlag,
+	},
+	Action: utils.MigrateFlags(record),
+}
+
+// poolRecorder takes the snapshots of the pending pool of a node, tracking when
+// each pending tx was first seen.
+type poolRecorder struct {
+	client *rpc.Client
+	seen   map[common.Hash]uint64
+	noJam  bool // whether the node doesn't report the jam index history
+}
+
+func record(ctx *cli.Context) error {
+	client, err := rpc.Dial(ctx.String(nodeURLFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to connect to Ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	var (
+		recorder = &poolRecorder{client: client, seen: make(map[common.Hash]uint64)}
+		path     = ctx.String(snapshotsFlag.Name)
+		count    = ctx.Int(countFlag.Name)
+		tick     = time.NewTicker(ctx.Duration(intervalFlag.Name))
+		sigc     = make(chan os.Signal, 1)
+	)
+	defer tick.Stop()
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	for n := 0; count == 0 || n < count; {
+		select {
+		case <-tick.C:
+			snap, err := recorder.snapshot()
+			if err != nil {
+				log.Warn("Failed to record the pool", "err", err)
+				continue
+			}
+			if err := appendSnapshot(path, snap); err != nil {
+				return err
+			}
+			n++
+			log.Info("Recorded the pool", "number", snap.Number, "pending", len(snap.Txs), "underpriced", snap.UnderPriced, "snapshots", n)
+		case <-sigc:
+			return nil
+		}
+	}
+	return nil
+}
+
+// snapshot records the current pending pool of the node.
+func (r *poolRecorder) snapshot() (*poolSnapshot, error) {
+	var number hexutil.Uint64
+	if err := r.client.Call(&number, "eth_blockNumber"); err != nil {
+		return nil, err
+	}
+	var content map[string]map[string]map[string]*types.Transaction
+	if err := r.client.Call(&content, "txpool_content"); err != nil {
+		return nil, err
+	}
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	snap := &poolSnapshot{Number: uint64(number), Time: now}
+
+	if !r.noJam {
+		var samples []*core.JamSample
+		if err := r.client.Call(&samples, "txpool_jamIndexHistory", 1); err != nil {
+			log.Warn("Jam index history unavailable, recording no underpriced txs", "err", err)
+			r.noJam = true
+		} else if len(samples) > 0 {
+			snap.UnderPriced = uint64(samples[len(samples)-1].UnderPriced)
+		}
+	}
+	seen := make(map[common.Hash]uint64)
+	for _, txs := range content["pending"] {
+		for _, tx := range txs {
+			hash := tx.Hash()
+			if _, ok := seen[hash]; ok {
+				continue
+			}
+			first, ok := r.seen[hash]
+			if !ok {
+				first = now
+			}
+			seen[hash] = first
+			snap.Txs = append(snap.Txs, tx)
+			snap.Seen = append(snap.Seen, first)
+		}
+	}
+	// Forget the txs which left the pool
+	r.seen = seen
+	return snap, nil
+}
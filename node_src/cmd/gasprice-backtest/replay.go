@@ -0,0 +1,368 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandReplay = cli.Command{
+	Name:  "replay",
+	Usage: "Replay the recorded pool snapshots against the chain of a local datadir",
+	Description: `
+Runs the gas price prediction and the jam index offline on every recorded pool
+snapshot, and reports how often the suggested tips got included within the
+expected number of blocks, and how the jam index correlates with the time the
+pending txs waited for. The base fee of the head stands in for the projected
+one, which depends on an external price and can't be replayed.`,
+	Flags: []cli.Flag{
+		dataDirFlag,
+		snapshotsFlag,
+		configFlag,
+		statBlocksFlag,
+		minTipFlag,
+		minTxCntFlag,
+		fastFactorFlag,
+		medianFactorFlag,
+		lowFactorFlag,
+		minMedianIndexFlag,
+		minLowIndexFlag,
+		fastPercentileFlag,
+		medianPercentileFlag,
+		maxPendingSecsFlag,
+		jamSecsFlag,
+		underPricedFactorFlag,
+		pendingFactorFlag,
+		maxJamPendingSecsFlag,
+	},
+	Action: utils.MigrateFlags(replay),
+}
+
+// tierNames are the names of the speed tiers, in the order of the suggestions.
+var tierNames = []string{"fast", "median", "low"}
+
+// backtestConfig is a set of prediction and jam index knobs to evaluate.
+type backtestConfig struct {
+	Name       string
+	StatBlocks int    // Number of blocks the average tx count is taken over
+	MinTip     uint64 // Minimum tip in wei accepted by the pool
+	Prediction gasprice.PredConfig
+	Jam        core.TxJamConfig
+}
+
+// blockInfo is what the inclusion of a suggested fee depends on in a block.
+type blockInfo struct {
+	baseFee  *big.Int
+	gasLimit uint64
+	gasUsed  uint64
+	minTip   *big.Int // Lowest tip effectively paid by a tx of the block, nil if none
+	txs      int
+}
+
+// chainData reads the blocks of the replayed chain, caching what the replay needs.
+type chainData struct {
+	db     ethdb.Database
+	head   uint64
+	blocks map[uint64]*blockInfo
+}
+
+// tierStats is the outcome of the suggestions of a speed tier.
+type tierStats struct {
+	evaluated int
+	included  int
+	tips      *big.Int // Sum of the suggested tips
+	blocks    uint64   // Sum of the expected blocks
+}
+
+// jamStats pairs the jam index of every snapshot with the mean time its pending
+// txs waited for, in blocks.
+type jamStats struct {
+	indexes []float64
+	delays  []float64
+}
+
+func replay(ctx *cli.Context) error {
+	configs, err := loadConfigs(ctx)
+	if err != nil {
+		return err
+	}
+	snaps, err := loadSnapshots(ctx.String(snapshotsFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to load pool snapshots: %v", err)
+	}
+	chaindata := filepath.Join(ctx.String(dataDirFlag.Name), "geth", "chaindata")
+	db, err := rawdb.NewLevelDBDatabaseWithFreezer(chaindata, 256, 256, filepath.Join(chaindata, "ancient"), "", true)
+	if err != nil {
+		return fmt.Errorf("failed to open chain database: %v", err)
+	}
+	defer db.Close()
+
+	hash := rawdb.ReadHeadBlockHash(db)
+	head := rawdb.ReadHeaderNumber(db, hash)
+	if head == nil {
+		return fmt.Errorf("no head block in %s", chaindata)
+	}
+	chain := &chainData{db: db, head: *head, blocks: make(map[uint64]*blockInfo)}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, cfg := range configs {
+		tiers, jam := backtest(chain, snaps, cfg)
+		fmt.Fprintf(out, "config %s (%d snapshots)\n", cfg.Name, len(snaps))
+		fmt.Fprintln(out, "tier\tevaluated\tincluded\trate\tavg tip (gwei)\tavg blocks")
+		for i, stats := range tiers {
+			if stats.evaluated == 0 {
+				fmt.Fprintf(out, "%s\t0\t0\t-\t-\t-\n", tierNames[i])
+				continue
+			}
+			avgTip, _ := new(big.Float).Quo(new(big.Float).SetInt(stats.tips), big.NewFloat(float64(stats.evaluated*params.GWei))).Float64()
+			fmt.Fprintf(out, "%s\t%d\t%d\t%.1f%%\t%.3f\t%.2f\n", tierNames[i], stats.evaluated, stats.included,
+				100*float64(stats.included)/float64(stats.evaluated), avgTip, float64(stats.blocks)/float64(stats.evaluated))
+		}
+		fmt.Fprintf(out, "jam index: %d samples, mean %.1f, correlation with the wait of the pending txs %.3f\n\n",
+			len(jam.indexes), mean(jam.indexes), correlation(jam.indexes, jam.delays))
+	}
+	return out.Flush()
+}
+
+// loadConfigs returns the config built from the flags, or the ones listed in the
+// config file, each overriding the flags.
+func loadConfigs(ctx *cli.Context) ([]*backtestConfig, error) {
+	base := backtestConfig{
+		Name:       "flags",
+		StatBlocks: ctx.Int(statBlocksFlag.Name),
+		MinTip:     ctx.Uint64(minTipFlag.Name),
+		Prediction: gasprice.PredConfig{
+			MinTxCntPerBlock:    ctx.Int(minTxCntFlag.Name),
+			FastFactor:          ctx.Int(fastFactorFlag.Name),
+			MedianFactor:        ctx.Int(medianFactorFlag.Name),
+			LowFactor:           ctx.Int(lowFactorFlag.Name),
+			MinMedianIndex:      ctx.Int(minMedianIndexFlag.Name),
+			MinLowIndex:         ctx.Int(minLowIndexFlag.Name),
+			FastPercentile:      ctx.Int(fastPercentileFlag.Name),
+			MeidanPercentile:    ctx.Int(medianPercentileFlag.Name),
+			MaxValidPendingSecs: ctx.Int(maxPendingSecsFlag.Name),
+		},
+		Jam: core.TxJamConfig{
+			PeriodsSecs:         core.DefaultJamConfig.PeriodsSecs,
+			JamSecs:             ctx.Int(jamSecsFlag.Name),
+			UnderPricedFactor:   ctx.Int(underPricedFactorFlag.Name),
+			PendingFactor:       ctx.Int(pendingFactorFlag.Name),
+			MaxValidPendingSecs: ctx.Int(maxJamPendingSecsFlag.Name),
+		},
+	}
+	var configs []*backtestConfig
+	if path := ctx.String(configFlag.Name); path == "" {
+		configs = append(configs, &base)
+	} else {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var raws []json.RawMessage
+		if err := json.Unmarshal(blob, &raws); err != nil {
+			return nil, fmt.Errorf("invalid config file: %v", err)
+		}
+		for i, raw := range raws {
+			cfg := base
+			cfg.Name = fmt.Sprintf("#%d", i)
+			if err := json.Unmarshal(raw, &cfg); err != nil {
+				return nil, fmt.Errorf("invalid config %d: %v", i, err)
+			}
+			configs = append(configs, &cfg)
+		}
+	}
+	for _, cfg := range configs {
+		if cfg.StatBlocks < 1 || cfg.Prediction.FastFactor < 1 || cfg.Jam.JamSecs < 1 {
+			return nil, fmt.Errorf("invalid config %s: statBlocks, fastFactor and jamSecs must be positive", cfg.Name)
+		}
+	}
+	return configs, nil
+}
+
+// backtest runs the prediction and the jam index of a config on every snapshot.
+func backtest(chain *chainData, snaps []*poolSnapshot, cfg *backtestConfig) ([]*tierStats, *jamStats) {
+	tiers := make([]*tierStats, len(tierNames))
+	for i := range tiers {
+		tiers[i] = &tierStats{tips: new(big.Int)}
+	}
+	jam := new(jamStats)
+	minTip := new(big.Int).SetUint64(cfg.MinTip)
+
+	for _, snap := range snaps {
+		head := chain.block(snap.Number)
+		if head == nil {
+			continue
+		}
+		ages := make(map[*types.Transaction]time.Duration, len(snap.Txs))
+		for i, tx := range snap.Txs {
+			ages[tx] = snap.age(i)
+		}
+		// Gas price prediction
+		avgTxCnt := chain.avgTxCnt(snap.Number, cfg.StatBlocks)
+		if avgTxCnt < cfg.Prediction.MinTxCntPerBlock {
+			avgTxCnt = cfg.Prediction.MinTxCntPerBlock
+		}
+		pending := gasprice.FilterPending(append(gasprice.TxByPrice{}, snap.Txs...), head.gasLimit, cfg.Prediction.MaxValidPendingSecs,
+			func(tx *types.Transaction) time.Duration { return ages[tx] })
+		for i, fee := range gasprice.SuggestFees(cfg.Prediction, pending, head.baseFee, minTip, avgTxCnt) {
+			if snap.Number+fee.ExpectedBlocks > chain.head {
+				continue // not enough blocks replayed yet
+			}
+			stats := tiers[i]
+			stats.evaluated++
+			stats.tips.Add(stats.tips, fee.MaxPriorityFeePerGas)
+			stats.blocks += fee.ExpectedBlocks
+			for n := snap.Number + 1; n <= snap.Number+fee.ExpectedBlocks; n++ {
+				if block := chain.block(n); block != nil && block.includes(fee) {
+					stats.included++
+					break
+				}
+			}
+		}
+		// Jam index
+		delay, ok := chain.inclusionDelay(snap)
+		if !ok {
+			continue
+		}
+		maxGas := (head.gasLimit / 10) * 6
+		durs := make([]time.Duration, 0, len(snap.Txs))
+		for i, tx := range snap.Txs {
+			if tx.GasPrice().Cmp(big.NewInt(params.GWei)) < 0 || tx.Gas() > maxGas {
+				continue
+			}
+			durs = append(durs, snap.age(i))
+		}
+		sample := core.EvalJamSample(cfg.Jam, int(snap.UnderPriced), durs, time.Unix(0, int64(snap.Time)*int64(time.Millisecond)))
+		jam.indexes = append(jam.indexes, float64(sample.Index))
+		jam.delays = append(jam.delays, delay)
+	}
+	return tiers, jam
+}
+
+// block returns what the inclusion of a fee depends on in the canonical block of
+// the given number, nil if the block isn't available.
+func (c *chainData) block(number uint64) *blockInfo {
+	if info, ok := c.blocks[number]; ok {
+		return info
+	}
+	hash := rawdb.ReadCanonicalHash(c.db, number)
+	block := rawdb.ReadBlock(c.db, hash, number)
+	if block == nil {
+		return nil
+	}
+	info := &blockInfo{
+		baseFee:  block.BaseFee(),
+		gasLimit: block.GasLimit(),
+		gasUsed:  block.GasUsed(),
+		txs:      len(block.Transactions()),
+	}
+	for _, tx := range block.Transactions() {
+		if tx.GasFeeCap().Sign() == 0 {
+			continue // system txs
+		}
+		tip, err := tx.EffectiveGasTip(info.baseFee)
+		if err != nil {
+			continue
+		}
+		if info.minTip == nil || tip.Cmp(info.minTip) < 0 {
+			info.minTip = tip
+		}
+	}
+	c.blocks[number] = info
+	return info
+}
+
+// avgTxCnt returns the average tx count of the given number of blocks up to head.
+func (c *chainData) avgTxCnt(head uint64, blocks int) int {
+	var sum, n int
+	for i := 0; i < blocks && uint64(i) < head; i++ {
+		if block := c.block(head - uint64(i)); block != nil {
+			sum += block.txs
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / n
+}
+
+// inclusionDelay returns the mean number of blocks the pending txs of a snapshot
+// waited for to be included, false if none of them was included.
+func (c *chainData) inclusionDelay(snap *poolSnapshot) (float64, bool) {
+	var sum, n uint64
+	for _, tx := range snap.Txs {
+		number := rawdb.ReadTxLookupEntry(c.db, tx.Hash())
+		if number == nil || *number <= snap.Number {
+			continue
+		}
+		sum += *number - snap.Number
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return float64(sum) / float64(n), true
+}
+
+// includes reports whether a tx paying the suggested fee would have been included
+// in the block, by paying at least the lowest tip it included. Blocks without any
+// tip paying tx include it if they have spare gas left.
+func (b *blockInfo) includes(fee *gasprice.FeeSuggestion) bool {
+	tip := fee.MaxPriorityFeePerGas
+	if b.baseFee != nil {
+		if fee.MaxFeePerGas.Cmp(b.baseFee) < 0 {
+			return false
+		}
+		if room := new(big.Int).Sub(fee.MaxFeePerGas, b.baseFee); room.Cmp(tip) < 0 {
+			tip = room
+		}
+	}
+	if b.minTip != nil {
+		return tip.Cmp(b.minTip) >= 0
+	}
+	return b.gasLimit-b.gasUsed >= params.TxGas
+}
+
+func mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// correlation returns the Pearson correlation coefficient of two series, zero if
+// either is constant.
+func correlation(xs, ys []float64) float64 {
+	mx, my := mean(xs), mean(ys)
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
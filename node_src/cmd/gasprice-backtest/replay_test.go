@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func gweis(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.GWei))
+}
+
+func TestBlockIncludes(t *testing.T) {
+	full := &blockInfo{baseFee: gweis(100), gasLimit: 100000, gasUsed: 100000, minTip: gweis(5)}
+	spare := &blockInfo{baseFee: gweis(100), gasLimit: 100000, gasUsed: 50000}
+	cheap := &blockInfo{baseFee: gweis(100), gasLimit: 100000, gasUsed: 50000, minTip: gweis(2)}
+
+	fee := func(maxFee, tip int64) *gasprice.FeeSuggestion {
+		return &gasprice.FeeSuggestion{MaxFeePerGas: gweis(maxFee), MaxPriorityFeePerGas: gweis(tip)}
+	}
+	require.True(t, full.includes(fee(200, 5)))
+	require.False(t, full.includes(fee(200, 4)))
+	// the fee cap leaves room for a 3 gwei tip only
+	require.False(t, full.includes(fee(103, 5)))
+	require.True(t, spare.includes(fee(101, 1)))
+	// can't pay the base fee
+	require.False(t, spare.includes(fee(99, 1)))
+	// the spare gas doesn't let a tx paying less than the included ones in
+	require.True(t, cheap.includes(fee(200, 2)))
+	require.False(t, cheap.includes(fee(200, 1)))
+}
+
+func TestBacktest(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	chain := &chainData{db: db, head: 14, blocks: map[uint64]*blockInfo{
+		10: {baseFee: gweis(100), gasLimit: 30000000, gasUsed: 30000000, minTip: gweis(3), txs: 2},
+		11: {baseFee: gweis(100), gasLimit: 30000000, gasUsed: 30000000, minTip: gweis(8), txs: 2},
+		12: {baseFee: gweis(100), gasLimit: 30000000, gasUsed: 30000000, minTip: gweis(8), txs: 2},
+		13: {baseFee: gweis(100), gasLimit: 30000000, gasUsed: 21000, txs: 1},
+		14: {baseFee: gweis(100), gasLimit: 30000000, gasUsed: 30000000, minTip: gweis(8), txs: 2},
+	}}
+	newTx := func(nonce uint64, tip int64) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{Nonce: nonce, Gas: params.TxGas, GasTipCap: gweis(tip), GasFeeCap: gweis(1000)})
+	}
+	// pending txs paying 9 to 4 gwei of tips, one of them waiting for 30s
+	first := &poolSnapshot{Number: 10, Time: 100000, UnderPriced: 2}
+	for i := int64(0); i < 6; i++ {
+		first.Txs = append(first.Txs, newTx(uint64(i), 9-i))
+		first.Seen = append(first.Seen, first.Time)
+	}
+	first.Seen[5] = first.Time - 30000
+	second := &poolSnapshot{Number: 11, Time: 103000, Txs: []*types.Transaction{newTx(10, 9)}, Seen: []uint64{103000}}
+
+	rawdb.WriteTxLookupEntries(db, 12, []common.Hash{first.Txs[0].Hash(), second.Txs[0].Hash()})
+	rawdb.WriteTxLookupEntries(db, 13, []common.Hash{first.Txs[1].Hash()})
+
+	cfg := &backtestConfig{
+		StatBlocks: 1,
+		MinTip:     params.GWei,
+		Prediction: gasprice.PredConfig{
+			MinTxCntPerBlock:    1,
+			FastFactor:          1,
+			MedianFactor:        2,
+			LowFactor:           3,
+			FastPercentile:      50,
+			MeidanPercentile:    90,
+			MaxValidPendingSecs: 300,
+		},
+		Jam: core.TxJamConfig{JamSecs: 15, UnderPricedFactor: 1, PendingFactor: 1, MaxValidPendingSecs: 300},
+	}
+	tiers, jam := backtest(chain, []*poolSnapshot{first, second}, cfg)
+
+	// fast: 7 gwei within blocks 11-12, then 9 gwei in block 12
+	require.Equal(t, 2, tiers[0].evaluated)
+	require.Equal(t, 1, tiers[0].included)
+	// median: 5 gwei within blocks 11-13, then 9 gwei in block 12
+	require.Equal(t, 2, tiers[1].evaluated)
+	require.Equal(t, 2, tiers[1].included)
+	// low: the min tip within blocks 11-14, then in block 12
+	require.Equal(t, 2, tiers[2].evaluated)
+	require.Equal(t, 1, tiers[2].included)
+
+	// the first snapshot is jammed and its txs waited for 2.5 blocks on average
+	require.Equal(t, []float64{2 + 33, 0}, jam.indexes)
+	require.Equal(t, []float64{2.5, 1}, jam.delays)
+	require.True(t, math.Abs(correlation(jam.indexes, jam.delays)-1) < 1e-9)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// poolSnapshot is the pending pool of a node at a point of time.
+type poolSnapshot struct {
+	Number      uint64               // Head of the chain when the pool was recorded
+	Time        uint64               // Unix time of the record in milliseconds
+	UnderPriced uint64               // Underpriced txs rejected during the last jam period
+	Txs         []*types.Transaction // Pending txs
+	Seen        []uint64             // Unix time in milliseconds each pending tx was first seen at
+}
+
+// age returns how long a pending tx of the snapshot had been waiting for.
+func (s *poolSnapshot) age(i int) time.Duration {
+	if s.Seen[i] > s.Time {
+		return 0
+	}
+	return time.Duration(s.Time-s.Seen[i]) * time.Millisecond
+}
+
+// appendSnapshot appends a snapshot to the RLP stream of the file at path.
+func appendSnapshot(path string, snap *poolSnapshot) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return rlp.Encode(f, snap)
+}
+
+// loadSnapshots reads all the snapshots recorded to the file at path.
+func loadSnapshots(path string) ([]*poolSnapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		stream = rlp.NewStream(f, 0)
+		snaps  []*poolSnapshot
+	)
+	for {
+		snap := new(poolSnapshot)
+		if err := stream.Decode(snap); err != nil {
+			if err == io.EOF {
+				return snaps, nil
+			}
+			return nil, err
+		}
+		if len(snap.Seen) != len(snap.Txs) {
+			return nil, errors.New("invalid pool snapshot")
+		}
+		snaps = append(snaps, snap)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndLoadSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots.rlp")
+
+	tx := types.NewTransaction(1, common.HexToAddress("0x01"), big.NewInt(1), 21000, big.NewInt(1e9), nil)
+	snaps := []*poolSnapshot{
+		{Number: 10, Time: 1000, UnderPriced: 3, Txs: []*types.Transaction{tx}, Seen: []uint64{400}},
+		{Number: 11, Time: 4000},
+	}
+	for _, snap := range snaps {
+		require.Nil(t, appendSnapshot(path, snap))
+	}
+	actual, err := loadSnapshots(path)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(actual))
+
+	require.Equal(t, uint64(3), actual[0].UnderPriced)
+	require.Equal(t, 1, len(actual[0].Txs))
+	require.Equal(t, tx.Hash(), actual[0].Txs[0].Hash())
+	require.Equal(t, uint64(600), uint64(actual[0].age(0).Milliseconds()))
+	require.Equal(t, uint64(11), actual[1].Number)
+	require.Equal(t, 0, len(actual[1].Txs))
+}
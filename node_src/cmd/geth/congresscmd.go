@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	congressUpgradeVersionFlag = cli.IntFlag{
+		Name:  "version",
+		Usage: "Version of the system contracts to upgrade to",
+	}
+	congressUpgradeAtFlag = cli.Uint64Flag{
+		Name:  "at",
+		Usage: "Number of the block to apply the upgrade at",
+	}
+	congressUpgradeBlocksFlag = cli.Uint64Flag{
+		Name:  "blocks",
+		Usage: "Number of blocks to replay from the upgraded block on",
+		Value: 1,
+	}
+	congressCommand = cli.Command{
+		Name:        "congress",
+		Usage:       "A set of commands for the Congress consensus engine",
+		Category:    "MISCELLANEOUS COMMANDS",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:      "snapshot-inspect",
+				Usage:     "Inspect the epoch snapshot index",
+				ArgsUsage: "<epoch (optional)>",
+				Action:    utils.MigrateFlags(congressSnapshotInspect),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.SyncModeFlag,
+					utils.MainnetFlag,
+					utils.TestnetFlag,
+				},
+				Description: `
+geth congress snapshot-inspect [<epoch>]
+summarizes the Congress snapshots indexed by epoch, or prints the snapshot
+of the given epoch along with whether its checkpoint block is canonical.
+`,
+			},
+			{
+				Name:      "snapshot-export",
+				Usage:     "Export the epoch snapshot index into a file",
+				ArgsUsage: "<file> <first epoch (optional)> <last epoch (optional)>",
+				Action:    utils.MigrateFlags(congressSnapshotExport),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.SyncModeFlag,
+					utils.MainnetFlag,
+					utils.TestnetFlag,
+				},
+				Description: `
+geth congress snapshot-export <file> [<first epoch> [<last epoch>]]
+writes the Congress snapshots indexed by epoch into the file, one JSON
+snapshot per line in ascending epoch order.
+`,
+			},
+			{
+				Name:      "snapshot-prune",
+				Usage:     "Prune old snapshots from the epoch snapshot index",
+				ArgsUsage: "<epochs to retain>",
+				Action:    utils.MigrateFlags(congressSnapshotPrune),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.SyncModeFlag,
+					utils.MainnetFlag,
+					utils.TestnetFlag,
+				},
+				Description: `
+geth congress snapshot-prune <epochs to retain>
+deletes every snapshot from the epoch index except the given number of
+most recent epochs. Use --congress.snapshots.retain to keep the index
+pruned while the node is running.
+`,
+			},
+			{
+				Name:      "simulate-upgrade",
+				Usage:     "Dry run a system contract upgrade against the local chain",
+				ArgsUsage: "",
+				Action:    utils.MigrateFlags(congressSimulateUpgrade),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.SyncModeFlag,
+					utils.MainnetFlag,
+					utils.TestnetFlag,
+					congressUpgradeVersionFlag,
+					congressUpgradeAtFlag,
+					congressUpgradeBlocksFlag,
+				},
+				Description: `
+geth congress simulate-upgrade --version <version> --at <block> [--blocks <count>]
+applies the given version of the system contracts at the start of the block on
+a copy of its parent state, replays the following blocks on both the upgraded
+copy and the plain state, and prints how the validators, balances and system
+contracts differ. Nothing is written to the chain, but the parent state must
+still be available.
+`,
+			},
+		},
+	}
+)
+
+// epochSnapshotHeader is the part of a stored snapshot identifying its checkpoint.
+type epochSnapshotHeader struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+func congressSnapshotInspect(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("max 1 argument: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	if ctx.NArg() == 1 {
+		epoch, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid epoch: %v", err)
+		}
+		blob := rawdb.ReadCongressSnapshot(db, epoch)
+		if len(blob) == 0 {
+			return fmt.Errorf("no snapshot stored for epoch %d", epoch)
+		}
+		var header epochSnapshotHeader
+		if err := json.Unmarshal(blob, &header); err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, blob, "", "  "); err != nil {
+			return err
+		}
+		fmt.Println(out.String())
+		fmt.Printf("canonical: %v\n", rawdb.ReadCanonicalHash(db, header.Number) == header.Hash)
+		return nil
+	}
+	var (
+		count, stale int
+		size         common.StorageSize
+		first, last  uint64
+	)
+	it := rawdb.IterateCongressSnapshots(db, 0)
+	defer it.Release()
+
+	for it.Next() {
+		epoch, ok := rawdb.CongressSnapshotEpoch(it.Key())
+		if !ok {
+			continue
+		}
+		var header epochSnapshotHeader
+		if err := json.Unmarshal(it.Value(), &header); err != nil {
+			return fmt.Errorf("corrupt snapshot at epoch %d: %v", epoch, err)
+		}
+		if rawdb.ReadCanonicalHash(db, header.Number) != header.Hash {
+			stale++
+		}
+		if count == 0 {
+			first = epoch
+		}
+		last = epoch
+		count++
+		size += common.StorageSize(len(it.Key()) + len(it.Value()))
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if count == 0 {
+		fmt.Println("No epoch snapshots stored")
+		return nil
+	}
+	fmt.Printf("Epoch snapshots: %d (%v)\n", count, size)
+	fmt.Printf("Epochs:          %d - %d\n", first, last)
+	fmt.Printf("Non-canonical:   %d\n", stale)
+	return nil
+}
+
+func congressSnapshotExport(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 3 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	var (
+		first uint64
+		last  uint64 = math.MaxUint64
+		err   error
+	)
+	if ctx.NArg() >= 2 {
+		if first, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+			return fmt.Errorf("invalid first epoch: %v", err)
+		}
+	}
+	if ctx.NArg() == 3 {
+		if last, err = strconv.ParseUint(ctx.Args().Get(2), 10, 64); err != nil {
+			return fmt.Errorf("invalid last epoch: %v", err)
+		}
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	fh, err := os.OpenFile(ctx.Args().Get(0), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	writer := bufio.NewWriter(fh)
+
+	it := rawdb.IterateCongressSnapshots(db, first)
+	defer it.Release()
+
+	var count int
+	for it.Next() {
+		epoch, ok := rawdb.CongressSnapshotEpoch(it.Key())
+		if !ok {
+			continue
+		}
+		if epoch > last {
+			break
+		}
+		if _, err := writer.Write(append(common.CopyBytes(it.Value()), '\n')); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	log.Info("Exported epoch snapshots", "count", count, "file", ctx.Args().Get(0))
+	return nil
+}
+
+func congressSnapshotPrune(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	retain, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+	if err != nil || retain == 0 {
+		return fmt.Errorf("invalid number of epochs to retain: %s", ctx.Args().Get(0))
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	// Find the latest stored epoch to count the retained ones from
+	var (
+		last  uint64
+		found bool
+	)
+	it := rawdb.IterateCongressSnapshots(db, 0)
+	for it.Next() {
+		if epoch, ok := rawdb.CongressSnapshotEpoch(it.Key()); ok {
+			last, found = epoch, true
+		}
+	}
+	it.Release()
+
+	if !found || last+1 <= retain {
+		log.Info("No epoch snapshots to prune")
+		return nil
+	}
+	deleted, err := congress.PruneEpochSnapshots(db, last+1-retain)
+	if err != nil {
+		return err
+	}
+	log.Info("Pruned epoch snapshots", "deleted", deleted, "first", last+1-retain, "last", last)
+	return nil
+}
+
+func congressSimulateUpgrade(ctx *cli.Context) error {
+	if !ctx.IsSet(congressUpgradeVersionFlag.Name) || !ctx.IsSet(congressUpgradeAtFlag.Name) {
+		return fmt.Errorf("--%s and --%s are required", congressUpgradeVersionFlag.Name, congressUpgradeAtFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack)
+	defer db.Close()
+	defer chain.Stop()
+
+	engine, ok := chain.Engine().(*congress.Congress)
+	if !ok {
+		return errors.New("not a congress chain")
+	}
+	engine.SetStateFn(chain.StateAt)
+	engine.SetChain(chain)
+
+	version := systemcontract.SysContractVersion(ctx.Int(congressUpgradeVersionFlag.Name))
+	sim, err := engine.SimulateUpgrade(chain, version, ctx.Uint64(congressUpgradeAtFlag.Name), ctx.Uint64(congressUpgradeBlocksFlag.Name))
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(sim, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -84,6 +84,9 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolRemotesFlag,
+		utils.TxPoolRemotesIntervalFlag,
+		utils.TxPoolRemotesSizeFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -91,6 +94,12 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolAllowlistFlag,
+		utils.TxPoolSenderRateFlag,
+		utils.TxPoolSenderBurstFlag,
+		utils.TxPoolContractRateFlag,
+		utils.TxPoolContractBurstFlag,
+		utils.TxPoolContractSlotsFlag,
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
@@ -150,6 +159,11 @@ var (
 		utils.GpoPercentileFlag,
 		utils.GpoMaxGasPriceFlag,
 		utils.GpoIgnoreGasPriceFlag,
+		utils.CongressMissedBlocksThresholdsFlag,
+		utils.CongressMissedBlocksWebhookFlag,
+		utils.CongressFinalityFlag,
+		utils.CongressSnapshotsRetainFlag,
+		utils.CongressDenialsRetainFlag,
 		utils.MinerNotifyFullFlag,
 		configFileFlag,
 		utils.CatalystFlag,
@@ -235,6 +249,8 @@ func init() {
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		// See congresscmd.go
+		congressCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
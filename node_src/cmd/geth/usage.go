@@ -95,6 +95,9 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolNoLocalsFlag,
 			utils.TxPoolJournalFlag,
 			utils.TxPoolRejournalFlag,
+			utils.TxPoolRemotesFlag,
+			utils.TxPoolRemotesIntervalFlag,
+			utils.TxPoolRemotesSizeFlag,
 			utils.TxPoolPriceLimitFlag,
 			utils.TxPoolPriceBumpFlag,
 			utils.TxPoolAccountSlotsFlag,
@@ -102,6 +105,12 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolAllowlistFlag,
+			utils.TxPoolSenderRateFlag,
+			utils.TxPoolSenderBurstFlag,
+			utils.TxPoolContractRateFlag,
+			utils.TxPoolContractBurstFlag,
+			utils.TxPoolContractSlotsFlag,
 		},
 	},
 	{
@@ -197,6 +206,16 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.GpoIgnoreGasPriceFlag,
 		},
 	},
+	{
+		Name: "CONGRESS",
+		Flags: []cli.Flag{
+			utils.CongressMissedBlocksThresholdsFlag,
+			utils.CongressMissedBlocksWebhookFlag,
+			utils.CongressFinalityFlag,
+			utils.CongressSnapshotsRetainFlag,
+			utils.CongressDenialsRetainFlag,
+		},
+	},
 	{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
@@ -39,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/congress"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -340,6 +341,20 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolRemotesFlag = cli.StringFlag{
+		Name:  "txpool.remotes",
+		Usage: "Disk snapshot of the remote transactions to survive node restarts (disabled if empty)",
+	}
+	TxPoolRemotesIntervalFlag = cli.DurationFlag{
+		Name:  "txpool.remotes.interval",
+		Usage: "Time interval to regenerate the remote transaction snapshot",
+		Value: core.DefaultTxPoolConfig.RemotesInterval,
+	}
+	TxPoolRemotesSizeFlag = cli.Uint64Flag{
+		Name:  "txpool.remotes.size",
+		Usage: "Maximum size in bytes of the remote transaction snapshot",
+		Value: core.DefaultTxPoolConfig.RemotesSize,
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -375,6 +390,32 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.TxPool.Lifetime,
 	}
+	TxPoolAllowlistFlag = cli.StringFlag{
+		Name:  "txpool.allowlist",
+		Usage: "File listing the addresses exempt from the fairness policies, one per line",
+	}
+	TxPoolSenderRateFlag = cli.Float64Flag{
+		Name:  "txpool.senderrate",
+		Usage: "Remote transactions per second allowed per sender (0 = unlimited)",
+	}
+	TxPoolSenderBurstFlag = cli.Uint64Flag{
+		Name:  "txpool.senderburst",
+		Usage: "Maximum number of remote transactions a sender can submit at once",
+		Value: ethconfig.Defaults.TxPool.Fairness.SenderBurst,
+	}
+	TxPoolContractRateFlag = cli.Float64Flag{
+		Name:  "txpool.contractrate",
+		Usage: "Remote transactions per second allowed per called contract (0 = unlimited)",
+	}
+	TxPoolContractBurstFlag = cli.Uint64Flag{
+		Name:  "txpool.contractburst",
+		Usage: "Maximum number of remote transactions a contract can be called with at once",
+		Value: ethconfig.Defaults.TxPool.Fairness.ContractBurst,
+	}
+	TxPoolContractSlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.contractslots",
+		Usage: "Executable remote transaction slots per contract kept when the pool is full (0 = unlimited)",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -696,6 +737,28 @@ var (
 		Value: ethconfig.Defaults.GPO.IgnorePrice.Int64(),
 	}
 
+	// Congress settings
+	CongressMissedBlocksThresholdsFlag = cli.StringFlag{
+		Name:  "congress.missedblocks.thresholds",
+		Usage: "Comma separated percentages of the jail threshold at which to alert about missed blocks of the local validator (default: 50,80,90)",
+	}
+	CongressMissedBlocksWebhookFlag = cli.StringFlag{
+		Name:  "congress.missedblocks.webhook",
+		Usage: "URL to POST missed blocks alerts of the local validator to",
+	}
+	CongressSnapshotsRetainFlag = cli.Uint64Flag{
+		Name:  "congress.snapshots.retain",
+		Usage: "Number of recent epoch snapshots to keep on disk (0 = keep all)",
+	}
+	CongressDenialsRetainFlag = cli.Uint64Flag{
+		Name:  "congress.denials.retain",
+		Usage: "Number of recent blocks to keep the blacklist denial records of (0 = keep all)",
+	}
+	CongressFinalityFlag = cli.BoolFlag{
+		Name:  "congress.finality",
+		Usage: "Exchange validator votes on recent blocks over the vote protocol and track the finalized block",
+	}
+
 	// Metrics flags
 	MetricsEnabledFlag = cli.BoolFlag{
 		Name:  "metrics",
@@ -1294,6 +1357,31 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config, light bool) {
 	}
 }
 
+func setCongress(ctx *cli.Context, cfg *congress.Config) {
+	if ctx.GlobalIsSet(CongressMissedBlocksThresholdsFlag.Name) {
+		cfg.MissedBlocks.Thresholds = nil
+		for _, s := range strings.Split(ctx.GlobalString(CongressMissedBlocksThresholdsFlag.Name), ",") {
+			threshold, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+			if err != nil || threshold == 0 || threshold > 100 {
+				Fatalf("Invalid threshold in --%s: %s", CongressMissedBlocksThresholdsFlag.Name, s)
+			}
+			cfg.MissedBlocks.Thresholds = append(cfg.MissedBlocks.Thresholds, threshold)
+		}
+	}
+	if ctx.GlobalIsSet(CongressMissedBlocksWebhookFlag.Name) {
+		cfg.MissedBlocks.Webhook = ctx.GlobalString(CongressMissedBlocksWebhookFlag.Name)
+	}
+	if ctx.GlobalIsSet(CongressFinalityFlag.Name) {
+		cfg.Finality.Enabled = ctx.GlobalBool(CongressFinalityFlag.Name)
+	}
+	if ctx.GlobalIsSet(CongressSnapshotsRetainFlag.Name) {
+		cfg.Snapshots.Retain = ctx.GlobalUint64(CongressSnapshotsRetainFlag.Name)
+	}
+	if ctx.GlobalIsSet(CongressDenialsRetainFlag.Name) {
+		cfg.Denials.Retain = ctx.GlobalUint64(CongressDenialsRetainFlag.Name)
+	}
+}
+
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLocalsFlag.Name) {
 		locals := strings.Split(ctx.GlobalString(TxPoolLocalsFlag.Name), ",")
@@ -1314,6 +1402,15 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolRemotesFlag.Name) {
+		cfg.Remotes = ctx.GlobalString(TxPoolRemotesFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolRemotesIntervalFlag.Name) {
+		cfg.RemotesInterval = ctx.GlobalDuration(TxPoolRemotesIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolRemotesSizeFlag.Name) {
+		cfg.RemotesSize = ctx.GlobalUint64(TxPoolRemotesSizeFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1335,6 +1432,24 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolAllowlistFlag.Name) {
+		cfg.Fairness.Allowlist = ctx.GlobalString(TxPoolAllowlistFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolSenderRateFlag.Name) {
+		cfg.Fairness.SenderRate = ctx.GlobalFloat64(TxPoolSenderRateFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolSenderBurstFlag.Name) {
+		cfg.Fairness.SenderBurst = ctx.GlobalUint64(TxPoolSenderBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolContractRateFlag.Name) {
+		cfg.Fairness.ContractRate = ctx.GlobalFloat64(TxPoolContractRateFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolContractBurstFlag.Name) {
+		cfg.Fairness.ContractBurst = ctx.GlobalUint64(TxPoolContractBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolContractSlotsFlag.Name) {
+		cfg.Fairness.ContractSlots = ctx.GlobalUint64(TxPoolContractSlotsFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1473,6 +1588,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, ks, cfg)
 	setGPO(ctx, &cfg.GPO, ctx.GlobalString(SyncModeFlag.Name) == "light")
 	setTxPool(ctx, &cfg.TxPool)
+	setCongress(ctx, &cfg.Congress)
 	setEthash(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
 	setWhitelist(ctx, cfg)
@@ -1832,6 +1948,8 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 	var engine consensus.Engine
 	if config.Clique != nil {
 		engine = clique.New(config.Clique, chainDb)
+	} else if config.Congress != nil {
+		engine = congress.New(config, chainDb)
 	} else {
 		engine = ethash.NewFaker()
 		if !ctx.GlobalBool(FakePoWFlag.Name) {
@@ -0,0 +1,230 @@
+package congress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// errUnknownActivity is returned if no sealing activity is stored for an epoch.
+var errUnknownActivity = errors.New("unknown epoch activity")
+
+// ValidatorActivity is the sealing record of a validator over a range of blocks.
+type ValidatorActivity struct {
+	InTurn    uint64 `json:"inTurn"`       // Number of blocks sealed in-turn
+	OutOfTurn uint64 `json:"outOfTurn"`    // Number of blocks sealed out-of-turn
+	Missed    uint64 `json:"missedInTurn"` // Number of in-turn slots sealed by others
+	Punished  uint64 `json:"punished"`     // Number of times the Punish contract was called for the validator
+}
+
+func (a *ValidatorActivity) add(other *ValidatorActivity) {
+	a.InTurn += other.InTurn
+	a.OutOfTurn += other.OutOfTurn
+	a.Missed += other.Missed
+	a.Punished += other.Punished
+}
+
+// EpochActivity is the sealing record of all validators within an epoch, or a part of it.
+type EpochActivity struct {
+	Epoch      uint64                                `json:"epoch"`
+	From       uint64                                `json:"from"`
+	To         uint64                                `json:"to"`
+	Hash       common.Hash                           `json:"hash"` // Hash of the block `To`, used to detect reorgs
+	Validators map[common.Address]*ValidatorActivity `json:"validators"`
+}
+
+func newEpochActivity(epoch, from, to uint64) *EpochActivity {
+	return &EpochActivity{
+		Epoch:      epoch,
+		From:       from,
+		To:         to,
+		Validators: make(map[common.Address]*ValidatorActivity),
+	}
+}
+
+func (e *EpochActivity) validator(addr common.Address) *ValidatorActivity {
+	act, ok := e.Validators[addr]
+	if !ok {
+		act = new(ValidatorActivity)
+		e.Validators[addr] = act
+	}
+	return act
+}
+
+// record adds the sealing of a block to the activity, the block activity being nil
+// for in-turn blocks.
+func (e *EpochActivity) record(sealer common.Address, block *blockActivity) {
+	if block == nil {
+		e.validator(sealer).InTurn++
+		return
+	}
+	e.validator(sealer).OutOfTurn++
+	e.validator(block.Missed).Missed++
+	if block.Punished {
+		e.validator(block.Missed).Punished++
+	}
+}
+
+// revert removes the sealing of a block recorded earlier from the activity.
+func (e *EpochActivity) revert(sealer common.Address, block *blockActivity) {
+	if block == nil {
+		e.validator(sealer).InTurn--
+	} else {
+		e.validator(sealer).OutOfTurn--
+		e.validator(block.Missed).Missed--
+		if block.Punished {
+			e.validator(block.Missed).Punished--
+		}
+	}
+	for addr, act := range e.Validators {
+		if *act == (ValidatorActivity{}) {
+			delete(e.Validators, addr)
+		}
+	}
+}
+
+func loadEpochActivity(db ethdb.KeyValueReader, epoch uint64) (*EpochActivity, error) {
+	blob := rawdb.ReadCongressActivity(db, epoch)
+	if len(blob) == 0 {
+		return nil, errUnknownActivity
+	}
+	act := new(EpochActivity)
+	if err := json.Unmarshal(blob, act); err != nil {
+		return nil, err
+	}
+	return act, nil
+}
+
+func (e *EpochActivity) store(db ethdb.KeyValueWriter) error {
+	blob, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteCongressActivity(db, e.Epoch, blob)
+	return nil
+}
+
+// blockActivity is the in-turn validator an out-of-turn block was sealed in place
+// of, recorded while processing the block.
+type blockActivity struct {
+	Missed   common.Address
+	Punished bool // Whether the Punish contract emitted LogPunishValidator for it
+}
+
+// writeBlockActivity persists the activity of a canonical out-of-turn block, so that
+// it can be reverted from the index if the block gets reorged away.
+func writeBlockActivity(db ethdb.KeyValueWriter, header *types.Header, activity *blockActivity) error {
+	blob, err := rlp.EncodeToBytes(activity)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteCongressBlockActivity(db, header.Number.Uint64(), SealHash(header), blob)
+	return nil
+}
+
+// readBlockActivity retrieves the activity of an out-of-turn block. The blocks which
+// weren't processed by this node, e.g. fast synced ones, have no record, so their
+// missed validator is derived from the snapshot of the parent instead, assuming the
+// Punish contract was called whenever the validator wasn't a recent signer.
+func (c *Congress) readBlockActivity(chain consensus.ChainHeaderReader, header *types.Header) (*blockActivity, error) {
+	number := header.Number.Uint64()
+	if blob := rawdb.ReadCongressBlockActivity(c.db, number, SealHash(header)); len(blob) > 0 {
+		activity := new(blockActivity)
+		if err := rlp.DecodeBytes(blob, activity); err != nil {
+			return nil, err
+		}
+		return activity, nil
+	}
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	missed, punished := missedValidator(snap, number)
+	return &blockActivity{Missed: missed, Punished: punished}, nil
+}
+
+// punishEvents counts the LogPunishValidator events of the validator emitted by the
+// Punish contract so far in the block under processing.
+func (c *Congress) punishEvents(header *types.Header, state *state.StateDB, val common.Address) int {
+	var (
+		topic  = c.abi[systemcontract.PunishContractName].Events["LogPunishValidator"].ID
+		punish = *systemcontract.GetPunishAddr(header.Number, c.chainConfig)
+		count  int
+	)
+	for _, l := range state.Logs() {
+		if l.Address == punish && len(l.Topics) > 1 && l.Topics[0] == topic && l.Topics[1] == val.Hash() {
+			count++
+		}
+	}
+	return count
+}
+
+// missedValidator returns the in-turn validator of the given height according to the
+// snapshot of its parent, and whether it will be punished if it doesn't seal the block.
+func missedValidator(snap *Snapshot, number uint64) (common.Address, bool) {
+	proposers := snap.proposers()
+	inturnValidator := proposers[number%uint64(len(proposers))]
+	// check sigend recently or not
+	for _, recent := range snap.Recents {
+		if recent == inturnValidator {
+			return inturnValidator, false
+		}
+	}
+	return inturnValidator, true
+}
+
+// epochRange returns the first and the last block number of the given epoch.
+func (c *Congress) epochRange(epoch uint64) (uint64, uint64) {
+	from, to := epoch*c.config.Epoch, (epoch+1)*c.config.Epoch-1
+	if from == 0 {
+		from = 1 // the genesis is not sealed by anyone
+	}
+	return from, to
+}
+
+// epochActivity retrieves the sealing activity of the given epoch from the index, up
+// to the latest indexed block for the current epoch.
+func (c *Congress) epochActivity(chain consensus.ChainHeaderReader, epoch uint64) (*EpochActivity, error) {
+	act, err := loadEpochActivity(c.db, epoch)
+	if err != nil {
+		return nil, err
+	}
+	// The epoch may be being reindexed after a reorg
+	if act.To < act.From {
+		return nil, errUnknownActivity
+	}
+	if h := chain.GetHeaderByNumber(act.To); h == nil || h.Hash() != act.Hash {
+		return nil, errUnknownActivity
+	}
+	return act, nil
+}
+
+// rangeActivity retrieves the sealing activity of the epochs overlapping [from, to].
+// The epochs are reported whole, as the index doesn't break them down further.
+func (c *Congress) rangeActivity(chain consensus.ChainHeaderReader, from, to uint64) ([]*EpochActivity, error) {
+	head := chain.CurrentHeader()
+	if from == 0 {
+		from = 1
+	}
+	if from > to || to > head.Number.Uint64() {
+		return nil, fmt.Errorf("invalid block range [%d, %d], head %d", from, to, head.Number.Uint64())
+	}
+	var epochs []*EpochActivity
+	for epoch := from / c.config.Epoch; epoch <= to/c.config.Epoch; epoch++ {
+		act, err := c.epochActivity(chain, epoch)
+		if err != nil {
+			return nil, fmt.Errorf("epoch %d: %w", epoch, err)
+		}
+		epochs = append(epochs, act)
+	}
+	return epochs, nil
+}
@@ -0,0 +1,215 @@
+package congress
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// headReader is a chain of headers kept in memory with a current head.
+type headReader struct {
+	*testHeaderReader
+	head *types.Header
+}
+
+func (r *headReader) CurrentHeader() *types.Header { return r.head }
+
+// activityTestChain makes a chain of n headers after the genesis sealed by the same
+// validator, the ones in outOfTurn being sealed out-of-turn.
+func activityTestChain(config *params.ChainConfig, n uint64, outOfTurn map[uint64]bool) (*headReader, common.Address) {
+	sealer := common.HexToAddress("0xc0ffee")
+	chain := &headReader{testHeaderReader: &testHeaderReader{config: config, headers: make(map[common.Hash]*types.Header)}}
+
+	genesis := &types.Header{Number: new(big.Int), Difficulty: big.NewInt(1)}
+	chain.headers[genesis.Hash()] = genesis
+	parent := genesis.Hash()
+	for i := uint64(1); i <= n; i++ {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(i),
+			Coinbase:   sealer,
+			Difficulty: new(big.Int).Set(diffInTurn),
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		if outOfTurn[i] {
+			header.Difficulty = new(big.Int).Set(diffNoTurn)
+		}
+		chain.headers[header.Hash()] = header
+		chain.head, parent = header, header.Hash()
+	}
+	return chain, sealer
+}
+
+// Tests that the epoch activity is indexed from the canonical blocks, reverted along
+// the blocks reorged away, and that the records of the out-of-turn blocks are pruned
+// one epoch after theirs.
+func TestActivityIndex(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.Congress = &params.CongressConfig{Period: 3, Epoch: 4}
+
+	var (
+		engine = New(&config, rawdb.NewMemoryDatabase())
+		a      = common.HexToAddress("0xa")
+		b      = common.HexToAddress("0xb")
+	)
+	blocks, sealer := activityTestChain(&config, 9, map[uint64]bool{2: true, 3: true, 6: true})
+	chain := &canonicalReader{headReader: blocks, canonical: make(map[uint64]common.Hash)}
+	for _, header := range blocks.headers {
+		chain.canonical[header.Number.Uint64()] = header.Hash()
+	}
+	engine.SetChain(chain)
+
+	records := map[uint64]*blockActivity{
+		2: {Missed: a, Punished: true},
+		3: {Missed: b},
+		6: {Missed: a, Punished: true},
+	}
+	for number, record := range records {
+		engine.addBlockData(chain.GetHeaderByNumber(number), &blockData{activity: record})
+	}
+	// Start indexing at the first block, catching up with the head at once
+	indexer := &blockIndexer{congress: engine}
+	indexer.update(chain.GetHeaderByNumber(1))
+	indexer.update(chain.head)
+
+	act, err := engine.epochActivity(chain, 0)
+	if err != nil {
+		t.Fatalf("failed to retrieve epoch activity: %v", err)
+	}
+	if act.From != 1 || act.To != 3 || act.Hash != chain.GetHeaderByNumber(3).Hash() {
+		t.Fatalf("epoch range mismatch: from %d, to %d, hash %x", act.From, act.To, act.Hash)
+	}
+	want := map[common.Address]ValidatorActivity{
+		sealer: {InTurn: 1, OutOfTurn: 2},
+		a:      {Missed: 1, Punished: 1},
+		b:      {Missed: 1},
+	}
+	for addr, have := range act.Validators {
+		if *have != want[addr] {
+			t.Errorf("validator %x activity mismatch: have %+v, want %+v", addr, *have, want[addr])
+		}
+	}
+	if len(act.Validators) != len(want) {
+		t.Fatalf("validators mismatch: have %d, want %d", len(act.Validators), len(want))
+	}
+	// The current epoch is indexed up to the head
+	act, err = engine.epochActivity(chain, 2)
+	if err != nil {
+		t.Fatalf("failed to retrieve current epoch activity: %v", err)
+	}
+	if act.From != 8 || act.To != 9 || act.Validators[sealer].InTurn != 2 {
+		t.Fatalf("current epoch activity mismatch: from %d, to %d, %+v", act.From, act.To, act.Validators[sealer])
+	}
+	// The records of the epochs before the previous one are pruned
+	for number := range records {
+		blob := rawdb.ReadCongressBlockActivity(engine.db, number, SealHash(chain.GetHeaderByNumber(number)))
+		if pruned := len(blob) == 0; pruned != (number < 4) {
+			t.Errorf("block %d record pruned mismatch: have %v, want %v", number, pruned, number < 4)
+		}
+	}
+	// Reorg the blocks 7 to 9 away in favour of an out-of-turn block 7
+	parent := chain.GetHeaderByNumber(6)
+	side := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     big.NewInt(7),
+		Coinbase:   b,
+		Time:       1,
+		Difficulty: new(big.Int).Set(diffNoTurn),
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+	chain.headers[side.Hash()], chain.head = side, side
+	chain.canonical[7] = side.Hash()
+	delete(chain.canonical, 8)
+	delete(chain.canonical, 9)
+	engine.addBlockData(side, &blockData{activity: &blockActivity{Missed: sealer}})
+	indexer.update(side)
+
+	if _, err := engine.epochActivity(chain, 2); err != errUnknownActivity {
+		t.Fatalf("reorged epoch activity error mismatch: have %v, want %v", err, errUnknownActivity)
+	}
+	act, err = engine.epochActivity(chain, 1)
+	if err != nil {
+		t.Fatalf("failed to retrieve reorged epoch activity: %v", err)
+	}
+	want = map[common.Address]ValidatorActivity{
+		sealer: {InTurn: 2, OutOfTurn: 1, Missed: 1},
+		a:      {Missed: 1, Punished: 1},
+		b:      {OutOfTurn: 1},
+	}
+	if act.To != 7 || act.Hash != side.Hash() || len(act.Validators) != len(want) {
+		t.Fatalf("reorged epoch activity mismatch: to %d, hash %x, %d validators", act.To, act.Hash, len(act.Validators))
+	}
+	for addr, have := range act.Validators {
+		if *have != want[addr] {
+			t.Errorf("validator %x reorged activity mismatch: have %+v, want %+v", addr, *have, want[addr])
+		}
+	}
+	// A restarted indexer resumes from the stored activity
+	resumed := &blockIndexer{congress: engine}
+	resumed.update(side)
+	if resumed.activity.Epoch != 1 || resumed.activity.To != 7 || *resumed.activity.Validators[b] != want[b] {
+		t.Fatalf("resumed activity mismatch: epoch %d, to %d", resumed.activity.Epoch, resumed.activity.To)
+	}
+}
+
+// Tests that only the Punish contract events of the given validator are counted.
+func TestPunishEvents(t *testing.T) {
+	var (
+		engine = New(params.AllCongressProtocolChanges, rawdb.NewMemoryDatabase())
+		header = &types.Header{Number: big.NewInt(10)}
+		val    = common.HexToAddress("0xa")
+		topic  = engine.abi[systemcontract.PunishContractName].Events["LogPunishValidator"].ID
+		addr   = *systemcontract.GetPunishAddr(header.Number, engine.chainConfig)
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddLog(&types.Log{Address: addr, Topics: []common.Hash{topic, val.Hash()}})
+	statedb.AddLog(&types.Log{Address: addr, Topics: []common.Hash{topic, common.HexToAddress("0xb").Hash()}})
+	statedb.AddLog(&types.Log{Address: common.HexToAddress("0xc"), Topics: []common.Hash{topic, val.Hash()}})
+	if count := engine.punishEvents(header, statedb, val); count != 1 {
+		t.Fatalf("punish events mismatch: have %d, want 1", count)
+	}
+}
+
+// Tests that the block number tags are resolved against the head, or rejected.
+func TestAPIBlockNumber(t *testing.T) {
+	chain, _ := activityTestChain(params.AllCongressProtocolChanges, 3, nil)
+	api := &API{chain: chain, congress: New(params.AllCongressProtocolChanges, rawdb.NewMemoryDatabase())}
+
+	tests := []struct {
+		number rpc.BlockNumber
+		want   uint64
+		fail   bool
+	}{
+		{rpc.LatestBlockNumber, 3, false},
+		{rpc.PendingBlockNumber, 3, false},
+		{rpc.BlockNumber(2), 2, false},
+		{rpc.FinalizedBlockNumber, 0, true},
+		{rpc.BlockNumber(-4), 0, true},
+	}
+	for i, tt := range tests {
+		have, err := api.blockNumber(tt.number)
+		if (err != nil) != tt.fail || have != tt.want {
+			t.Errorf("test %d: have %d (err %v), want %d (fail %v)", i, have, err, tt.want, tt.fail)
+		}
+	}
+	if _, err := api.GetValidatorActivity(rpc.BlockNumber(-4), rpc.LatestBlockNumber); err == nil {
+		t.Fatalf("unsupported tag accepted")
+	}
+	// The finalized tag resolves once a block got finalized, as long as it's canonical
+	api.congress.SetChain(chain)
+	api.congress.finality = &finality{number: 2, hash: chain.GetHeaderByNumber(2).Hash()}
+	if have, err := api.blockNumber(rpc.FinalizedBlockNumber); err != nil || have != 2 {
+		t.Fatalf("finalized block mismatch: have %d (err %v), want 2", have, err)
+	}
+	api.congress.finality.hash = common.HexToHash("0x01")
+	if _, err := api.blockNumber(rpc.FinalizedBlockNumber); err != errNoFinalizedBlock {
+		t.Fatalf("non-canonical finalized block error mismatch: have %v, want %v", err, errNoFinalizedBlock)
+	}
+}
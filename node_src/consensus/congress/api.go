@@ -18,10 +18,14 @@
 package congress
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -35,16 +39,10 @@ type API struct {
 
 // GetSnapshot retrieves the state snapshot at a given block.
 func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
-	// Retrieve the requested block number (or current if none requested)
-	var header *types.Header
-	if number == nil || *number == rpc.LatestBlockNumber {
-		header = api.chain.CurrentHeader()
-	} else {
-		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
-	}
-	// Ensure we have an actually valid block and return its snapshot
-	if header == nil {
-		return nil, errUnknownBlock
+	// Retrieve the requested block (or current if none requested)
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
 	}
 	return api.congress.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
 }
@@ -58,18 +56,27 @@ func (api *API) GetSnapshotAtHash(hash common.Hash) (*Snapshot, error) {
 	return api.congress.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
 }
 
+// GetEpochSnapshot retrieves the snapshot taken at the checkpoint block of an epoch
+// from the epoch index, rebuilding it from the headers if it isn't indexed, as for
+// the epochs sealed before the index existed.
+func (api *API) GetEpochSnapshot(epoch uint64) (*Snapshot, error) {
+	snap, err := loadEpochSnapshot(api.congress.config, api.congress.signatures, api.congress.db, epoch)
+	if err != errUnknownEpoch {
+		return snap, err
+	}
+	header := api.chain.GetHeaderByNumber(epoch * api.congress.config.Epoch)
+	if header == nil {
+		return nil, errUnknownEpoch
+	}
+	return api.congress.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
 // GetValidators retrieves the list of authorized validators at the specified block.
 func (api *API) GetValidators(number *rpc.BlockNumber) ([]common.Address, error) {
-	// Retrieve the requested block number (or current if none requested)
-	var header *types.Header
-	if number == nil || *number == rpc.LatestBlockNumber {
-		header = api.chain.CurrentHeader()
-	} else {
-		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
-	}
-	// Ensure we have an actually valid block and return the validators from its snapshot
-	if header == nil {
-		return nil, errUnknownBlock
+	// Retrieve the requested block (or current if none requested)
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
 	}
 	snap, err := api.congress.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
 	if err != nil {
@@ -146,3 +153,408 @@ func (api *API) Status() (*status, error) {
 		NumBlocks:     numBlocks,
 	}, nil
 }
+
+// proposerSlot is an upcoming block height along with its expected in-turn validator.
+type proposerSlot struct {
+	Number    uint64         `json:"number"`
+	Validator common.Address `json:"validator"`
+	Timestamp uint64         `json:"timestamp"` // Expected timestamp if all the blocks before are sealed on time
+	Recent    bool           `json:"recent"`    // Whether the validator may still be blocked by the recents limit, so the slot is likely taken out-of-turn
+	Local     bool           `json:"local"`     // Whether the slot belongs to the local validator
+}
+
+type proposerSchedule struct {
+	Number    uint64          `json:"number"` // Head block the schedule is projected from
+	Hash      common.Hash     `json:"hash"`
+	Epoch     uint64          `json:"epochEnd"`            // Checkpoint block the validator set may change after
+	Local     *common.Address `json:"local,omitempty"`     // Local validator, if any
+	NextLocal *proposerSlot   `json:"nextLocal,omitempty"` // First upcoming slot of the local validator, if scheduled
+	Slots     []*proposerSlot `json:"slots"`
+}
+
+// GetProposerSchedule projects the in-turn validators of the next count blocks, up to
+// the next checkpoint since the validator set may change after it. If count is not
+// given, all blocks up to the checkpoint are projected.
+func (api *API) GetProposerSchedule(count *uint64) (*proposerSchedule, error) {
+	header := api.chain.CurrentHeader()
+	snap, err := api.congress.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		number    = header.Number.Uint64()
+		epoch     = api.congress.config.Epoch
+		end       = (number/epoch + 1) * epoch
+		period    = api.congress.config.Period
+		proposers = snap.proposers()
+	)
+	if count != nil && number+*count < end {
+		end = number + *count
+	}
+	api.congress.lock.RLock()
+	local := api.congress.validator
+	api.congress.lock.RUnlock()
+
+	schedule := &proposerSchedule{
+		Number: number,
+		Hash:   header.Hash(),
+		Epoch:  (number/epoch + 1) * epoch,
+	}
+	if _, ok := snap.Validators[local]; ok {
+		schedule.Local = &local
+	}
+	// The next block can't be sealed earlier than now, the following ones are a period apart
+	timestamp := header.Time + period
+	if now := uint64(time.Now().Unix()); timestamp < now {
+		timestamp = now
+	}
+	for n := number + 1; n <= end; n++ {
+		validator := proposers[n%uint64(len(proposers))]
+		slot := &proposerSlot{
+			Number:    n,
+			Validator: validator,
+			Timestamp: timestamp + (n-number-1)*period,
+			Local:     schedule.Local != nil && validator == local,
+		}
+		for seen, recent := range snap.Recents {
+			if recent == validator && seen+recentsLimit(api.congress.config, n, len(snap.Validators)) > n {
+				slot.Recent = true
+			}
+		}
+		if slot.Local && schedule.NextLocal == nil {
+			schedule.NextLocal = slot
+		}
+		schedule.Slots = append(schedule.Slots, slot)
+	}
+	return schedule, nil
+}
+
+type doubleSignEvidence struct {
+	Validator common.Address `json:"validator"`
+	Number    uint64         `json:"number"`
+	HeaderA   *types.Header  `json:"headerA"`
+	HeaderB   *types.Header  `json:"headerB"`
+	Punished  bool           `json:"punished"` // Whether the Punish contract jailed the validator for it as of the head
+}
+
+// GetDoubleSignEvidences retrieves the double-sign evidences collected by the node,
+// optionally filtered by the equivocating validator.
+func (api *API) GetDoubleSignEvidences(validator *common.Address) ([]*doubleSignEvidence, error) {
+	records, err := readAllEvidences(api.congress.db)
+	if err != nil {
+		return nil, err
+	}
+	head := api.chain.CurrentHeader()
+	var statedb *state.StateDB
+	if api.chain.Config().IsDoubleSign(head.Number) {
+		if statedb, err = api.congress.stateFn(head.Root); err != nil {
+			return nil, err
+		}
+	}
+	evidences := make([]*doubleSignEvidence, 0, len(records))
+	for _, record := range records {
+		if validator != nil && record.Validator != *validator {
+			continue
+		}
+		evidence := &doubleSignEvidence{
+			Validator: record.Validator,
+			Number:    record.Evidence.Number(),
+			HeaderA:   record.Evidence.HeaderA,
+			HeaderB:   record.Evidence.HeaderB,
+		}
+		if statedb != nil {
+			punished, err := api.congress.doubleSignPunished(api.chain, head, statedb, record.Validator, evidence.Number)
+			if err != nil {
+				return nil, err
+			}
+			evidence.Punished = punished
+		}
+		evidences = append(evidences, evidence)
+	}
+	return evidences, nil
+}
+
+type activityReport struct {
+	From       uint64                                `json:"from"`
+	To         uint64                                `json:"to"`
+	Validators map[common.Address]*ValidatorActivity `json:"validators"` // Totals over the whole range
+	Epochs     []*EpochActivity                      `json:"epochs"`
+}
+
+// blockNumber resolves the given block number against the current head and the
+// finalized block, rejecting the tags which can't be resolved.
+func (api *API) blockNumber(number rpc.BlockNumber) (uint64, error) {
+	switch {
+	case number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber:
+		return api.chain.CurrentHeader().Number.Uint64(), nil
+	case number == rpc.FinalizedBlockNumber:
+		finalized, hash := api.congress.Finalized()
+		if hash == (common.Hash{}) {
+			return 0, errNoFinalizedBlock
+		}
+		return finalized, nil
+	case number < 0:
+		return 0, fmt.Errorf("unsupported block number tag %d", number)
+	}
+	return uint64(number.Int64()), nil
+}
+
+// header retrieves the header of the given block, or the current one if none
+// requested.
+func (api *API) header(number *rpc.BlockNumber) (*types.Header, error) {
+	if number == nil {
+		return api.chain.CurrentHeader(), nil
+	}
+	n, err := api.blockNumber(*number)
+	if err != nil {
+		return nil, err
+	}
+	header := api.chain.GetHeaderByNumber(n)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return header, nil
+}
+
+// blockRange resolves the given block numbers against the current head.
+func (api *API) blockRange(from rpc.BlockNumber, to rpc.BlockNumber) (uint64, uint64, error) {
+	start, err := api.blockNumber(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := api.blockNumber(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// GetValidatorActivity returns the in-turn and out-of-turn blocks sealed, the missed
+// in-turn slots and the punish events of every validator within the epochs overlapping
+// [from, to], broken down by epoch.
+func (api *API) GetValidatorActivity(from rpc.BlockNumber, to rpc.BlockNumber) (*activityReport, error) {
+	start, end, err := api.blockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	epochs, err := api.congress.rangeActivity(api.chain, start, end)
+	if err != nil {
+		return nil, err
+	}
+	report := &activityReport{
+		From:       epochs[0].From,
+		To:         epochs[len(epochs)-1].To,
+		Validators: make(map[common.Address]*ValidatorActivity),
+		Epochs:     epochs,
+	}
+	for _, epoch := range epochs {
+		for val, act := range epoch.Validators {
+			total, ok := report.Validators[val]
+			if !ok {
+				total = new(ValidatorActivity)
+				report.Validators[val] = total
+			}
+			total.add(act)
+		}
+	}
+	return report, nil
+}
+
+// GetValidatorActivityOf returns the activity of a single validator within the epochs overlapping [from, to], broken down by epoch.
+func (api *API) GetValidatorActivityOf(validator common.Address, from rpc.BlockNumber, to rpc.BlockNumber) (map[uint64]*ValidatorActivity, error) {
+	start, end, err := api.blockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	epochs, err := api.congress.rangeActivity(api.chain, start, end)
+	if err != nil {
+		return nil, err
+	}
+	activities := make(map[uint64]*ValidatorActivity)
+	for _, epoch := range epochs {
+		act, ok := epoch.Validators[validator]
+		if !ok {
+			act = new(ValidatorActivity)
+		}
+		activities[epoch.Epoch] = act
+	}
+	return activities, nil
+}
+
+// GetEpochActivity returns the activity of all validators within the given epoch.
+func (api *API) GetEpochActivity(epoch uint64) (*EpochActivity, error) {
+	return api.congress.epochActivity(api.chain, epoch)
+}
+
+// GetMissedBlocks returns the missed blocks counter of the validator in the Punish
+// contract, along with the thresholds at which it's punished and jailed.
+func (api *API) GetMissedBlocks(validator common.Address, number *rpc.BlockNumber) (*MissedBlocksEvent, error) {
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.congress.missedBlocksOf(validator, header)
+}
+
+// GetBlockRewardBreakdown returns how the fees of the given block were shared among
+// the recipients of its transactions, nil if no fee was distributed.
+func (api *API) GetBlockRewardBreakdown(number rpc.BlockNumber) (*BlockRewardReport, error) {
+	n, err := api.blockNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	header := api.chain.GetHeaderByNumber(n)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.congress.rewardReport(header)
+}
+
+// GetPendingProposals returns the passed system governance proposals waiting to be
+// executed by the next block.
+func (api *API) GetPendingProposals() ([]*ProposalInfo, error) {
+	return api.congress.pendingProposals(api.chain.CurrentHeader())
+}
+
+// GetProposal returns the system governance proposal with the given id, either once
+// executed by a canonical block or while waiting to be executed.
+func (api *API) GetProposal(id hexutil.Big) (*ProposalInfo, error) {
+	execution, err := api.congress.proposalExecution(id.ToInt())
+	if err != nil || execution != nil {
+		return execution, err
+	}
+	pending, err := api.congress.pendingProposals(api.chain.CurrentHeader())
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range pending {
+		if info.Id.ToInt().Cmp(id.ToInt()) == 0 {
+			return info, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown proposal %v", id.ToInt())
+}
+
+// blacklistHeader retrieves the header of the given block, or the current one if
+// none requested, along with the state of its parent the blacklist is read from.
+func (api *API) blacklistHeader(number *rpc.BlockNumber) (*types.Header, *state.StateDB, error) {
+	header, err := api.header(number)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Number.Sign() == 0 {
+		return nil, nil, errUnknownBlock
+	}
+	parent := api.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil, nil, consensus.ErrUnknownAncestor
+	}
+	statedb, err := api.congress.stateFn(parent.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, statedb, nil
+}
+
+// GetBlacklist returns the blacklisted addresses and their directions in effect
+// for the transactions of the given block.
+func (api *API) GetBlacklist(number *rpc.BlockNumber) (map[common.Address]blacklistDirection, error) {
+	header, statedb, err := api.blacklistHeader(number)
+	if err != nil {
+		return nil, err
+	}
+	if api.congress.chainConfig.RedCoastBlock == nil || api.congress.chainConfig.RedCoastBlock.Cmp(header.Number) >= 0 {
+		return nil, errBlacklistInactive
+	}
+	return api.congress.getBlacklist(header, statedb)
+}
+
+// GetEventCheckRules returns the event check rules in effect for the transactions
+// of the given block, keyed by event signature.
+func (api *API) GetEventCheckRules(number *rpc.BlockNumber) (map[common.Hash]*EventCheckRule, error) {
+	header, statedb, err := api.blacklistHeader(number)
+	if err != nil {
+		return nil, err
+	}
+	if api.congress.chainConfig.SophonBlock == nil || api.congress.chainConfig.SophonBlock.Cmp(header.Number) >= 0 {
+		return nil, errBlacklistInactive
+	}
+	return api.congress.getEventCheckRules(header, statedb)
+}
+
+// GetDenials returns the transactions denied by the blacklist within [from, to],
+// optionally only the ones denied because of the given address.
+func (api *API) GetDenials(from rpc.BlockNumber, to rpc.BlockNumber, address *common.Address) ([]*DenialRecord, error) {
+	start, end, err := api.blockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if to == rpc.PendingBlockNumber {
+		end++ // Transactions validated for the pending block
+	}
+	return api.congress.denials(start, end, address)
+}
+
+// MissedBlocks creates a subscription that is triggered each time the missed blocks
+// counter of the local validator changes.
+func (api *API) MissedBlocks(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	events := make(chan *MissedBlocksEvent, chainHeadChanSize)
+	sub, err := api.congress.SubscribeMissedBlocks(events)
+	if err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// Proposals creates a subscription that is triggered each time a system governance
+// proposal is created, passed or executed.
+func (api *API) Proposals(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	events := make(chan *ProposalInfo, chainHeadChanSize)
+	sub, err := api.congress.SubscribeProposals(events)
+	if err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -1,22 +1,100 @@
 package congress
 
 import (
+	"sort"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// EventCheckLocation is the part of an event holding an address checked by a rule.
+type EventCheckLocation uint8
+
+const (
+	LocationTopic EventCheckLocation = iota // Indexed argument, by topic index
+	LocationData                            // Non-indexed argument, by 32 bytes word offset in the ABI encoded data
+)
+
 type EventCheckRule struct {
-	EventSig common.Hash
-	Checks   map[int]common.AddressCheckType
+	EventSig   common.Hash                        `json:"eventSig"`
+	Checks     map[int]common.AddressCheckType    `json:"checks"`               // Address check type by topic index
+	DataChecks map[int]common.AddressCheckType    `json:"dataChecks,omitempty"` // Address check type by data word offset, after EventRulesBlock
+	Contracts  map[common.Address]*EventCheckRule `json:"contracts,omitempty"`  // Checks of the events emitted by a single contract only, after EventRulesBlock
+}
+
+func newEventCheckRule(sig common.Hash) *EventCheckRule {
+	return &EventCheckRule{
+		EventSig: sig,
+		Checks:   make(map[int]common.AddressCheckType),
+	}
+}
+
+// eventCheck is an address of an event checked by a rule.
+type eventCheck struct {
+	location EventCheckLocation
+	index    int // Topic index or data word offset
+	address  common.Address
+	cType    common.AddressCheckType
+}
+
+// checks returns the addresses of the event checked by the rule, along with the ones
+// checked by the rule of the emitting contract. Topics go first, then data words,
+// each in index order.
+func (r *EventCheckRule) checks(evLog *types.Log) []eventCheck {
+	var checks []eventCheck
+	for _, rule := range []*EventCheckRule{r, r.Contracts[evLog.Address]} {
+		if rule == nil {
+			continue
+		}
+		// Events without indexed arguments were never checked by topic
+		if len(evLog.Topics) > 1 {
+			for _, idx := range sortedChecks(rule.Checks) {
+				// do a basic check
+				if idx >= len(evLog.Topics) {
+					log.Error("check index in rule out to range", "sig", rule.EventSig.String(), "checkIdx", idx, "topicsLen", len(evLog.Topics))
+					continue
+				}
+				checks = append(checks, eventCheck{LocationTopic, idx, common.BytesToAddress(evLog.Topics[idx].Bytes()), rule.Checks[idx]})
+			}
+		}
+		for _, offset := range sortedChecks(rule.DataChecks) {
+			if offset < 0 || (offset+1)*common.HashLength > len(evLog.Data) {
+				log.Debug("check offset in rule out of data", "sig", rule.EventSig.String(), "checkOffset", offset, "dataLen", len(evLog.Data))
+				continue
+			}
+			word := evLog.Data[offset*common.HashLength : (offset+1)*common.HashLength]
+			checks = append(checks, eventCheck{LocationData, offset, common.BytesToAddress(word), rule.DataChecks[offset]})
+		}
+	}
+	return checks
+}
+
+// sortedChecks returns the indexes of the checks in increasing order.
+func sortedChecks(checks map[int]common.AddressCheckType) []int {
+	indexes := make([]int, 0, len(checks))
+	for idx := range checks {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	return indexes
 }
 
 type blacklistValidator struct {
 	blacks map[common.Address]blacklistDirection
 	rules  map[common.Hash]*EventCheckRule
+	audit  func(r *DenialRecord) // Records the denials in the audit log, may be nil
+}
+
+func (b *blacklistValidator) IsAddressDenied(address common.Address, cType common.AddressCheckType) bool {
+	d, hit := b.isAddressDenied(address, cType)
+	if hit && b.audit != nil {
+		b.audit(&DenialRecord{Address: address, Direction: d, Source: DenialEVM})
+	}
+	return hit
 }
 
-func (b *blacklistValidator) IsAddressDenied(address common.Address, cType common.AddressCheckType) (hit bool) {
+func (b *blacklistValidator) isAddressDenied(address common.Address, cType common.AddressCheckType) (d blacklistDirection, hit bool) {
 	d, exist := b.blacks[address]
 	if exist {
 		switch cType {
@@ -39,18 +117,16 @@ func (b *blacklistValidator) IsAddressDenied(address common.Address, cType commo
 }
 
 func (b *blacklistValidator) IsLogDenied(evLog *types.Log) bool {
-	if nil == evLog || len(evLog.Topics) <= 1 {
+	if nil == evLog || len(evLog.Topics) == 0 {
 		return false
 	}
 	if rule, exist := b.rules[evLog.Topics[0]]; exist {
-		for idx, checkType := range rule.Checks {
-			// do a basic check
-			if idx >= len(evLog.Topics) {
-				log.Error("check index in rule out to range", "sig", rule.EventSig.String(), "checkIdx", idx, "topicsLen", len(evLog.Topics))
-				continue
-			}
-			addr := common.BytesToAddress(evLog.Topics[idx].Bytes())
-			if b.IsAddressDenied(addr, checkType) {
+		for _, check := range rule.checks(evLog) {
+			if d, hit := b.isAddressDenied(check.address, check.cType); hit {
+				if b.audit != nil {
+					sig := rule.EventSig
+					b.audit(&DenialRecord{Address: check.address, Direction: d, Source: DenialLog, EventSig: &sig})
+				}
 				return true
 			}
 		}
@@ -0,0 +1,121 @@
+package congress
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// eventData ABI encodes the static words of an event data.
+func eventData(words ...common.Hash) []byte {
+	var data []byte
+	for _, word := range words {
+		data = append(data, word.Bytes()...)
+	}
+	return data
+}
+
+func TestIsLogDenied(t *testing.T) {
+	var (
+		transfer       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+		transferSingle = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+		transferBatch  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+
+		from     = common.HexToAddress("0xf0")
+		to       = common.HexToAddress("0x70")
+		clean    = common.HexToAddress("0xc1ea")
+		token    = common.HexToAddress("0x20")
+		other    = common.HexToAddress("0x21")
+		amount   = common.BigToHash(big.NewInt(1000))
+		tokenID  = common.BigToHash(big.NewInt(7))
+		checkAll = map[int]common.AddressCheckType{1: common.CheckFrom, 2: common.CheckTo}
+	)
+	validator := &blacklistValidator{
+		blacks: map[common.Address]blacklistDirection{from: DirectionFrom, to: DirectionTo},
+		rules: map[common.Hash]*EventCheckRule{
+			// ERC-20 and ERC-721 transfers, indexed by every compliant token, and by
+			// position in the data for a legacy token leaving its arguments unindexed
+			transfer: {
+				EventSig: transfer,
+				Checks:   checkAll,
+				Contracts: map[common.Address]*EventCheckRule{
+					token: {EventSig: transfer, DataChecks: map[int]common.AddressCheckType{0: common.CheckFrom, 1: common.CheckTo}},
+				},
+			},
+			// ERC-1155 transfers, with the operator first
+			transferSingle: {EventSig: transferSingle, Checks: map[int]common.AddressCheckType{2: common.CheckFrom, 3: common.CheckTo}},
+			transferBatch:  {EventSig: transferBatch, Checks: map[int]common.AddressCheckType{2: common.CheckFrom, 3: common.CheckTo}},
+		},
+	}
+	tests := []struct {
+		name   string
+		log    *types.Log
+		denied bool
+	}{
+		{"erc20 from", &types.Log{Address: other, Topics: []common.Hash{transfer, from.Hash(), clean.Hash()}, Data: amount.Bytes()}, true},
+		{"erc20 to", &types.Log{Address: other, Topics: []common.Hash{transfer, clean.Hash(), to.Hash()}, Data: amount.Bytes()}, true},
+		{"erc20 wrong direction", &types.Log{Address: other, Topics: []common.Hash{transfer, to.Hash(), from.Hash()}, Data: amount.Bytes()}, false},
+		{"erc20 unindexed from", &types.Log{Address: token, Topics: []common.Hash{transfer}, Data: eventData(from.Hash(), clean.Hash(), amount)}, true},
+		{"erc20 unindexed to", &types.Log{Address: token, Topics: []common.Hash{transfer}, Data: eventData(clean.Hash(), to.Hash(), amount)}, true},
+		{"erc20 unindexed other contract", &types.Log{Address: other, Topics: []common.Hash{transfer}, Data: eventData(from.Hash(), to.Hash(), amount)}, false},
+		{"erc20 unindexed short data", &types.Log{Address: token, Topics: []common.Hash{transfer}, Data: from.Hash().Bytes()[:31]}, false},
+		{"erc721 from", &types.Log{Address: other, Topics: []common.Hash{transfer, from.Hash(), clean.Hash(), tokenID}}, true},
+		{"erc721 to", &types.Log{Address: token, Topics: []common.Hash{transfer, clean.Hash(), to.Hash(), tokenID}}, true},
+		{"erc721 clean", &types.Log{Address: other, Topics: []common.Hash{transfer, clean.Hash(), clean.Hash(), tokenID}}, false},
+		{"erc1155 single", &types.Log{Address: other, Topics: []common.Hash{transferSingle, clean.Hash(), clean.Hash(), to.Hash()}, Data: eventData(tokenID, amount)}, true},
+		{"erc1155 single operator", &types.Log{Address: other, Topics: []common.Hash{transferSingle, from.Hash(), clean.Hash(), clean.Hash()}, Data: eventData(tokenID, amount)}, false},
+		{"erc1155 batch", &types.Log{
+			Address: other,
+			Topics:  []common.Hash{transferBatch, clean.Hash(), from.Hash(), clean.Hash()},
+			Data:    eventData(common.BigToHash(big.NewInt(64)), common.BigToHash(big.NewInt(128)), common.BigToHash(common.Big1), tokenID, common.BigToHash(common.Big1), amount),
+		}, true},
+		{"unknown event", &types.Log{Address: token, Topics: []common.Hash{common.HexToHash("0x01"), from.Hash(), to.Hash()}}, false},
+	}
+	for _, tt := range tests {
+		if denied := validator.IsLogDenied(tt.log); denied != tt.denied {
+			t.Errorf("%s: denied mismatch: have %v, want %v", tt.name, denied, tt.denied)
+		}
+	}
+}
+
+// Tests that the checks of a rule are ordered topics first, and include the ones
+// scoped to the emitting contract only.
+func TestEventCheckRuleChecks(t *testing.T) {
+	var (
+		sig   = common.HexToHash("0x01")
+		token = common.HexToAddress("0x20")
+		addrs = []common.Address{common.HexToAddress("0xa1"), common.HexToAddress("0xa2"), common.HexToAddress("0xa3")}
+	)
+	rule := &EventCheckRule{
+		EventSig:   sig,
+		Checks:     map[int]common.AddressCheckType{2: common.CheckTo, 1: common.CheckFrom},
+		DataChecks: map[int]common.AddressCheckType{1: common.CheckBothInAny},
+		Contracts: map[common.Address]*EventCheckRule{
+			token: {EventSig: sig, DataChecks: map[int]common.AddressCheckType{0: common.CheckFrom}},
+		},
+	}
+	evLog := &types.Log{Address: token, Topics: []common.Hash{sig, addrs[0].Hash(), addrs[1].Hash()}, Data: eventData(addrs[2].Hash(), addrs[0].Hash())}
+
+	want := []eventCheck{
+		{LocationTopic, 1, addrs[0], common.CheckFrom},
+		{LocationTopic, 2, addrs[1], common.CheckTo},
+		{LocationData, 1, addrs[0], common.CheckBothInAny},
+		{LocationData, 0, addrs[2], common.CheckFrom},
+	}
+	have := rule.checks(evLog)
+	if len(have) != len(want) {
+		t.Fatalf("checks mismatch: have %v, want %v", have, want)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("check %d mismatch: have %v, want %v", i, have[i], want[i])
+		}
+	}
+	evLog.Address = common.HexToAddress("0x21")
+	if have := rule.checks(evLog); len(have) != 3 {
+		t.Errorf("checks of another contract mismatch: have %v", have)
+	}
+}
@@ -0,0 +1,275 @@
+package congress
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// errUnlinkedBlock is returned if the canonical chain changed while being indexed.
+var errUnlinkedBlock = errors.New("block not linked to the indexed chain")
+
+// blockData is the side data produced while processing a block, which is kept in
+// memory until the block turns canonical. Processing a block doesn't mean it will
+// be published or stay canonical: a locally sealed block may be discarded, and the
+// blocks are also processed for side chains, tracing and state regeneration.
+type blockData struct {
+	activity   *blockActivity     // In-turn validator an out-of-turn block was sealed in place of
+	reward     *BlockRewardReport // Fee distribution of the block, nil if no fee was distributed
+	denials    []*DenialRecord    // Denials raised by the transactions of the block
+	executions []*ProposalInfo    // Governance proposals executed by the block
+}
+
+func (d *blockData) empty() bool {
+	return d.activity == nil && d.reward == nil && len(d.denials) == 0 && len(d.executions) == 0
+}
+
+// addBlockData keeps the side data of a processed block until it turns canonical.
+func (c *Congress) addBlockData(header *types.Header, data *blockData) {
+	if !data.empty() {
+		c.processedBlocks.Add(SealHash(header), data)
+	}
+}
+
+// processedBlock retrieves the side data of a processed block, nil if unknown.
+func (c *Congress) processedBlock(header *types.Header) *blockData {
+	if data, ok := c.processedBlocks.Get(SealHash(header)); ok {
+		return data.(*blockData)
+	}
+	return nil
+}
+
+// blockIndexer persists the side data of the processed blocks once they turn
+// canonical, and maintains the sealing activity index of the epochs with them.
+type blockIndexer struct {
+	congress *Congress
+	activity *EpochActivity // Activity of the epoch of the latest indexed block, nil before the first head
+	tail     uint64         // Number below which the records of the out-of-turn blocks can be pruned
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// StartBlockIndexer starts indexing the canonical blocks on every new chain head.
+func (c *Congress) StartBlockIndexer(chain chainHeadSubscriber) {
+	c.indexer = &blockIndexer{
+		congress: c,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go c.indexer.loop(chain)
+}
+
+func (x *blockIndexer) loop(chain chainHeadSubscriber) {
+	defer close(x.done)
+
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	// Catch up with the blocks imported while the indexer wasn't running
+	if head := x.congress.chain.CurrentHeader(); head != nil {
+		x.update(head)
+	}
+	for {
+		select {
+		case ev := <-headCh:
+			x.update(ev.Block.Header())
+		case <-sub.Err():
+			return
+		case <-x.quit:
+			return
+		}
+	}
+}
+
+func (x *blockIndexer) stop() {
+	close(x.quit)
+	<-x.done
+}
+
+// update reverts the indexed blocks reorged away and indexes the canonical blocks up
+// to the new head.
+func (x *blockIndexer) update(head *types.Header) {
+	c := x.congress
+	if x.activity == nil {
+		act, err := x.resume(head)
+		if err != nil {
+			log.Warn("Failed to resume the block index", "number", head.Number, "err", err)
+			return
+		}
+		x.activity = act
+	}
+	batch := c.db.NewBatch()
+	err := x.rewind(batch)
+	for n := x.activity.To + 1; err == nil && n <= head.Number.Uint64(); n++ {
+		header := c.chain.GetHeaderByNumber(n)
+		if header == nil {
+			break
+		}
+		if err = x.index(batch, header); err == nil && batch.ValueSize() >= ethdb.IdealBatchSize {
+			err = batch.Write()
+			batch.Reset()
+		}
+	}
+	if err == nil {
+		err = x.activity.store(batch)
+	}
+	if err == nil {
+		err = batch.Write()
+	}
+	// Prune once written, so the records of the batch go too
+	if err == nil && x.tail > 0 {
+		batch.Reset()
+		x.prune(batch, x.tail)
+		if err = batch.Write(); err == nil {
+			x.tail = 0
+		}
+	}
+	if err != nil {
+		// Start over from the stored index on the next head
+		log.Warn("Failed to index blocks", "number", head.Number, "err", err)
+		x.activity = nil
+	}
+}
+
+// resume returns the activity to resume indexing from: the stored activity of the
+// epoch of the head or of the one before, or an empty activity of the epoch of the
+// head if none is stored.
+func (x *blockIndexer) resume(head *types.Header) (*EpochActivity, error) {
+	c := x.congress
+	epoch := head.Number.Uint64() / c.config.Epoch
+	if act, err := loadEpochActivity(c.db, epoch); err == nil {
+		return act, nil
+	}
+	if epoch > 0 {
+		if act, err := loadEpochActivity(c.db, epoch-1); err == nil {
+			return act, nil
+		}
+	}
+	from, _ := c.epochRange(epoch)
+	parent := c.chain.GetHeaderByNumber(from - 1)
+	if parent == nil {
+		return nil, errUnknownBlock
+	}
+	act := newEpochActivity(epoch, from, from-1)
+	act.Hash = parent.Hash()
+	return act, nil
+}
+
+// rewind reverts the indexed blocks which aren't canonical anymore, down to the
+// last common block with the canonical chain.
+func (x *blockIndexer) rewind(batch ethdb.Batch) error {
+	c := x.congress
+	for x.activity.To > 0 {
+		act := x.activity
+		if canonical := c.chain.GetHeaderByNumber(act.To); canonical != nil && canonical.Hash() == act.Hash {
+			return nil
+		}
+		header := c.chain.GetHeader(act.Hash, act.To)
+		if header == nil {
+			return errUnknownBlock
+		}
+		var block *blockActivity
+		if header.Difficulty.Cmp(diffInTurn) != 0 {
+			activity, err := c.readBlockActivity(c.chain, header)
+			if err != nil {
+				return err
+			}
+			block = activity
+		}
+		sealer, err := c.Author(header)
+		if err != nil {
+			return err
+		}
+		act.revert(sealer, block)
+		act.To, act.Hash = act.To-1, header.ParentHash
+
+		if err := batch.Delete(rewardKey(SealHash(header))); err != nil {
+			return err
+		}
+
+		// Continue with the previous epoch once the whole epoch is reverted
+		if act.To < act.From && act.Epoch > 0 {
+			rawdb.DeleteCongressActivity(batch, act.Epoch)
+			prev, err := loadEpochActivity(c.db, act.Epoch-1)
+			if err != nil {
+				return err
+			}
+			x.activity = prev
+		}
+	}
+	return nil
+}
+
+// index adds a new canonical block to the activity of its epoch and persists its
+// side data. The activity of an epoch is stored once the next one starts, and the
+// records of the out-of-turn blocks are pruned one epoch later, as they're only
+// kept to revert the blocks reorged away.
+func (x *blockIndexer) index(batch ethdb.Batch, header *types.Header) error {
+	c := x.congress
+	if header.ParentHash != x.activity.Hash {
+		return errUnlinkedBlock
+	}
+	number := header.Number.Uint64()
+	if epoch := number / c.config.Epoch; epoch != x.activity.Epoch {
+		if err := x.activity.store(batch); err != nil {
+			return err
+		}
+		x.tail = x.activity.From
+		x.activity = newEpochActivity(epoch, number, number-1)
+	}
+	data := c.processedBlock(header)
+
+	var block *blockActivity
+	if header.Difficulty.Cmp(diffInTurn) != 0 {
+		if data != nil && data.activity != nil {
+			block = data.activity
+		} else {
+			activity, err := c.readBlockActivity(c.chain, header)
+			if err != nil {
+				return err
+			}
+			block = activity
+		}
+		if err := writeBlockActivity(batch, header, block); err != nil {
+			return err
+		}
+	}
+	if data != nil && data.reward != nil {
+		if err := data.reward.store(batch, SealHash(header)); err != nil {
+			return err
+		}
+	}
+	if data != nil {
+		if err := storeProposalExecutions(batch, header, data.executions); err != nil {
+			return err
+		}
+		c.commitDenials(header, data.denials)
+	}
+	sealer, err := c.Author(header)
+	if err != nil {
+		return err
+	}
+	x.activity.record(sealer, block)
+	x.activity.To, x.activity.Hash = number, header.Hash()
+	return nil
+}
+
+// prune deletes the records of the out-of-turn blocks below the given number.
+func (x *blockIndexer) prune(batch ethdb.Batch, number uint64) {
+	it := rawdb.IterateCongressBlockActivities(x.congress.db)
+	defer it.Release()
+
+	for it.Next() {
+		if n, ok := rawdb.CongressBlockActivityNumber(it.Key()); ok && n >= number {
+			break
+		}
+		batch.Delete(common.CopyBytes(it.Key()))
+	}
+}
@@ -0,0 +1,173 @@
+package congress
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// ComplianceHit is a blacklist check which would deny a transaction.
+type ComplianceHit struct {
+	Source     DenialSource            `json:"source"`
+	Address    common.Address          `json:"address"`
+	Direction  blacklistDirection      `json:"direction"`            // Blacklist direction of the address
+	CheckType  common.AddressCheckType `json:"checkType"`            // Direction the address was checked in, 1 for from, 2 for to
+	Depth      int                     `json:"depth"`                // Depth of the call frame running the check, 0 for the transaction itself
+	Contract   *common.Address         `json:"contract,omitempty"`   // Contract emitting the denied event, only for the log source
+	EventSig   *common.Hash            `json:"eventSig,omitempty"`   // Signature of the denied event, only for the log source
+	TopicIndex *int                    `json:"topicIndex,omitempty"` // Topic holding the denied address, only for the log source
+	DataOffset *int                    `json:"dataOffset,omitempty"` // Data word holding the denied address, only for the log source
+	LogIndex   *uint                   `json:"logIndex,omitempty"`   // Index the denied event would have in the transaction, only for the log source
+}
+
+// ComplianceChecker runs the blacklist checks of a transaction in a dry run. It
+// records every check which would deny the transaction without denying it, so that
+// the transaction executes to completion and all hits are reported.
+//
+// It's both the extra validator and the tracer of the EVM, the latter only to track
+// the depth of the call frames.
+type ComplianceChecker struct {
+	validator *blacklistValidator // Blacklist and rules in effect
+	txChecks  bool                // Whether the sender and recipient of the transaction are checked
+	evmChecks bool                // Whether the calls and events are checked
+	state     *state.StateDB      // State the transaction executes on, to index the events
+	depth     int                 // Depth of the call frame currently executing
+
+	Hits []*ComplianceHit
+}
+
+// NewComplianceChecker creates a checker of the blacklist in effect for the
+// transactions of the block after the given one, executing on top of its state.
+// Like the transaction pool, the checks are those of the next block, whose
+// blacklist is read from the given state.
+func (c *Congress) NewComplianceChecker(parent *types.Header, statedb *state.StateDB) (*ComplianceChecker, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Difficulty: new(big.Int).Set(parent.Difficulty),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 1,
+	}
+	checker := &ComplianceChecker{
+		validator: &blacklistValidator{},
+		state:     statedb,
+	}
+	if c.chainConfig.RedCoastBlock != nil && c.chainConfig.RedCoastBlock.Cmp(header.Number) < 0 {
+		blacks, err := c.getBlacklist(header, statedb)
+		if err != nil {
+			return nil, err
+		}
+		checker.validator.blacks = blacks
+		checker.txChecks = true
+	}
+	// The EVM doesn't check anything before Sophon
+	if c.chainConfig.SophonBlock != nil && c.chainConfig.SophonBlock.Cmp(header.Number) < 0 {
+		rules, err := c.getEventCheckRules(header, statedb)
+		if err != nil {
+			return nil, err
+		}
+		checker.validator.rules = rules
+		checker.evmChecks = true
+	}
+	return checker, nil
+}
+
+// CheckTx runs the checks done on the sender and recipient of a transaction before
+// it's accepted into the pool.
+func (cc *ComplianceChecker) CheckTx(from common.Address, to *common.Address) {
+	if !cc.txChecks {
+		return
+	}
+	blacks := cc.validator.blacks
+	if d, exist := blacks[from]; exist && d != DirectionTo {
+		cc.Hits = append(cc.Hits, &ComplianceHit{Source: DenialValidateTx, Address: from, Direction: d, CheckType: common.CheckFrom})
+	}
+	if to != nil {
+		if d, exist := blacks[*to]; exist && d != DirectionFrom {
+			cc.Hits = append(cc.Hits, &ComplianceHit{Source: DenialValidateTx, Address: *to, Direction: d, CheckType: common.CheckTo})
+		}
+	}
+}
+
+// Denied returns whether any check would deny the transaction.
+func (cc *ComplianceChecker) Denied() bool {
+	return len(cc.Hits) > 0
+}
+
+// IsAddressDenied implements types.EvmExtraValidator, recording the hit and
+// allowing the call.
+func (cc *ComplianceChecker) IsAddressDenied(address common.Address, cType common.AddressCheckType) bool {
+	if !cc.evmChecks {
+		return false
+	}
+	if d, hit := cc.validator.isAddressDenied(address, cType); hit {
+		cc.Hits = append(cc.Hits, &ComplianceHit{Source: DenialEVM, Address: address, Direction: d, CheckType: cType, Depth: cc.depth})
+	}
+	return false
+}
+
+// IsLogDenied implements types.EvmExtraValidator, recording every denied address
+// of the event and allowing it.
+func (cc *ComplianceChecker) IsLogDenied(evLog *types.Log) bool {
+	if !cc.evmChecks || evLog == nil || len(evLog.Topics) == 0 {
+		return false
+	}
+	rule, exist := cc.validator.rules[evLog.Topics[0]]
+	if !exist {
+		return false
+	}
+	for _, check := range rule.checks(evLog) {
+		if d, hit := cc.validator.isAddressDenied(check.address, check.cType); hit {
+			var (
+				contract = evLog.Address
+				sig      = rule.EventSig
+				idx      = check.index
+				index    = uint(len(cc.state.Logs()))
+			)
+			record := &ComplianceHit{
+				Source:    DenialLog,
+				Address:   check.address,
+				Direction: d,
+				CheckType: check.cType,
+				Depth:     cc.depth,
+				Contract:  &contract,
+				EventSig:  &sig,
+				LogIndex:  &index,
+			}
+			if check.location == LocationData {
+				record.DataOffset = &idx
+			} else {
+				record.TopicIndex = &idx
+			}
+			cc.Hits = append(cc.Hits, record)
+		}
+	}
+	return false
+}
+
+// CaptureStart implements vm.EVMLogger.
+func (cc *ComplianceChecker) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureState implements vm.EVMLogger, tracking the depth of the executing frame.
+func (cc *ComplianceChecker) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	cc.depth = depth
+}
+
+// CaptureEnter implements vm.EVMLogger.
+func (cc *ComplianceChecker) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit implements vm.EVMLogger.
+func (cc *ComplianceChecker) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureFault implements vm.EVMLogger.
+func (cc *ComplianceChecker) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnd implements vm.EVMLogger.
+func (cc *ComplianceChecker) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) {}
@@ -0,0 +1,115 @@
+package congress
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the compliance checker reports every hit without denying anything.
+func TestComplianceChecker(t *testing.T) {
+	var (
+		from       = common.HexToAddress("0xf0")
+		to         = common.HexToAddress("0x70")
+		both       = common.HexToAddress("0xb0")
+		token      = common.HexToAddress("0x20")
+		sig        = common.HexToHash("0xddf252ad")
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	)
+	checker := &ComplianceChecker{
+		validator: &blacklistValidator{
+			blacks: map[common.Address]blacklistDirection{from: DirectionFrom, to: DirectionTo, both: DirectionBoth},
+			rules: map[common.Hash]*EventCheckRule{
+				sig: {EventSig: sig, Checks: map[int]common.AddressCheckType{1: common.CheckFrom, 2: common.CheckTo}},
+			},
+		},
+		txChecks:  true,
+		evmChecks: true,
+		state:     statedb,
+	}
+	// Only the sending direction of the sender and the receiving one of the recipient count
+	checker.CheckTx(to, &from)
+	if checker.Denied() {
+		t.Fatalf("transaction denied in the wrong directions: %v", checker.Hits)
+	}
+	checker.CheckTx(from, &to)
+	if len(checker.Hits) != 2 || checker.Hits[0].Address != from || checker.Hits[1].Address != to {
+		t.Fatalf("transaction hits mismatch: %v", checker.Hits)
+	}
+	// Calls and events are allowed, but reported with their depth and position
+	checker.CaptureState(0, 0, 0, 0, nil, nil, 2, nil)
+	if checker.IsAddressDenied(both, common.CheckTo) {
+		t.Fatalf("call denied in a dry run")
+	}
+	statedb.AddLog(&types.Log{Address: token})
+	if checker.IsLogDenied(&types.Log{Address: token, Topics: []common.Hash{sig, both.Hash(), both.Hash()}}) {
+		t.Fatalf("event denied in a dry run")
+	}
+	if len(checker.Hits) != 5 {
+		t.Fatalf("hits mismatch: have %d, want 5", len(checker.Hits))
+	}
+	if hit := checker.Hits[2]; hit.Source != DenialEVM || hit.Depth != 2 || hit.Direction != DirectionBoth {
+		t.Errorf("call hit mismatch: %+v", hit)
+	}
+	for i, hit := range checker.Hits[3:] {
+		if hit.Source != DenialLog || *hit.Contract != token || *hit.EventSig != sig || *hit.TopicIndex != i+1 || *hit.LogIndex != 1 {
+			t.Errorf("event hit %d mismatch: %+v", i, hit)
+		}
+	}
+}
+
+// Tests that the checker evaluates the blacklist of the block after the given one,
+// checking the transactions from RedCoast on and the EVM from Sophon on.
+func TestNewComplianceChecker(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.RedCoastBlock = big.NewInt(5)
+	config.SophonBlock = big.NewInt(10)
+
+	var (
+		engine     = New(&config, rawdb.NewMemoryDatabase())
+		blocked    = common.HexToAddress("0xbad")
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	)
+	tests := []struct {
+		number    int64
+		txChecks  bool
+		evmChecks bool
+	}{
+		{4, false, false},
+		{5, true, false},
+		{9, true, false},
+		{10, true, true},
+	}
+	for _, tt := range tests {
+		parent := &types.Header{Number: big.NewInt(tt.number), Difficulty: big.NewInt(2)}
+
+		// The blacklist and rules of the next block are cached by their parent hash
+		engine.blacklists.Add(parent.Hash(), map[common.Address]blacklistDirection{blocked: DirectionBoth})
+		engine.eventCheckRules.Add(parent.Hash(), map[common.Hash]*EventCheckRule{})
+
+		checker, err := engine.NewComplianceChecker(parent, statedb)
+		if err != nil {
+			t.Fatalf("parent %d: failed to create checker: %v", tt.number, err)
+		}
+		if checker.txChecks != tt.txChecks || checker.evmChecks != tt.evmChecks {
+			t.Errorf("parent %d: checks mismatch: have tx %v evm %v, want tx %v evm %v", tt.number, checker.txChecks, checker.evmChecks, tt.txChecks, tt.evmChecks)
+		}
+		checker.CheckTx(blocked, nil)
+		checker.IsAddressDenied(blocked, common.CheckTo)
+		if want := btoi(tt.txChecks) + btoi(tt.evmChecks); len(checker.Hits) != want {
+			t.Errorf("parent %d: hits mismatch: have %d, want %d", tt.number, len(checker.Hits), want)
+		}
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -65,6 +65,9 @@ const (
 	maxValidators = 21                     // Max validators allowed to seal.
 
 	inmemoryBlacklist = 21 // Number of recent blacklist snapshots to keep in memory
+
+	inmemorySealedHeaders   = 4096 // Number of recent sealed headers to keep in memory for double-sign detection
+	inmemoryProcessedBlocks = 128  // Number of recently processed blocks to keep the side data of until canonical
 )
 
 type blacklistDirection uint
@@ -75,6 +78,39 @@ const (
 	DirectionBoth
 )
 
+func (d blacklistDirection) String() string {
+	switch d {
+	case DirectionFrom:
+		return "from"
+	case DirectionTo:
+		return "to"
+	case DirectionBoth:
+		return "both"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d blacklistDirection) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *blacklistDirection) UnmarshalText(input []byte) error {
+	switch string(input) {
+	case "from":
+		*d = DirectionFrom
+	case "to":
+		*d = DirectionTo
+	case "both":
+		*d = DirectionBoth
+	default:
+		return fmt.Errorf("unknown blacklist direction %q", input)
+	}
+	return nil
+}
+
 // Congress proof-of-stake-authority protocol constants.
 var (
 	epochLength = uint64(30000) // Default number of blocks after which to checkpoint and reset the pending votes
@@ -140,13 +176,29 @@ var (
 	// that already signed a header recently, thus is temporarily not allowed to.
 	errRecentlySigned = errors.New("recently signed")
 
-	// errInvalidValidatorLen is returned if validators length is zero or bigger than maxValidators.
+	// errInvalidValidatorLen is returned if validators length is zero or bigger than the validators limit.
 	errInvalidValidatorsLength = errors.New("Invalid validators length")
 
 	// errInvalidCoinbase is returned if the coinbase isn't the validator of the block.
 	errInvalidCoinbase = errors.New("Invalid coin base")
 
 	errInvalidSysGovCount = errors.New("invalid system governance tx count")
+
+	// errInvalidSysGovSender is returned if a system governance transaction isn't
+	// sent by the validator of the block.
+	errInvalidSysGovSender = errors.New("invalid sender for system governance transaction")
+
+	// errInvalidSysGovTarget is returned if a system governance transaction isn't
+	// sent to the system governance address.
+	errInvalidSysGovTarget = errors.New("invalid target for system governance transaction")
+
+	// errInvalidSysGovData is returned if the data of a system governance transaction
+	// isn't the canonical encoding of a proposal.
+	errInvalidSysGovData = errors.New("invalid system governance transaction data")
+
+	// errSysGovProposalMismatch is returned if the proposal carried by a system
+	// governance transaction differs from the passed one of the contract.
+	errSysGovProposalMismatch = errors.New("system governance proposal mismatch")
 )
 
 var (
@@ -196,11 +248,19 @@ type Congress struct {
 	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
 
+	sealedHeaders *lru.ARCCache // Recent headers keyed by height and validator to detect double signs
+
+	processedBlocks *lru.ARCCache // Side data of recently processed blocks keyed by seal hash, persisted once canonical
+
 	blacklists      *lru.Cache // blacklists caches recent blacklist to speed up transactions validation
 	blLock          sync.Mutex // Make sure only get blacklist once for each block
 	eventCheckRules *lru.Cache // eventCheckRules caches recent EventCheckRules to speed up log validation
 	rulesLock       sync.Mutex // Make sure only get eventCheckRules once for each block
 
+	denialAudits *lru.ARCCache // Denials raised by the EVM, keyed by the number of the blocks under processing
+	auditLock    sync.Mutex    // Protects the creation of denial audits
+	denialWriter *denialWriter // Persists the denial records, nil if the audit log isn't started
+
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
 	signer types.Signer // the signer instance to recover tx sender
@@ -216,6 +276,13 @@ type Congress struct {
 
 	chain consensus.ChainHeaderReader // chain is only for reading parent headers when getting blacklist and rules
 
+	snapStore SnapshotStoreConfig // Settings of the epoch snapshot store, protected by lock
+
+	monitor         *missedBlocksMonitor // Watches the missed blocks counter of the local validator, nil if not started
+	finality        *finality            // Collects the validator votes finalizing blocks, nil if not started
+	proposalWatcher *proposalWatcher     // Reports the lifecycle of the governance proposals, nil if not started
+	indexer         *blockIndexer        // Persists the side data of the canonical blocks, nil if not started
+
 	// The fields below are for testing only
 	fakeDiff bool // Skip difficulty verifications
 }
@@ -231,8 +298,11 @@ func New(chainConfig *params.ChainConfig, db ethdb.Database) *Congress {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	signatures, _ := lru.NewARC(inmemorySignatures)
+	sealedHeaders, _ := lru.NewARC(inmemorySealedHeaders)
+	processedBlocks, _ := lru.NewARC(inmemoryProcessedBlocks)
 	blacklists, _ := lru.New(inmemoryBlacklist)
 	rules, _ := lru.New(inmemoryBlacklist)
+	denialAudits, _ := lru.NewARC(inmemoryDenialAudits)
 
 	abi := systemcontract.GetInteractiveABI()
 
@@ -242,8 +312,11 @@ func New(chainConfig *params.ChainConfig, db ethdb.Database) *Congress {
 		db:              db,
 		recents:         recents,
 		signatures:      signatures,
+		sealedHeaders:   sealedHeaders,
+		processedBlocks: processedBlocks,
 		blacklists:      blacklists,
 		eventCheckRules: rules,
+		denialAudits:    denialAudits,
 		proposals:       make(map[common.Address]bool),
 		abi:             abi,
 		signer:          types.LatestSignerForChainID(chainConfig.ChainID),
@@ -325,6 +398,11 @@ func (c *Congress) verifyHeader(chain consensus.ChainHeaderReader, header *types
 	if isEpoch && validatorsBytes%common.AddressLength != 0 {
 		return errExtraValidators
 	}
+	if isEpoch && c.config.IsValidatorsLimit(header.Number) {
+		if count := validatorsBytes / common.AddressLength; count == 0 || count > validatorsLimit(c.config, number) {
+			return errInvalidValidatorsLength
+		}
+	}
 
 	// Ensure that the mix digest is zero as we don't have fork protection currently
 	if header.MixDigest != (common.Hash{}) {
@@ -418,6 +496,14 @@ func (c *Congress) snapshot(chain consensus.ChainHeaderReader, number uint64, ha
 				break
 			}
 		}
+		// If the snapshot of the epoch is indexed, use that
+		if number%c.config.Epoch == 0 {
+			if s := c.epochSnapshot(number, hash); s != nil {
+				log.Trace("Loaded epoch snapshot from disk", "number", number, "hash", hash)
+				snap = s
+				break
+			}
+		}
 		// If we're at the genesis, snapshot the initial state. Alternatively if we're
 		// at a checkpoint block without a parent (light client CHT), or we have piled
 		// up more headers than allowed to be reorged (chain reinit from a freezer),
@@ -432,9 +518,13 @@ func (c *Congress) snapshot(chain consensus.ChainHeaderReader, number uint64, ha
 					copy(validators[i][:], checkpoint.Extra[extraVanity+i*common.AddressLength:])
 				}
 				snap = newSnapshot(c.config, c.signatures, number, hash, validators)
+				snap.updateProposers(checkpoint)
 				if err := snap.store(c.db); err != nil {
 					return nil, err
 				}
+				if err := c.storeEpochSnapshot(snap); err != nil {
+					return nil, err
+				}
 				log.Info("Stored checkpoint snapshot to disk", "number", number, "hash", hash)
 				break
 			}
@@ -475,6 +565,12 @@ func (c *Congress) snapshot(chain consensus.ChainHeaderReader, number uint64, ha
 		}
 		log.Trace("Stored voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
 	}
+	// Index the snapshots of the checkpoint blocks by epoch
+	if snap.Number%c.config.Epoch == 0 && len(headers) > 0 {
+		if err = c.storeEpochSnapshot(snap); err != nil {
+			return nil, err
+		}
+	}
 	return snap, err
 }
 
@@ -517,18 +613,13 @@ func (c *Congress) verifySeal(chain consensus.ChainHeaderReader, header *types.H
     if _, ok := snap.Validators[signer]; !ok {
         return errUnauthorizedValidator
     }
+    c.recordSealedHeader(signer, header)
 
     for seen, recent := range snap.Recents {
         if recent == signer {
-            var limit uint64
-			limit = uint64(len(snap.Validators)/2 + 1)
-            if len(snap.Validators) > 21 || len(snap.Validators) == 1  {
-                limit = uint64(len(snap.Validators)/2 + 1)
-            } else {  //if number > 9299500 {  // Replace 'someGivenNumber' with the actual variable or value you want to compare against
-                limit = 2
-            }
+            limit := recentsLimit(c.config, number, len(snap.Validators))
             // Validator is among recents, only fail if the current block doesn't shift it out
-            if seen > number-limit {
+            if signedRecently(c.config, number, seen, limit) {
                 return errors.New("signed recently location-1")
             }
         }
@@ -617,10 +708,13 @@ func (c *Congress) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 		header.BaseFee = misc.CalcBaseFee(chain.Config(), parent)
 	}
 
+	var activity *blockActivity
 	if header.Difficulty.Cmp(diffInTurn) != 0 {
-		if err := c.tryPunishValidator(chain, header, state); err != nil {
+		act, err := c.tryPunishValidator(chain, header, state)
+		if err != nil {
 			return err
 		}
+		activity = act
 	}
 
 	// avoid nil pointer
@@ -634,48 +728,13 @@ func (c *Congress) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 	}
 
 	// deposit block reward if any tx exists.
-	var addr [] common.Address
-	var gass [] uint64
-
-
+	var rewardReport *BlockRewardReport
 	if len(*txs) > 0 {
-				
-		var totalGasSum uint64
-
-		for i := 0; i < len(*txs); i++ {
-			TO := (*txs)[i]
-
-			if TO.To() == nil {
-				addr = append(addr, common.HexToAddress("0x0000000000000000000000000000000000000000"))
-			} else {
-				addr = append(addr, *TO.To())
-			}
-
-			gasFee := TO.Gas() * TO.GasPrice().Uint64()
-			gass = append(gass, gasFee)
-
-			// Accumulate gasFee to totalGasSum
-			totalGasSum += gasFee
-		}
-		
-	    fee := state.GetBalance(consensus.FeeRecoder)
-
-		feeUint64 := fee.Uint64()
-
-		if totalGasSum > feeUint64 {
-		
-			percentDifference := float64(totalGasSum-feeUint64) / float64(totalGasSum) * 100
-
-			for i := 0; i < len(gass); i++ {
-				decreaseAmount := uint64(float64(gass[i]) * (percentDifference / 100.0))
-				gass[i] -= decreaseAmount
-			}
-		}
-	    	
-		if err := c.trySendBlockReward(chain, header, state,addr,gass); err != nil {
-			//panic(err)
-			log.Info(err.Error())
+		report, err := c.distributeBlockReward(chain, header, state, *txs, *receipts)
+		if err != nil {
+			return err
 		}
+		rewardReport = report
 	}
 
 	// do epoch thing at the end, because it will update active validators
@@ -696,14 +755,27 @@ func (c *Congress) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 		}
 	}
 
+	govTxs, evidenceTxs := splitSystemTxs(systemTxs)
+
+	//handle double sign evidences
+	for _, tx := range evidenceTxs {
+		receipt, err := c.replayEvidence(chain, header, state, len(*txs), tx)
+		if err != nil {
+			return err
+		}
+		*txs = append(*txs, tx)
+		*receipts = append(*receipts, receipt)
+	}
+
 	//handle system governance Proposal
+	var executions []*ProposalInfo
 	if chain.Config().IsRedCoast(header.Number) {
 		proposalCount, err := c.getPassedProposalCount(chain, header, state)
 		if err != nil {
 			return err
 		}
-		if proposalCount != uint32(len(systemTxs)) {
-			return errInvalidSysGovCount
+		if proposalCount != uint32(len(govTxs)) {
+			return fmt.Errorf("%w: have %d, want %d", errInvalidSysGovCount, len(govTxs), proposalCount)
 		}
 		// Due to the logics of the finish operation of contract `governance`, when finishing a proposal which
 		// is not the last passed proposal, it will change the sequence. So in here we must first executes all
@@ -715,13 +787,14 @@ func (c *Congress) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 				return err
 			}
 			// execute the system governance Proposal
-			tx := systemTxs[int(i)]
-			receipt, err := c.replayProposal(chain, header, state, prop, len(*txs), tx)
+			tx := govTxs[int(i)]
+			receipt, usedGas, err := c.replayProposal(chain, header, state, prop, len(*txs), tx)
 			if err != nil {
-				return err
+				return fmt.Errorf("system governance tx %d (%s) of proposal %v: %w", i, tx.Hash().Hex(), prop.Id, err)
 			}
 			*txs = append(*txs, tx)
 			*receipts = append(*receipts, receipt)
+			executions = append(executions, newProposalExecution(prop, header, receipt, usedGas))
 			// set
 			pIds = append(pIds, prop.Id)
 		}
@@ -738,6 +811,12 @@ func (c *Congress) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	header.UncleHash = types.CalcUncleHash(nil)
 
+	c.addBlockData(header, &blockData{
+		activity:   activity,
+		reward:     rewardReport,
+		denials:    c.blockDenials(header.Number.Uint64(), *txs),
+		executions: executions,
+	})
 	return nil
 }
 
@@ -765,56 +844,18 @@ func (c *Congress) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 	}
 
 	// punish validator if necessary
+	var activity *blockActivity
 	if header.Difficulty.Cmp(diffInTurn) != 0 {
-		if err := c.tryPunishValidator(chain, header, state); err != nil {
+		if activity, err = c.tryPunishValidator(chain, header, state); err != nil {
 			panic(err)
 		}
 	}
 
 	// deposit block reward if any tx exists.
-	var addr [] common.Address
-	var gass [] uint64
-	//addr = new[len(txs)]
-	
-	
+	var rewardReport *BlockRewardReport
 	if len(txs) > 0 {
-				
-		var totalGasSum uint64
-
-		for i := 0; i < len(txs); i++ {
-			TO := txs[i]
-
-			if TO.To() == nil {
-				addr = append(addr, common.HexToAddress("0x0000000000000000000000000000000000000000"))
-			} else {
-				addr = append(addr, *TO.To())
-			}
-
-			gasFee := TO.Gas() * TO.GasPrice().Uint64()
-			gass = append(gass, gasFee)
-
-			// Accumulate gasFee to totalGasSum
-			totalGasSum += gasFee
-		}
-		
-	    fee := state.GetBalance(consensus.FeeRecoder)
-
-		feeUint64 := fee.Uint64()
-
-		if totalGasSum > feeUint64 {
-		
-			percentDifference := float64(totalGasSum-feeUint64) / float64(totalGasSum) * 100
-
-			for i := 0; i < len(gass); i++ {
-				decreaseAmount := uint64(float64(gass[i]) * (percentDifference / 100.0))
-				gass[i] -= decreaseAmount
-			}
-		}
-	
-		if err := c.trySendBlockReward(chain, header, state,addr,gass); err != nil {
-			//panic(err)
-			log.Info(err.Error())
-
+		if rewardReport, err = c.distributeBlockReward(chain, header, state, txs, receipts); err != nil {
+			return nil, nil, err
 		}
 	}
 
@@ -826,12 +867,21 @@ func (c *Congress) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 		}
 	}
 
+	//submit double sign evidences
+	if c.signTxFn != nil && chain.Config().IsDoubleSign(header.Number) {
+		txs, receipts, err = c.submitEvidences(chain, header, state, txs, receipts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	//handle system governance Proposal
 	//
 	// Note:
 	// Even if the miner is not `running`, it's still working,
 	// the 'miner.worker' will try to FinalizeAndAssemble a block,
 	// in this case, the signTxFn is not set. A `non-miner node` can't execute system governance proposal.
+	var executions []*ProposalInfo
 	if c.signTxFn != nil && chain.Config().IsRedCoast(header.Number) {
 		proposalCount, err := c.getPassedProposalCount(chain, header, state)
 		if err != nil {
@@ -848,12 +898,13 @@ func (c *Congress) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 				return nil, nil, err
 			}
 			// execute the system governance Proposal
-			tx, receipt, err := c.executeProposal(chain, header, state, prop, len(txs))
+			tx, receipt, usedGas, err := c.executeProposal(chain, header, state, prop, len(txs))
 			if err != nil {
 				return nil, nil, err
 			}
 			txs = append(txs, tx)
 			receipts = append(receipts, receipt)
+			executions = append(executions, newProposalExecution(prop, header, receipt, usedGas))
 			// set
 			pIds = append(pIds, prop.Id)
 		}
@@ -871,7 +922,15 @@ func (c *Congress) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 	header.UncleHash = types.CalcUncleHash(nil)
 
 	// Assemble and return the final block for sealing
-	return types.NewBlock(header, txs, nil, receipts, new(trie.Trie)), receipts, nil
+	b = types.NewBlock(header, txs, nil, receipts, new(trie.Trie))
+
+	c.addBlockData(b.Header(), &blockData{
+		activity:   activity,
+		reward:     rewardReport,
+		denials:    c.blockDenials(header.Number.Uint64(), txs),
+		executions: executions,
+	})
+	return b, receipts, nil
 }
 
 func (c *Congress) trySendBlockReward(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, addr [] common.Address,gass [] uint64) error {
@@ -910,29 +969,23 @@ func (c *Congress) trySendBlockReward(chain consensus.ChainHeaderReader, header
 	return nil
 }
 
-func (c *Congress) tryPunishValidator(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) error {
+func (c *Congress) tryPunishValidator(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) (*blockActivity, error) {
 	number := header.Number.Uint64()
 	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
 	if err != nil {
-		return err
-	}
-	validators := snap.validators()
-	outTurnValidator := validators[number%uint64(len(validators))]
-	// check sigend recently or not
-	signedRecently := false
-	for _, recent := range snap.Recents {
-		if recent == outTurnValidator {
-			signedRecently = true
-			break
-		}
+		return nil, err
 	}
-	if !signedRecently {
+	outTurnValidator, punish := missedValidator(snap, number)
+	activity := &blockActivity{Missed: outTurnValidator}
+	if punish {
+		events := c.punishEvents(header, state, outTurnValidator)
 		if err := c.punishValidator(outTurnValidator, chain, header, state); err != nil {
-			return err
+			return nil, err
 		}
+		activity.Punished = c.punishEvents(header, state, outTurnValidator) > events
 	}
 
-	return nil
+	return activity, nil
 }
 
 func (c *Congress) doSomethingAtEpoch(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) ([]common.Address, error) {
@@ -961,7 +1014,7 @@ func (c *Congress) initializeSystemContracts(chain consensus.ChainHeaderReader,
 	}
 
 	genesisValidators := snap.validators()
-	if len(genesisValidators) == 0 || len(genesisValidators) > maxValidators {
+	if len(genesisValidators) == 0 || len(genesisValidators) > validatorsLimit(c.config, header.Number.Uint64()) {
 		return errInvalidValidatorsLength
 	}
 
@@ -1034,6 +1087,13 @@ func (c *Congress) getTopValidators(chain consensus.ChainHeaderReader, header *t
 	if !ok {
 		return []common.Address{}, errors.New("Invalid validators format")
 	}
+	// Keep the top validators only if the contract returns more than allowed, the
+	// contract ranks them by stake so the truncation happens before sorting
+	if c.config.IsValidatorsLimit(header.Number) {
+		if limit := validatorsLimit(c.config, header.Number.Uint64()); len(validators) > limit {
+			validators = validators[:limit]
+		}
+	}
 	sort.Sort(validatorsAscending(validators))
 	return validators, err
 }
@@ -1142,15 +1202,9 @@ func (c *Congress) Seal(chain consensus.ChainHeaderReader, block *types.Block, r
   for seen, recent := range snap.Recents {
   	if recent == val {
   		// Determine the limit based on the number of validators
-  		var limit uint64
-		limit = uint64(len(snap.Validators)/2 + 1)
-  		if len(snap.Validators) > 21 || len(snap.Validators) == 1  {
-  			limit = uint64(len(snap.Validators)/2 + 1)
-  		} else { //if number > 9299500 {
-  			limit = 2
-  		}
+  		limit := recentsLimit(c.config, number, len(snap.Validators))
   		// Validator is among recents, only wait if the current block doesn't shift it out
-  		if number < limit || seen > number-limit {
+  		if number < limit || signedRecently(c.config, number, seen, limit) {
   			log.Info("Signed recently, must wait for others")
   			return nil
   		}
@@ -1215,8 +1269,24 @@ func (c *Congress) SealHash(header *types.Header) common.Hash {
 	return SealHash(header)
 }
 
-// Close implements consensus.Engine. It's a noop for congress as there are no background threads.
+// Close implements consensus.Engine, terminating the missed blocks monitor and the
+// finality gadget if they're running.
 func (c *Congress) Close() error {
+	if c.monitor != nil {
+		c.monitor.stop()
+	}
+	if c.finality != nil {
+		c.finality.stop()
+	}
+	if c.proposalWatcher != nil {
+		c.proposalWatcher.stop()
+	}
+	if c.indexer != nil {
+		c.indexer.stop()
+	}
+	if c.denialWriter != nil {
+		c.denialWriter.stop()
+	}
 	return nil
 }
 
@@ -1277,12 +1347,27 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 
 func (c *Congress) PreHandle(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) error {
 	if c.chainConfig.RedCoastBlock != nil && c.chainConfig.RedCoastBlock.Cmp(header.Number) == 0 {
-		return systemcontract.ApplySystemContractUpgrade(systemcontract.SysContractV1, state, header, newChainContext(chain, c), c.chainConfig)
+		if err := systemcontract.ApplySystemContractUpgrade(systemcontract.SysContractV1, state, header, newChainContext(chain, c), c.chainConfig); err != nil {
+			return err
+		}
 	}
 	if c.chainConfig.SophonBlock != nil && c.chainConfig.SophonBlock.Cmp(header.Number) == 0 {
-		return systemcontract.ApplySystemContractUpgrade(systemcontract.SysContractV2, state, header, newChainContext(chain, c), c.chainConfig)
+		if err := systemcontract.ApplySystemContractUpgrade(systemcontract.SysContractV2, state, header, newChainContext(chain, c), c.chainConfig); err != nil {
+			return err
+		}
 	}
-	return nil
+	if c.chainConfig.DoubleSignBlock != nil && c.chainConfig.DoubleSignBlock.Cmp(header.Number) == 0 {
+		if err := systemcontract.ApplySystemContractUpgrade(systemcontract.SysContractDoubleSign, state, header, newChainContext(chain, c), c.chainConfig); err != nil {
+			return err
+		}
+	}
+	if c.chainConfig.EventRulesBlock != nil && c.chainConfig.EventRulesBlock.Cmp(header.Number) == 0 {
+		if err := systemcontract.ApplySystemContractUpgrade(systemcontract.SysContractEventRules, state, header, newChainContext(chain, c), c.chainConfig); err != nil {
+			return err
+		}
+	}
+	// The upgrades scheduled in the chain config go after the built-in ones
+	return systemcontract.ApplyManifestUpgrades(state, header, newChainContext(chain, c), c.chainConfig)
 }
 
 // IsSysTransaction checks whether a specific transaction is a system transaction.
@@ -1295,6 +1380,9 @@ func (c *Congress) IsSysTransaction(sender common.Address, tx *types.Transaction
 	if sender == header.Coinbase && *to == systemcontract.SysGovToAddr && tx.GasPrice().Sign() == 0 {
 		return true, nil
 	}
+	if c.chainConfig.IsDoubleSign(header.Number) && sender == header.Coinbase && *to == systemcontract.DoubleSignEvidenceToAddr && tx.GasPrice().Sign() == 0 {
+		return true, nil
+	}
 	// Make sure the miner can NOT call the system contract through a normal transaction.
 	if sender == header.Coinbase && *to == systemcontract.SysGovContractAddr {
 		return true, nil
@@ -1330,11 +1418,13 @@ func (c *Congress) ValidateTx(sender common.Address, tx *types.Transaction, head
 		}
 		if d, exist := m[sender]; exist && (d != DirectionTo) {
 			log.Trace("Hit blacklist", "tx", tx.Hash().String(), "addr", sender.String(), "direction", d)
+			c.recordDenial(&DenialRecord{Number: header.Number.Uint64(), Tx: tx.Hash(), Address: sender, Direction: d, Source: DenialValidateTx})
 			return types.ErrAddressDenied
 		}
 		if to := tx.To(); to != nil {
 			if d, exist := m[*to]; exist && (d != DirectionFrom) {
 				log.Trace("Hit blacklist", "tx", tx.Hash().String(), "addr", to.String(), "direction", d)
+				c.recordDenial(&DenialRecord{Number: header.Number.Uint64(), Tx: tx.Hash(), Address: *to, Direction: d, Source: DenialValidateTx})
 				return types.ErrAddressDenied
 			}
 		}
@@ -1429,6 +1519,10 @@ func (c *Congress) CreateEvmExtraValidator(header *types.Header, parentState *st
 		return &blacklistValidator{
 			blacks: blacks,
 			rules:  rules,
+			audit: func(r *DenialRecord) {
+				r.Number, r.Tx = header.Number.Uint64(), parentState.TxHash()
+				c.auditDenial(r.Number, r)
+			},
 		}
 	}
 	return nil
@@ -1454,13 +1548,14 @@ func (c *Congress) getEventCheckRules(header *types.Header, parentState *state.S
 	lastUpdated := lastRulesUpdatedNumber(parentState)
 	if num >= 2 && num > lastUpdated+1 {
 		parent := c.chain.GetHeader(header.ParentHash, num-1)
-		if parent != nil {
+		// the rules of the parent are read the legacy way at the block after the EventRules fork block
+		if parent != nil && c.readsRulesV2(parent.Number) == c.readsRulesV2(header.Number) {
 			if v, ok := c.eventCheckRules.Get(parent.ParentHash); ok {
 				m := v.(map[common.Hash]*EventCheckRule)
 				c.eventCheckRules.Add(header.ParentHash, m)
 				return m, nil
 			}
-		} else {
+		} else if parent == nil {
 			log.Error("Unexpected error when getEventCheckRules, can not get parent from chain", "number", num, "blockHash", header.Hash(), "parentHash", header.ParentHash)
 		}
 	}
@@ -1468,16 +1563,29 @@ func (c *Congress) getEventCheckRules(header *types.Header, parentState *state.S
 	// can't get blacklist from cache, try to call the contract
 	alABI := c.abi[systemcontract.AddressListContractName]
 	method := "getRuleByIndex"
-	get := func(i uint32) (common.Hash, int, common.AddressCheckType, error) {
-		ret, err := c.commonCallContract(header, parentState, alABI, systemcontract.AddressListContractAddr, method, 3, i)
+	eventRules := c.readsRulesV2(header.Number)
+	if eventRules {
+		method = "getRuleV2ByIndex"
+	}
+	get := func(i uint32) (common.Hash, int, common.AddressCheckType, EventCheckLocation, common.Address, error) {
+		expect := 3
+		if eventRules {
+			expect = 5
+		}
+		ret, err := c.commonCallContract(header, parentState, alABI, systemcontract.AddressListContractAddr, method, expect, i)
 		if err != nil {
-			return common.Hash{}, 0, common.CheckNone, err
+			return common.Hash{}, 0, common.CheckNone, LocationTopic, common.Address{}, err
 		}
 		sig := ret[0].([32]byte)
 		idx := ret[1].(*big.Int).Uint64()
 		ct := ret[2].(uint8)
+		if !eventRules {
+			return sig, int(idx), common.AddressCheckType(ct), LocationTopic, common.Address{}, nil
+		}
+		loc := ret[3].(uint8)
+		contract := ret[4].(common.Address)
 
-		return sig, int(idx), common.AddressCheckType(ct), nil
+		return sig, int(idx), common.AddressCheckType(ct), EventCheckLocation(loc), contract, nil
 	}
 
 	cnt, err := c.getEventCheckRulesLen(header, parentState)
@@ -1487,20 +1595,39 @@ func (c *Congress) getEventCheckRules(header *types.Header, parentState *state.S
 	}
 	rules := make(map[common.Hash]*EventCheckRule)
 	for i := 0; i < cnt; i++ {
-		sig, idx, ct, err := get(uint32(i))
+		sig, idx, ct, loc, contract, err := get(uint32(i))
 		if err != nil {
-			log.Error("getRuleByIndex failed", "index", i, "number", num, "blockHash", header.Hash(), "err", err)
+			log.Error(fmt.Sprintf("%s failed", method), "index", i, "number", num, "blockHash", header.Hash(), "err", err)
 			return nil, err
 		}
 		rule, exist := rules[sig]
 		if !exist {
-			rule = &EventCheckRule{
-				EventSig: sig,
-				Checks:   make(map[int]common.AddressCheckType),
-			}
+			rule = newEventCheckRule(sig)
 			rules[sig] = rule
 		}
-		rule.Checks[idx] = ct
+		// the zero contract is the wildcard, checking the events of every contract
+		if contract != (common.Address{}) {
+			if rule.Contracts == nil {
+				rule.Contracts = make(map[common.Address]*EventCheckRule)
+			}
+			scoped, exist := rule.Contracts[contract]
+			if !exist {
+				scoped = newEventCheckRule(sig)
+				rule.Contracts[contract] = scoped
+			}
+			rule = scoped
+		}
+		switch loc {
+		case LocationTopic:
+			rule.Checks[idx] = ct
+		case LocationData:
+			if rule.DataChecks == nil {
+				rule.DataChecks = make(map[int]common.AddressCheckType)
+			}
+			rule.DataChecks[idx] = ct
+		default:
+			log.Warn("event check rule, unsupported location", "index", i, "sig", sig.String(), "location", loc)
+		}
 	}
 
 	c.eventCheckRules.Add(header.ParentHash, rules)
@@ -1508,7 +1635,11 @@ func (c *Congress) getEventCheckRules(header *types.Header, parentState *state.S
 }
 
 func (c *Congress) getEventCheckRulesLen(header *types.Header, parentState *state.StateDB) (int, error) {
-	ret, err := c.commonCallContract(header, parentState, c.abi[systemcontract.AddressListContractName], systemcontract.AddressListContractAddr, "rulesLen", 1)
+	method := "rulesLen"
+	if c.readsRulesV2(header.Number) {
+		method = "rulesV2Len"
+	}
+	ret, err := c.commonCallContract(header, parentState, c.abi[systemcontract.AddressListContractName], systemcontract.AddressListContractAddr, method, 1)
 	if err != nil {
 		return 0, err
 	}
@@ -1575,6 +1706,13 @@ func lastBlacklistUpdatedNumber(state consensus.StateReader) uint64 {
 	return value.Big().Uint64()
 }
 
+// readsRulesV2 returns whether the event check rules of a block are read with the
+// V2 getters of the AddressList contract. They are deployed at the start of the
+// EventRules fork block, so the parent state only has them from the next block on.
+func (c *Congress) readsRulesV2(number *big.Int) bool {
+	return c.chainConfig.EventRulesBlock != nil && c.chainConfig.EventRulesBlock.Cmp(number) < 0
+}
+
 func lastRulesUpdatedNumber(state consensus.StateReader) uint64 {
 	value := state.GetState(systemcontract.AddressListContractAddr, systemcontract.RulesLastUpdatedNumberPosition)
 	return value.Big().Uint64()
@@ -110,17 +110,17 @@ func (c *Congress) finishProposalById(chain consensus.ChainHeaderReader, header
 	return nil
 }
 
-func (c *Congress) executeProposal(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, prop *Proposal, totalTxIndex int) (*types.Transaction, *types.Receipt, error) {
+func (c *Congress) executeProposal(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, prop *Proposal, totalTxIndex int) (*types.Transaction, *types.Receipt, uint64, error) {
 	// Even if the miner is not `running`, it's still working,
 	// the 'miner.worker' will try to FinalizeAndAssemble a block,
 	// in this case, the signTxFn is not set. A `non-miner node` can't execute system governance proposal.
 	if c.signTxFn == nil {
-		return nil, nil, errors.New("signTxFn not set")
+		return nil, nil, 0, errors.New("signTxFn not set")
 	}
 
 	propRLP, err := rlp.EncodeToBytes(prop)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
 	//make system governance transaction
 	nonce := state.GetNonce(c.validator)
@@ -132,46 +132,96 @@ func (c *Congress) executeProposal(chain consensus.ChainHeaderReader, header *ty
 	tx := types.NewTransaction(nonce, systemcontract.SysGovToAddr, amout, header.GasLimit, new(big.Int), propRLP)
 	tx, err = c.signTxFn(accounts.Account{Address: c.validator}, tx, chain.Config().ChainID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
 	//add nonce for validator
 	state.SetNonce(c.validator, nonce+1)
-	receipt := c.executeProposalMsg(chain, header, state, prop, totalTxIndex, tx.Hash(), common.Hash{})
+	receipt, usedGas := c.executeProposalMsg(chain, header, state, prop, totalTxIndex, tx.Hash(), common.Hash{})
 
-	return tx, receipt, nil
+	return tx, receipt, usedGas, nil
 }
 
-func (c *Congress) replayProposal(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, prop *Proposal, totalTxIndex int, tx *types.Transaction) (*types.Receipt, error) {
+func (c *Congress) replayProposal(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, prop *Proposal, totalTxIndex int, tx *types.Transaction) (*types.Receipt, uint64, error) {
 	sender, err := types.Sender(c.signer, tx)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if sender != header.Coinbase {
-		return nil, errors.New("invalid sender for system governance transaction")
-	}
-	propRLP, err := rlp.EncodeToBytes(prop)
-	if err != nil {
-		return nil, err
+		return nil, 0, fmt.Errorf("%w: have %s, want %s", errInvalidSysGovSender, sender.Hex(), header.Coinbase.Hex())
 	}
-	if !bytes.Equal(propRLP, tx.Data()) {
-		return nil, fmt.Errorf("data missmatch, proposalID: %s, rlp: %s, txHash:%s, txData:%s", prop.Id.String(), hexutil.Encode(propRLP), tx.Hash().String(), hexutil.Encode(tx.Data()))
+	if err := verifyProposalTx(prop, tx); err != nil {
+		return nil, 0, err
 	}
 	//make system governance transaction
 	nonce := state.GetNonce(sender)
 	//add nonce for validator
 	state.SetNonce(sender, nonce+1)
-	receipt := c.executeProposalMsg(chain, header, state, prop, totalTxIndex, tx.Hash(), header.Hash())
+	receipt, usedGas := c.executeProposalMsg(chain, header, state, prop, totalTxIndex, tx.Hash(), header.Hash())
+
+	return receipt, usedGas, nil
+}
+
+// proposalMismatchError is returned if a field of the proposal carried by a system
+// governance transaction differs from the passed proposal of the contract.
+type proposalMismatchError struct {
+	Field string
+	Have  string
+	Want  string
+}
+
+func (e *proposalMismatchError) Error() string {
+	return fmt.Sprintf("%v: %s have %s, want %s", errSysGovProposalMismatch, e.Field, e.Have, e.Want)
+}
 
-	return receipt, nil
+func (e *proposalMismatchError) Unwrap() error { return errSysGovProposalMismatch }
+
+// verifyProposalTx checks that a system governance transaction carries exactly the
+// given passed proposal, reporting the first mismatching field otherwise.
+func verifyProposalTx(prop *Proposal, tx *types.Transaction) error {
+	if to := tx.To(); to == nil || *to != systemcontract.SysGovToAddr {
+		return errInvalidSysGovTarget
+	}
+	txProp := new(Proposal)
+	if err := rlp.DecodeBytes(tx.Data(), txProp); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidSysGovData, err)
+	}
+	switch {
+	case txProp.Id.Cmp(prop.Id) != 0:
+		return &proposalMismatchError{Field: "id", Have: txProp.Id.String(), Want: prop.Id.String()}
+	case txProp.Action.Cmp(prop.Action) != 0:
+		return &proposalMismatchError{Field: "action", Have: txProp.Action.String(), Want: prop.Action.String()}
+	case txProp.From != prop.From:
+		return &proposalMismatchError{Field: "from", Have: txProp.From.Hex(), Want: prop.From.Hex()}
+	case txProp.To != prop.To:
+		return &proposalMismatchError{Field: "to", Have: txProp.To.Hex(), Want: prop.To.Hex()}
+	case txProp.Value.Cmp(prop.Value) != 0:
+		return &proposalMismatchError{Field: "value", Have: txProp.Value.String(), Want: prop.Value.String()}
+	case !bytes.Equal(txProp.Data, prop.Data):
+		return &proposalMismatchError{Field: "data", Have: hexutil.Encode(txProp.Data), Want: hexutil.Encode(prop.Data)}
+	}
+	// Same fields, the transaction must still carry the canonical encoding
+	propRLP, err := rlp.EncodeToBytes(prop)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(propRLP, tx.Data()) {
+		return fmt.Errorf("%w: have %s, want %s", errInvalidSysGovData, hexutil.Encode(tx.Data()), hexutil.Encode(propRLP))
+	}
+	return nil
 }
 
-func (c *Congress) executeProposalMsg(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, prop *Proposal, totalTxIndex int, txHash, bHash common.Hash) *types.Receipt {
-	var receipt *types.Receipt
+// executeProposalMsg executes a passed proposal, returning the receipt of its system
+// transaction and the gas used by the call, which isn't charged.
+func (c *Congress) executeProposalMsg(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, prop *Proposal, totalTxIndex int, txHash, bHash common.Hash) (*types.Receipt, uint64) {
+	var (
+		receipt *types.Receipt
+		usedGas uint64
+	)
 	action := prop.Action.Uint64()
 	switch action {
 	case 0:
 		// evm action.
-		receipt = c.executeEvmCallProposal(chain, header, state, prop, totalTxIndex, txHash, bHash)
+		receipt, usedGas = c.executeEvmCallProposal(chain, header, state, prop, totalTxIndex, txHash, bHash)
 	case 1:
 		// delete code action
 		ok := state.Erase(prop.To)
@@ -187,17 +237,17 @@ func (c *Congress) executeProposalMsg(chain consensus.ChainHeaderReader, header
 	receipt.BlockNumber = header.Number
 	receipt.TransactionIndex = uint(state.TxIndex())
 
-	return receipt
+	return receipt, usedGas
 }
 
 // the returned value should not nil.
-func (c *Congress) executeEvmCallProposal(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, prop *Proposal, totalTxIndex int, txHash, bHash common.Hash) *types.Receipt {
+func (c *Congress) executeEvmCallProposal(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, prop *Proposal, totalTxIndex int, txHash, bHash common.Hash) (*types.Receipt, uint64) {
 	// actually run the governance message
 	msg := vmcaller.NewLegacyMessage(prop.From, &prop.To, 0, prop.Value, header.GasLimit, new(big.Int), prop.Data, false)
 	state.Prepare(txHash, totalTxIndex)
-	_, err := vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, c), c.chainConfig)
+	_, usedGas, err := vmcaller.ExecuteMsgWithGas(msg, state, header, newChainContext(chain, c), c.chainConfig)
 
-	// governance message will not actually consumes gas
+	// governance message will not actually consumes gas, the gas used is informational only
 	receipt := types.NewReceipt([]byte{}, err != nil, header.GasUsed)
 	// Set the receipt logs and create a bloom for filtering
 	receipt.Logs = state.GetLogs(txHash, bHash)
@@ -205,7 +255,7 @@ func (c *Congress) executeEvmCallProposal(chain consensus.ChainHeaderReader, hea
 
 	log.Info("executeProposalMsg", "action", "evmCall", "id", prop.Id.String(), "from", prop.From, "to", prop.To, "value", prop.Value.String(), "data", hexutil.Encode(prop.Data), "txHash", txHash.String(), "err", err)
 
-	return receipt
+	return receipt, usedGas
 }
 
 // Methods for debug trace
@@ -213,6 +263,9 @@ func (c *Congress) executeEvmCallProposal(chain consensus.ChainHeaderReader, hea
 // ApplySysTx applies a system-transaction using a given evm,
 // the main purpose of this method is for tracing a system-transaction.
 func (c *Congress) ApplySysTx(evm *vm.EVM, state *state.StateDB, txIndex int, sender common.Address, tx *types.Transaction) (ret []byte, vmerr error, err error) {
+	if *tx.To() == systemcontract.DoubleSignEvidenceToAddr {
+		return c.applyEvidenceTx(evm, state, txIndex, sender, tx)
+	}
 	var prop = &Proposal{}
 	if err = rlp.DecodeBytes(tx.Data(), prop); err != nil {
 		return
@@ -0,0 +1,358 @@
+package congress
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	inmemoryDenialAudits = 128         // Number of block numbers under processing to keep the denials of in memory
+	inmemoryDeniedTxs    = 4096        // Number of transactions denied by the pool to remember as recorded
+	denialQueueSize      = 1024        // Number of pending writes of the denial writer
+	denialFlushInterval  = time.Second // Time after which the denial records are flushed to disk
+	maxDenialQueryRange  = 10000       // Maximum number of blocks to query the denial audit log over
+)
+
+var (
+	// errDenialQueryRange is returned if the denial audit log is queried over too many blocks.
+	errDenialQueryRange = errors.New("denial query range too large")
+
+	// errBlacklistInactive is returned if the blacklist is queried before it's enforced.
+	errBlacklistInactive = errors.New("blacklist not enforced at block")
+)
+
+var denialPrefix = []byte("congress-denial-") // denialPrefix + num (uint64 big endian) + block hash + tx hash + address + source -> denial record
+
+// DenialSource is the check which denied a transaction.
+type DenialSource string
+
+const (
+	DenialValidateTx DenialSource = "validateTx" // Sender or recipient blacklisted when validating the transaction
+	DenialEVM        DenialSource = "evm"        // Caller or callee blacklisted within the EVM
+	DenialLog        DenialSource = "log"        // Address of an event blacklisted by an event check rule
+)
+
+// DenialRecord is an entry of the audit log of the transactions denied by the
+// blacklist.
+type DenialRecord struct {
+	Number    uint64             `json:"number"` // Block the transaction was validated for or executed in
+	Block     common.Hash        `json:"block"`  // Hash of the block the transaction was executed in, zero for the pool denials
+	Tx        common.Hash        `json:"tx"`
+	Address   common.Address     `json:"address"`
+	Direction blacklistDirection `json:"direction"`
+	Source    DenialSource       `json:"source"`
+	EventSig  *common.Hash       `json:"eventSig,omitempty"` // Signature of the denied event, only for the log source
+	Time      uint64             `json:"time"`               // Unix time the denial was recorded at
+}
+
+func denialKey(r *DenialRecord) []byte {
+	key := make([]byte, 0, len(denialPrefix)+8+2*common.HashLength+common.AddressLength+len(r.Source))
+	key = append(key, denialPrefix...)
+	key = append(key, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(key[len(denialPrefix):], r.Number)
+	key = append(key, r.Block[:]...)
+	key = append(key, r.Tx[:]...)
+	key = append(key, r.Address[:]...)
+	return append(key, r.Source...)
+}
+
+func (r *DenialRecord) store(db ethdb.KeyValueWriter) error {
+	blob, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return db.Put(denialKey(r), blob)
+}
+
+// DenialAuditConfig are the configuration parameters of the denial audit log.
+type DenialAuditConfig struct {
+	Retain uint64 // Number of recent blocks to keep the denial records of, 0 to keep all
+}
+
+// denialAudit collects the denials raised while executing the transactions of a
+// block number, deduplicated by record key, until a block is processed.
+type denialAudit struct {
+	records map[string]*DenialRecord
+	lock    sync.Mutex
+}
+
+func (a *denialAudit) add(r *DenialRecord) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.records[string(denialKey(r))] = r
+}
+
+// filter returns the records of the given transactions.
+func (a *denialAudit) filter(txs []*types.Transaction) []*DenialRecord {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	included := make(map[common.Hash]bool, len(txs))
+	for _, tx := range txs {
+		included[tx.Hash()] = true
+	}
+	var records []*DenialRecord
+	for _, r := range a.records {
+		if included[r.Tx] {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// denialWriter persists the denial records in batches off the caller's goroutine,
+// pruning the ones falling out of the retention window.
+type denialWriter struct {
+	cfg DenialAuditConfig
+	db  ethdb.Database
+
+	records chan []*DenialRecord
+	seen    *lru.Cache // Transactions already denied by the pool, recorded once
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+func newDenialWriter(cfg DenialAuditConfig, db ethdb.Database) *denialWriter {
+	seen, _ := lru.New(inmemoryDeniedTxs)
+	return &denialWriter{
+		cfg:     cfg,
+		db:      db,
+		records: make(chan []*DenialRecord, denialQueueSize),
+		seen:    seen,
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// StartDenialAudit starts persisting the denials raised by the blacklist.
+func (c *Congress) StartDenialAudit(cfg DenialAuditConfig) {
+	c.denialWriter = newDenialWriter(cfg, c.db)
+	go c.denialWriter.loop()
+}
+
+func (w *denialWriter) loop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(denialFlushInterval)
+	defer ticker.Stop()
+
+	var (
+		batch = w.db.NewBatch()
+		head  uint64 // Highest block number of the records written
+		tail  uint64 // Lowest block number which may still hold records
+	)
+	flush := func() {
+		if batch.ValueSize() == 0 {
+			return
+		}
+		if err := batch.Write(); err != nil {
+			log.Warn("Failed to store denial records", "err", err)
+		}
+		batch.Reset()
+
+		// Prune once written, so the records of the batch out of the window go too
+		if w.cfg.Retain > 0 && head >= w.cfg.Retain {
+			tail = w.prune(batch, tail, head-w.cfg.Retain+1)
+			if err := batch.Write(); err != nil {
+				log.Warn("Failed to prune denial records", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	store := func(records []*DenialRecord) {
+		for _, r := range records {
+			if err := r.store(batch); err != nil {
+				log.Warn("Failed to store denial record", "tx", r.Tx, "err", err)
+			}
+			if r.Number > head {
+				head = r.Number
+			}
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			flush()
+		}
+	}
+	for {
+		select {
+		case records := <-w.records:
+			store(records)
+		case <-ticker.C:
+			flush()
+		case <-w.quit:
+			for {
+				select {
+				case records := <-w.records:
+					store(records)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// prune deletes the records of the blocks within [from, to), returning the lowest
+// block number which may still hold records.
+func (w *denialWriter) prune(batch ethdb.Batch, from, to uint64) uint64 {
+	if from >= to {
+		return from
+	}
+	start := make([]byte, 8)
+	binary.BigEndian.PutUint64(start, from)
+
+	it := w.db.NewIterator(denialPrefix, start)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) < len(denialPrefix)+8 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(denialPrefix):]) >= to {
+			break
+		}
+		batch.Delete(common.CopyBytes(key))
+	}
+	return to
+}
+
+// write queues the records of a block, waiting for room in the queue.
+func (w *denialWriter) write(records []*DenialRecord) {
+	select {
+	case w.records <- records:
+	case <-w.quit:
+	}
+}
+
+func (w *denialWriter) stop() {
+	close(w.quit)
+	<-w.done
+}
+
+// recordDenial persists a denial raised by the transaction pool. Each transaction
+// is recorded once, and the record is dropped rather than blocking the pool if
+// the writer falls behind.
+func (c *Congress) recordDenial(r *DenialRecord) {
+	w := c.denialWriter
+	if w == nil {
+		return
+	}
+	if ok, _ := w.seen.ContainsOrAdd(r.Tx, nil); ok {
+		return
+	}
+	r.Time = uint64(time.Now().Unix())
+	select {
+	case w.records <- []*DenialRecord{r}:
+	default:
+		log.Debug("Denial record dropped", "tx", r.Tx)
+	}
+}
+
+// auditDenial keeps a denial raised while processing a block at the given number
+// until a block is processed. The denials raised outside of a transaction, e.g. by
+// a call, aren't audited.
+func (c *Congress) auditDenial(number uint64, r *DenialRecord) {
+	if c.denialWriter == nil || r.Tx == (common.Hash{}) {
+		return
+	}
+	r.Time = uint64(time.Now().Unix())
+
+	c.auditLock.Lock()
+	audit, ok := c.denialAudits.Get(number)
+	if !ok {
+		audit = &denialAudit{records: make(map[string]*DenialRecord)}
+		c.denialAudits.Add(number, audit)
+	}
+	c.auditLock.Unlock()
+
+	audit.(*denialAudit).add(r)
+}
+
+// blockDenials returns the denials raised by the given transactions of a block.
+// The audit is kept, as other blocks may still be processed or assembled at the
+// same number.
+func (c *Congress) blockDenials(number uint64, txs []*types.Transaction) []*DenialRecord {
+	audit, ok := c.denialAudits.Get(number)
+	if !ok {
+		return nil
+	}
+	return audit.(*denialAudit).filter(txs)
+}
+
+// commitDenials persists the denials raised by a canonical block. The records are
+// copied, as the audit of the number is shared by all the blocks processed at it.
+func (c *Congress) commitDenials(header *types.Header, records []*DenialRecord) {
+	if c.denialWriter == nil || len(records) == 0 {
+		return
+	}
+	committed := make([]*DenialRecord, 0, len(records))
+	for _, r := range records {
+		record := *r
+		record.Block = header.Hash()
+		committed = append(committed, &record)
+	}
+	c.denialWriter.write(committed)
+}
+
+// denials retrieves the denial records of the given block range, optionally
+// filtered by address. The records of the blocks reorged away are skipped until
+// they get pruned.
+func (c *Congress) denials(from, to uint64, address *common.Address) ([]*DenialRecord, error) {
+	if to < from {
+		return nil, errors.New("invalid block range")
+	}
+	if to-from >= maxDenialQueryRange {
+		return nil, errDenialQueryRange
+	}
+	start := make([]byte, 8)
+	binary.BigEndian.PutUint64(start, from)
+
+	it := c.db.NewIterator(denialPrefix, start)
+	defer it.Release()
+
+	var (
+		records   = []*DenialRecord{}
+		canonical = make(map[uint64]common.Hash) // Canonical hashes of the numbers of the records
+	)
+	for it.Next() {
+		key := it.Key()
+		if len(key) < len(denialPrefix)+8 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(denialPrefix):]) > to {
+			break
+		}
+		r := new(DenialRecord)
+		if err := json.Unmarshal(it.Value(), r); err != nil {
+			return nil, err
+		}
+		if r.Block != (common.Hash{}) {
+			if _, ok := canonical[r.Number]; !ok {
+				if header := c.chain.GetHeaderByNumber(r.Number); header != nil {
+					canonical[r.Number] = header.Hash()
+				} else {
+					canonical[r.Number] = common.Hash{}
+				}
+			}
+			if canonical[r.Number] != r.Block {
+				continue
+			}
+		}
+		if address == nil || r.Address == *address {
+			records = append(records, r)
+		}
+	}
+	return records, it.Error()
+}
@@ -0,0 +1,126 @@
+package congress
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the denials raised by the EVM validator are kept until a block including
+// their transaction turns canonical, and are queryable afterwards for that block only.
+func TestDenialAudit(t *testing.T) {
+	engine := New(params.AllCongressProtocolChanges, rawdb.NewMemoryDatabase())
+	engine.StartDenialAudit(DenialAuditConfig{})
+
+	blocks, _ := activityTestChain(params.AllCongressProtocolChanges, 7, nil)
+	chain := &canonicalReader{headReader: blocks, canonical: make(map[uint64]common.Hash)}
+	for _, header := range blocks.headers {
+		chain.canonical[header.Number.Uint64()] = header.Hash()
+	}
+	engine.SetChain(chain)
+
+	var (
+		header  = chain.GetHeaderByNumber(7)
+		side    = types.CopyHeader(header)
+		blocked = common.HexToAddress("0xbad")
+		from    = common.HexToAddress("0xcafe")
+		sig     = common.HexToHash("0xddf252ad")
+		tx      = types.NewTransaction(0, blocked, new(big.Int), 21000, new(big.Int), nil)
+		other   = types.NewTransaction(1, blocked, new(big.Int), 21000, new(big.Int), nil)
+	)
+	validator := &blacklistValidator{
+		blacks: map[common.Address]blacklistDirection{blocked: DirectionTo},
+		rules: map[common.Hash]*EventCheckRule{
+			sig: {EventSig: sig, Checks: map[int]common.AddressCheckType{2: common.CheckTo}},
+		},
+		audit: func(r *DenialRecord) {
+			r.Number, r.Tx = header.Number.Uint64(), tx.Hash()
+			engine.auditDenial(r.Number, r)
+		},
+	}
+	if validator.IsAddressDenied(blocked, common.CheckFrom) {
+		t.Fatalf("address denied in the wrong direction")
+	}
+	if !validator.IsAddressDenied(blocked, common.CheckTo) {
+		t.Fatalf("address not denied")
+	}
+	if !validator.IsLogDenied(&types.Log{Topics: []common.Hash{sig, from.Hash(), blocked.Hash()}}) {
+		t.Fatalf("log not denied")
+	}
+	// The same denial raised again by another execution of the block is kept once
+	validator.IsAddressDenied(blocked, common.CheckTo)
+
+	// A denial raised out of a transaction isn't audited
+	engine.auditDenial(7, &DenialRecord{Number: 7, Address: blocked, Source: DenialEVM})
+
+	if records, _ := engine.denials(0, 10, nil); len(records) != 0 {
+		t.Fatalf("denials persisted before finalization: %d", len(records))
+	}
+	// A block without the transaction commits nothing
+	if records := engine.blockDenials(7, []*types.Transaction{other}); len(records) != 0 {
+		t.Fatalf("denials of another transaction: %d", len(records))
+	}
+	// The denials are committed for a side block too, but only the canonical ones are queried
+	side.Time = 1
+	engine.commitDenials(side, engine.blockDenials(7, []*types.Transaction{other, tx}))
+	engine.commitDenials(header, engine.blockDenials(7, []*types.Transaction{other, tx}))
+	engine.Close()
+
+	records, err := engine.denials(7, 7, &blocked)
+	if err != nil {
+		t.Fatalf("failed to query denials: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("denials mismatch: have %d, want 2", len(records))
+	}
+	sources := map[DenialSource]*DenialRecord{}
+	for _, r := range records {
+		if r.Tx != tx.Hash() || r.Direction != DirectionTo || r.Number != 7 || r.Block != header.Hash() {
+			t.Errorf("record mismatch: %+v", r)
+		}
+		sources[r.Source] = r
+	}
+	if sources[DenialEVM] == nil || sources[DenialLog] == nil || *sources[DenialLog].EventSig != sig {
+		t.Errorf("sources mismatch: %v", sources)
+	}
+	if records, _ := engine.denials(8, 10, nil); len(records) != 0 {
+		t.Errorf("denials out of range returned: %d", len(records))
+	}
+	if _, err := engine.denials(0, maxDenialQueryRange, nil); err != errDenialQueryRange {
+		t.Errorf("range error mismatch: have %v, want %v", err, errDenialQueryRange)
+	}
+}
+
+// Tests that the denials of the pool are recorded once per transaction, and that
+// the records of the blocks out of the retention window are pruned.
+func TestDenialRetention(t *testing.T) {
+	engine := New(params.AllCongressProtocolChanges, rawdb.NewMemoryDatabase())
+	engine.StartDenialAudit(DenialAuditConfig{Retain: 3})
+
+	blocked := common.HexToAddress("0xbad")
+	for number := uint64(1); number <= 6; number++ {
+		for i := 0; i < 2; i++ {
+			engine.recordDenial(&DenialRecord{Number: number, Tx: common.BigToHash(new(big.Int).SetUint64(number)), Address: blocked, Source: DenialValidateTx})
+		}
+		// A transaction already recorded isn't recorded again at a later block
+		engine.recordDenial(&DenialRecord{Number: number, Tx: common.BigToHash(common.Big1), Address: blocked, Source: DenialValidateTx})
+	}
+	engine.Close()
+
+	records, err := engine.denials(0, 10, nil)
+	if err != nil {
+		t.Fatalf("failed to query denials: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("denials mismatch: have %d, want 3", len(records))
+	}
+	for i, r := range records {
+		if want := uint64(4 + i); r.Number != want || r.Tx != common.BigToHash(new(big.Int).SetUint64(want)) {
+			t.Errorf("record %d mismatch: have %+v, want block %d", i, r, want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package congress
+
+import (
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/consensus/congress/vmcaller"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// errInvalidEvidence is returned if a double-sign evidence doesn't prove an equivocation.
+	errInvalidEvidence = errors.New("invalid double sign evidence")
+
+	// errExpiredEvidence is returned if a double-sign evidence is too old to be submitted.
+	errExpiredEvidence = errors.New("expired double sign evidence")
+
+	// errPunishedEvidence is returned if the validator of a double-sign evidence was
+	// already punished for the height, by an earlier block or transaction.
+	errPunishedEvidence = errors.New("double sign already punished")
+
+	// errUnknownEvidence is returned if no double-sign evidence is stored for a
+	// validator at a height.
+	errUnknownEvidence = errors.New("unknown double sign evidence")
+)
+
+// DoubleSignEvidence is the proof that a validator sealed two different headers
+// at the same height.
+type DoubleSignEvidence struct {
+	HeaderA *types.Header
+	HeaderB *types.Header
+}
+
+// Number returns the height at which the equivocation happened.
+func (e *DoubleSignEvidence) Number() uint64 {
+	return e.HeaderA.Number.Uint64()
+}
+
+// evidenceRecord is the persisted form of a double-sign evidence. Whether it was
+// submitted is only known by the Punish contract, as the blocks submitting it may
+// still be discarded or reorged away.
+type evidenceRecord struct {
+	Validator common.Address
+	Evidence  *DoubleSignEvidence
+}
+
+// sealedKey identifies the header sealed by a validator at a given height.
+type sealedKey struct {
+	number    uint64
+	validator common.Address
+}
+
+func readEvidence(db ethdb.KeyValueReader, validator common.Address, number uint64) (*evidenceRecord, error) {
+	blob := rawdb.ReadCongressEvidence(db, validator, number)
+	if len(blob) == 0 {
+		return nil, errUnknownEvidence
+	}
+	record := new(evidenceRecord)
+	if err := rlp.DecodeBytes(blob, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func writeEvidence(db ethdb.KeyValueWriter, record *evidenceRecord) error {
+	blob, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteCongressEvidence(db, record.Validator, record.Evidence.Number(), blob)
+	return nil
+}
+
+// readAllEvidences returns all the persisted evidences ordered by validator and height.
+func readAllEvidences(db ethdb.Iteratee) ([]*evidenceRecord, error) {
+	it := rawdb.IterateCongressEvidences(db)
+	defer it.Release()
+
+	var records []*evidenceRecord
+	for it.Next() {
+		record := new(evidenceRecord)
+		if err := rlp.DecodeBytes(it.Value(), record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, it.Error()
+}
+
+// verifyEvidence checks whether the evidence proves that a single validator sealed two
+// different headers at the same height, returning the equivocating validator.
+func (c *Congress) verifyEvidence(evidence *DoubleSignEvidence) (common.Address, error) {
+	a, b := evidence.HeaderA, evidence.HeaderB
+	if a == nil || b == nil || a.Number == nil || b.Number == nil {
+		return common.Address{}, errInvalidEvidence
+	}
+	if a.Number.Cmp(b.Number) != 0 || a.Hash() == b.Hash() {
+		return common.Address{}, errInvalidEvidence
+	}
+	if len(a.Extra) < extraVanity+extraSeal || len(b.Extra) < extraVanity+extraSeal {
+		return common.Address{}, errInvalidEvidence
+	}
+	signerA, err := ecrecover(a, c.signatures)
+	if err != nil {
+		return common.Address{}, err
+	}
+	signerB, err := ecrecover(b, c.signatures)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if signerA != signerB || signerA != a.Coinbase || signerB != b.Coinbase {
+		return common.Address{}, errInvalidEvidence
+	}
+	return signerA, nil
+}
+
+// recordSealedHeader remembers the header sealed by the validator at its height, and
+// persists a double-sign evidence if a different header was already seen.
+func (c *Congress) recordSealedHeader(validator common.Address, header *types.Header) {
+	key := sealedKey{number: header.Number.Uint64(), validator: validator}
+	v, known := c.sealedHeaders.Get(key)
+	if !known {
+		c.sealedHeaders.Add(key, header)
+		return
+	}
+	seen := v.(*types.Header)
+	if seen.Hash() == header.Hash() {
+		return
+	}
+	if _, err := readEvidence(c.db, validator, key.number); err == nil {
+		return
+	}
+	record := &evidenceRecord{
+		Validator: validator,
+		Evidence:  &DoubleSignEvidence{HeaderA: seen, HeaderB: header},
+	}
+	if err := writeEvidence(c.db, record); err != nil {
+		log.Error("Failed to store double sign evidence", "validator", validator, "number", key.number, "err", err)
+		return
+	}
+	log.Warn("Detected double sign", "validator", validator, "number", key.number, "hashA", seen.Hash(), "hashB", header.Hash())
+}
+
+// pendingEvidences returns the persisted evidences which still can be included in
+// the given header. The ones already punished are skipped when submitting them.
+func (c *Congress) pendingEvidences(header *types.Header) []*DoubleSignEvidence {
+	records, err := readAllEvidences(c.db)
+	if err != nil {
+		log.Error("Failed to read double sign evidences", "err", err)
+		return nil
+	}
+	var evidences []*DoubleSignEvidence
+	for _, record := range records {
+		if err := c.checkEvidenceAge(header, record.Evidence); err != nil {
+			continue
+		}
+		evidences = append(evidences, record.Evidence)
+	}
+	return evidences
+}
+
+// checkEvidenceAge makes sure the evidence is submitted within one epoch after the
+// equivocation happened.
+func (c *Congress) checkEvidenceAge(header *types.Header, evidence *DoubleSignEvidence) error {
+	number, height := header.Number.Uint64(), evidence.Number()
+	if height >= number || number-height > c.config.Epoch {
+		return errExpiredEvidence
+	}
+	return nil
+}
+
+// submitEvidences makes a system transaction for each pending evidence whose validator
+// isn't punished yet for the height and applies it.
+func (c *Congress) submitEvidences(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, receipts []*types.Receipt) ([]*types.Transaction, []*types.Receipt, error) {
+	for _, evidence := range c.pendingEvidences(header) {
+		validator, err := c.verifyEvidence(evidence)
+		if err != nil {
+			log.Warn("Drop invalid double sign evidence", "number", evidence.Number(), "err", err)
+			continue
+		}
+		punished, err := c.doubleSignPunished(chain, header, state, validator, evidence.Number())
+		if err != nil {
+			return nil, nil, err
+		}
+		if punished {
+			log.Debug("Skip double sign evidence already punished", "validator", validator, "number", evidence.Number())
+			continue
+		}
+		evidenceRLP, err := rlp.EncodeToBytes(evidence)
+		if err != nil {
+			return nil, nil, err
+		}
+		nonce := state.GetNonce(c.validator)
+		tx := types.NewTransaction(nonce, systemcontract.DoubleSignEvidenceToAddr, new(big.Int), header.GasLimit, new(big.Int), evidenceRLP)
+		tx, err = c.signTxFn(accounts.Account{Address: c.validator}, tx, chain.Config().ChainID)
+		if err != nil {
+			return nil, nil, err
+		}
+		state.SetNonce(c.validator, nonce+1)
+		receipt := c.executeEvidenceMsg(chain, header, state, validator, evidence, len(txs), tx.Hash(), common.Hash{})
+
+		txs = append(txs, tx)
+		receipts = append(receipts, receipt)
+	}
+	return txs, receipts, nil
+}
+
+// replayEvidence verifies and applies a double-sign evidence system transaction of an imported block.
+func (c *Congress) replayEvidence(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, totalTxIndex int, tx *types.Transaction) (*types.Receipt, error) {
+	sender, err := types.Sender(c.signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	if sender != header.Coinbase {
+		return nil, errors.New("invalid sender for double sign evidence transaction")
+	}
+	evidence := new(DoubleSignEvidence)
+	if err := rlp.DecodeBytes(tx.Data(), evidence); err != nil {
+		return nil, err
+	}
+	validator, err := c.verifyEvidence(evidence)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkEvidenceAge(header, evidence); err != nil {
+		return nil, err
+	}
+	// The state includes the evidences of the earlier blocks and transactions
+	punished, err := c.doubleSignPunished(chain, header, state, validator, evidence.Number())
+	if err != nil {
+		return nil, err
+	}
+	if punished {
+		return nil, errPunishedEvidence
+	}
+	nonce := state.GetNonce(sender)
+	state.SetNonce(sender, nonce+1)
+	return c.executeEvidenceMsg(chain, header, state, validator, evidence, totalTxIndex, tx.Hash(), header.Hash()), nil
+}
+
+// doubleSignPunished reports whether the Punish contract already jailed the validator
+// for double signing at the given height.
+func (c *Congress) doubleSignPunished(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, validator common.Address, height uint64) (bool, error) {
+	method := "doubleSignPunished"
+	data, err := c.abi[systemcontract.PunishContractName].Pack(method, validator, new(big.Int).SetUint64(height))
+	if err != nil {
+		log.Error("Can't pack data for doubleSignPunished", "error", err)
+		return false, err
+	}
+	msg := vmcaller.NewLegacyMessage(header.Coinbase, systemcontract.GetPunishAddr(header.Number, c.chainConfig), 0, new(big.Int), math.MaxUint64, new(big.Int), data, false)
+	result, err := vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, c), c.chainConfig)
+	if err != nil {
+		return false, err
+	}
+	ret, err := c.abi[systemcontract.PunishContractName].Unpack(method, result)
+	if err != nil {
+		return false, err
+	}
+	if len(ret) != 1 {
+		return false, errors.New("invalid output length")
+	}
+	punished, ok := ret[0].(bool)
+	if !ok {
+		return false, errors.New("invalid punished format")
+	}
+	return punished, nil
+}
+
+// executeEvidenceMsg submits the evidence to the Punish contract, the returned value should not nil.
+func (c *Congress) executeEvidenceMsg(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, validator common.Address, evidence *DoubleSignEvidence, totalTxIndex int, txHash, bHash common.Hash) *types.Receipt {
+	state.Prepare(txHash, totalTxIndex)
+	data, err := c.abi[systemcontract.PunishContractName].Pack("punishDoubleSign", validator, new(big.Int).SetUint64(evidence.Number()))
+	if err == nil {
+		msg := vmcaller.NewLegacyMessage(header.Coinbase, systemcontract.GetPunishAddr(header.Number, c.chainConfig), 0, new(big.Int), math.MaxUint64, new(big.Int), data, false)
+		_, err = vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, c), c.chainConfig)
+	}
+	// evidence message will not actually consumes gas
+	receipt := types.NewReceipt([]byte{}, err != nil, header.GasUsed)
+	receipt.Logs = state.GetLogs(txHash, bHash)
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	receipt.TxHash = txHash
+	receipt.BlockHash = bHash
+	receipt.BlockNumber = header.Number
+	receipt.TransactionIndex = uint(state.TxIndex())
+
+	log.Info("Submitted double sign evidence", "validator", validator, "number", evidence.Number(), "txHash", txHash, "err", err)
+	return receipt
+}
+
+// splitSystemTxs separates the double-sign evidence transactions from the governance ones.
+func splitSystemTxs(systemTxs []*types.Transaction) (govTxs []*types.Transaction, evidenceTxs []*types.Transaction) {
+	for _, tx := range systemTxs {
+		if *tx.To() == systemcontract.DoubleSignEvidenceToAddr {
+			evidenceTxs = append(evidenceTxs, tx)
+		} else {
+			govTxs = append(govTxs, tx)
+		}
+	}
+	return
+}
+
+// applyEvidenceTx applies a double-sign evidence system transaction using a given evm,
+// the main purpose of this method is for tracing.
+func (c *Congress) applyEvidenceTx(evm *vm.EVM, state *state.StateDB, txIndex int, sender common.Address, tx *types.Transaction) (ret []byte, vmerr error, err error) {
+	evidence := new(DoubleSignEvidence)
+	if err = rlp.DecodeBytes(tx.Data(), evidence); err != nil {
+		return
+	}
+	validator, err := c.verifyEvidence(evidence)
+	if err != nil {
+		return
+	}
+	data, err := c.abi[systemcontract.PunishContractName].Pack("punishDoubleSign", validator, new(big.Int).SetUint64(evidence.Number()))
+	if err != nil {
+		return
+	}
+	evm.Context.ExtraValidator = nil
+	nonce := evm.StateDB.GetNonce(sender)
+	//add nonce for validator
+	evm.StateDB.SetNonce(sender, nonce+1)
+
+	state.Prepare(tx.Hash(), txIndex)
+	evm.TxContext = vm.TxContext{
+		Origin:   sender,
+		GasPrice: new(big.Int),
+	}
+	ret, _, vmerr = evm.Call(vm.AccountRef(sender), *systemcontract.GetPunishAddr(evm.Context.BlockNumber, c.chainConfig), data, tx.Gas(), new(big.Int))
+	state.Finalise(true)
+	return
+}
@@ -0,0 +1,153 @@
+package congress
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/consensus/congress/vmcaller"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func signedTestHeader(t *testing.T, number int64, root common.Hash) (*types.Header, common.Address) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	header := &types.Header{
+		Number:     big.NewInt(number),
+		Coinbase:   addr,
+		Root:       root,
+		Difficulty: new(big.Int).Set(diffInTurn),
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+	sig, err := crypto.Sign(SealHash(header).Bytes(), key)
+	if err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+	return header, addr
+}
+
+func TestVerifyEvidence(t *testing.T) {
+	c := New(params.AllCongressProtocolChanges, rawdb.NewMemoryDatabase())
+
+	a, signer := signedTestHeader(t, 10, common.HexToHash("0x01"))
+	b, _ := signedTestHeader(t, 10, common.HexToHash("0x02"))
+	other, _ := signedTestHeader(t, 11, common.HexToHash("0x02"))
+
+	if val, err := c.verifyEvidence(&DoubleSignEvidence{HeaderA: a, HeaderB: b}); err != nil || val != signer {
+		t.Fatalf("valid evidence rejected: validator %x, err %v", val, err)
+	}
+	if _, err := c.verifyEvidence(&DoubleSignEvidence{HeaderA: a, HeaderB: a}); err != errInvalidEvidence {
+		t.Errorf("same header evidence: have %v, want %v", err, errInvalidEvidence)
+	}
+	if _, err := c.verifyEvidence(&DoubleSignEvidence{HeaderA: a, HeaderB: other}); err != errInvalidEvidence {
+		t.Errorf("different height evidence: have %v, want %v", err, errInvalidEvidence)
+	}
+	forged := types.CopyHeader(b)
+	forged.Coinbase = common.HexToAddress("0x03")
+	if _, err := c.verifyEvidence(&DoubleSignEvidence{HeaderA: a, HeaderB: forged}); err != errInvalidEvidence {
+		t.Errorf("forged coinbase evidence: have %v, want %v", err, errInvalidEvidence)
+	}
+}
+
+func TestRecordSealedHeader(t *testing.T) {
+	c := New(params.AllCongressProtocolChanges, rawdb.NewMemoryDatabase())
+
+	a, signer := signedTestHeader(t, 10, common.HexToHash("0x01"))
+	b, _ := signedTestHeader(t, 10, common.HexToHash("0x02"))
+
+	c.recordSealedHeader(signer, a)
+	c.recordSealedHeader(signer, a)
+	if records, _ := readAllEvidences(c.db); len(records) != 0 {
+		t.Fatalf("evidence recorded for a single header: %d", len(records))
+	}
+	c.recordSealedHeader(signer, b)
+	records, err := readAllEvidences(c.db)
+	if err != nil {
+		t.Fatalf("failed to read evidences: %v", err)
+	}
+	if len(records) != 1 || records[0].Validator != signer || records[0].Evidence.Number() != 10 {
+		t.Fatalf("unexpected evidences: %v", records)
+	}
+
+	head := &types.Header{Number: big.NewInt(11)}
+	if pending := c.pendingEvidences(head); len(pending) != 1 {
+		t.Fatalf("pending evidences mismatch: have %d, want 1", len(pending))
+	}
+	// The evidence can't be submitted anymore after an epoch
+	expired := &types.Header{Number: new(big.Int).SetUint64(10 + c.config.Epoch + 1)}
+	if pending := c.pendingEvidences(expired); len(pending) != 0 {
+		t.Fatalf("expired evidence still pending: %d", len(pending))
+	}
+}
+
+// Tests that the evidences are submitted and replayed only once per validator and
+// height, the Punish contract being the source of truth.
+func TestSubmitReplayEvidence(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.RedCoastBlock = big.NewInt(0)
+	config.DoubleSignBlock = big.NewInt(0)
+
+	var (
+		engine = New(&config, rawdb.NewMemoryDatabase())
+		chain  = &testHeaderReader{config: &config}
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		signer = crypto.PubkeyToAddress(key.PublicKey)
+		header = &types.Header{Number: big.NewInt(11), Coinbase: signer, GasLimit: 8000000, Difficulty: big.NewInt(1), Extra: make([]byte, extraVanity+extraSeal)}
+	)
+	engine.Authorize(signer, nil, func(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+	})
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err := systemcontract.ApplySystemContractUpgrade(systemcontract.SysContractDoubleSign, statedb, header, newChainContext(chain, engine), &config); err != nil {
+		t.Fatalf("failed to upgrade punish contract: %v", err)
+	}
+	data, _ := engine.abi[systemcontract.PunishV1ContractName].Pack("initialize")
+	msg := vmcaller.NewLegacyMessage(signer, &systemcontract.PunishV1ContractAddr, 0, new(big.Int), math.MaxUint64, new(big.Int), data, false)
+	if _, err := vmcaller.ExecuteMsg(msg, statedb, header, newChainContext(chain, engine), &config); err != nil {
+		t.Fatalf("failed to initialize punish contract: %v", err)
+	}
+	// Let the validators contract resolve any validator to a contract accepting punish()
+	jailed := common.HexToAddress("0xbeef")
+	statedb.SetCode(systemcontract.ValidatorsV1ContractAddr, common.FromHex("0x73"+jailed.Hex()[2:]+"60005260206000f3"))
+	statedb.SetCode(jailed, []byte{0x00})
+
+	a, _ := signedTestHeader(t, 10, common.HexToHash("0x01"))
+	b, _ := signedTestHeader(t, 10, common.HexToHash("0x02"))
+	engine.recordSealedHeader(signer, a)
+	engine.recordSealedHeader(signer, b)
+
+	replayed := statedb.Copy()
+	txs, receipts, err := engine.submitEvidences(chain, header, statedb, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to submit evidences: %v", err)
+	}
+	if len(txs) != 1 || receipts[0].Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("submission mismatch: %d txs, receipts %v", len(txs), receipts)
+	}
+	// A punished height is not submitted again
+	if txs, _, err := engine.submitEvidences(chain, header, statedb, nil, nil); err != nil || len(txs) != 0 {
+		t.Fatalf("punished evidence submitted again: %d txs, err %v", len(txs), err)
+	}
+	// The evidence is still pending for the blocks not built on the punishing one
+	if txs, _, err := engine.submitEvidences(chain, header, replayed.Copy(), nil, nil); err != nil || len(txs) != 1 {
+		t.Fatalf("evidence of a discarded block not submitted again: %d txs, err %v", len(txs), err)
+	}
+	// An imported block can't punish the same height twice
+	if _, err := engine.replayEvidence(chain, header, replayed, 0, txs[0]); err != nil {
+		t.Fatalf("failed to replay evidence: %v", err)
+	}
+	if _, err := engine.replayEvidence(chain, header, replayed, 1, txs[0]); err != errPunishedEvidence {
+		t.Fatalf("duplicate evidence replay: have %v, want %v", err, errPunishedEvidence)
+	}
+	if _, err := engine.replayEvidence(chain, header, statedb, 0, txs[0]); err != errPunishedEvidence {
+		t.Fatalf("punished evidence replay: have %v, want %v", err, errPunishedEvidence)
+	}
+}
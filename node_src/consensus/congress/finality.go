@@ -0,0 +1,304 @@
+package congress
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	maxFutureVotes = 16  // Number of blocks ahead of the head to accept votes for
+	maxVoteAge     = 128 // Number of blocks behind the head to keep votes for
+)
+
+var (
+	// errFinalityDisabled is returned if votes are handed to a node not running the
+	// finality gadget.
+	errFinalityDisabled = errors.New("finality gadget not running")
+
+	// ErrInvalidVote is returned if a vote isn't signed by a validator of the block.
+	ErrInvalidVote = errors.New("invalid vote")
+
+	// errNoFinalizedBlock is returned if the finalized block is requested before any
+	// block got finalized.
+	errNoFinalizedBlock = errors.New("finalized block not found")
+
+	finalizedGauge = metrics.NewRegisteredGauge("congress/finality/finalized", nil)
+	votesMeter     = metrics.NewRegisteredMeter("congress/finality/votes", nil)
+)
+
+// FinalityConfig are the configuration parameters of the finality gadget.
+type FinalityConfig struct {
+	Enabled bool // Whether to exchange votes with peers and vote on new heads if sealing
+}
+
+// NewVoteEvent is posted when a new valid vote is collected.
+type NewVoteEvent struct{ Vote *types.Vote }
+
+// finality collects the votes of the validators on recent blocks and finalizes the
+// highest canonical block voted by more than 2/3 of its validators.
+type finality struct {
+	congress *Congress
+
+	votes map[common.Hash]map[common.Address]*types.Vote // Collected votes by block hash and validator
+	voted uint64                                         // Highest block number the local validator voted on, persisted across restarts
+
+	number uint64      // Number of the latest finalized block
+	hash   common.Hash // Hash of the latest finalized block
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+	lock  sync.RWMutex
+
+	quit chan struct{}
+}
+
+// voteSigningData returns the data a validator signs to vote on a block.
+func voteSigningData(number uint64, hash common.Hash) []byte {
+	data, _ := rlp.EncodeToBytes([]interface{}{"congress-vote", number, hash})
+	return data
+}
+
+// ecrecoverVote extracts the address of the validator who signed the vote.
+func ecrecoverVote(vote *types.Vote) (common.Address, error) {
+	if len(vote.Signature) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidVote
+	}
+	pubkey, err := crypto.Ecrecover(crypto.Keccak256(voteSigningData(vote.Number, vote.Hash)), vote.Signature)
+	if err != nil {
+		return common.Address{}, ErrInvalidVote
+	}
+	var validator common.Address
+	copy(validator[:], crypto.Keccak256(pubkey[1:])[12:])
+	return validator, nil
+}
+
+// StartFinality starts the finality gadget if enabled, voting on every new chain head
+// if the local validator is sealing.
+func (c *Congress) StartFinality(chain chainHeadSubscriber, cfg FinalityConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	f := &finality{
+		congress: c,
+		votes:    make(map[common.Hash]map[common.Address]*types.Vote),
+		quit:     make(chan struct{}),
+	}
+	if hash := rawdb.ReadFinalizedBlockHash(c.db); hash != (common.Hash{}) {
+		if number := rawdb.ReadHeaderNumber(c.db, hash); number != nil {
+			f.number, f.hash = *number, hash
+			finalizedGauge.Update(int64(f.number))
+		}
+	}
+	f.voted, _ = rawdb.ReadCongressLastVote(c.db)
+	c.finality = f
+	go f.loop(chain)
+}
+
+// AddVote validates a vote received from the network and tallies it.
+func (c *Congress) AddVote(vote *types.Vote) error {
+	if c.finality == nil {
+		return errFinalityDisabled
+	}
+	return c.finality.add(vote)
+}
+
+// SubscribeNewVotes registers a subscription of NewVoteEvent.
+func (c *Congress) SubscribeNewVotes(ch chan<- NewVoteEvent) (event.Subscription, error) {
+	if c.finality == nil {
+		return nil, errFinalityDisabled
+	}
+	return c.finality.scope.Track(c.finality.feed.Subscribe(ch)), nil
+}
+
+// Finalized returns the number and hash of the latest finalized block, zero if none,
+// if the finality gadget isn't running or if the block isn't canonical anymore.
+func (c *Congress) Finalized() (uint64, common.Hash) {
+	if c.finality == nil {
+		return 0, common.Hash{}
+	}
+	c.finality.lock.RLock()
+	number, hash := c.finality.number, c.finality.hash
+	c.finality.lock.RUnlock()
+
+	if hash == (common.Hash{}) {
+		return 0, common.Hash{}
+	}
+	if header := c.chain.GetHeaderByNumber(number); header == nil || header.Hash() != hash {
+		return 0, common.Hash{}
+	}
+	return number, hash
+}
+
+func (f *finality) loop(chain chainHeadSubscriber) {
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-headCh:
+			header := ev.Block.Header()
+			if err := f.vote(header); err != nil {
+				log.Debug("Failed to vote on block", "number", header.Number, "hash", header.Hash(), "err", err)
+			}
+			f.update(header)
+		case <-sub.Err():
+			return
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+func (f *finality) stop() {
+	f.scope.Close()
+	close(f.quit)
+}
+
+// vote signs a vote on the new head if the local validator is one of its validators.
+// The local validator votes at most once per height, so that it never supports two
+// competing blocks, even across restarts as the last vote is persisted before it's
+// published.
+func (f *finality) vote(header *types.Header) error {
+	c := f.congress
+	c.lock.RLock()
+	val, signFn := c.validator, c.signFn
+	c.lock.RUnlock()
+
+	number := header.Number.Uint64()
+	if signFn == nil || number == 0 || number <= f.voted {
+		return nil
+	}
+	snap, err := c.snapshot(c.chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Validators[val]; !ok {
+		return nil
+	}
+	sig, err := signFn(accounts.Account{Address: val}, accounts.MimetypeCongressVote, voteSigningData(number, header.Hash()))
+	if err != nil {
+		return err
+	}
+	f.voted = number
+	rawdb.WriteCongressLastVote(c.db, number, header.Hash())
+	return f.add(&types.Vote{Number: number, Hash: header.Hash(), Signature: sig})
+}
+
+// add validates the vote and tallies it. Votes for unknown or old blocks are dropped,
+// since any vote on a descendant finalizes them too.
+func (f *finality) add(vote *types.Vote) error {
+	c := f.congress
+
+	head := c.chain.CurrentHeader().Number.Uint64()
+	if vote.Number > head+maxFutureVotes || vote.Number+maxVoteAge < head {
+		return nil
+	}
+	header := c.chain.GetHeaderByHash(vote.Hash)
+	if header == nil || header.Number.Uint64() != vote.Number {
+		return nil
+	}
+	validator, err := ecrecoverVote(vote)
+	if err != nil {
+		return err
+	}
+	snap, err := c.snapshot(c.chain, vote.Number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Validators[validator]; !ok {
+		return ErrInvalidVote
+	}
+	f.lock.Lock()
+	if vote.Number <= f.number {
+		f.lock.Unlock()
+		return nil
+	}
+	votes := f.votes[vote.Hash]
+	if votes == nil {
+		votes = make(map[common.Address]*types.Vote)
+		f.votes[vote.Hash] = votes
+	}
+	if _, known := votes[validator]; known {
+		f.lock.Unlock()
+		return nil
+	}
+	votes[validator] = vote
+	f.lock.Unlock()
+
+	votesMeter.Mark(1)
+	f.feed.Send(NewVoteEvent{Vote: vote})
+	f.tally(header, snap)
+	return nil
+}
+
+// tally finalizes the block if it's canonical and more than 2/3 of its validators
+// voted on it.
+func (f *finality) tally(header *types.Header, snap *Snapshot) {
+	c := f.congress
+	number, hash := header.Number.Uint64(), header.Hash()
+	if canonical := c.chain.GetHeaderByNumber(number); canonical == nil || canonical.Hash() != hash {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if number <= f.number || 3*len(f.votes[hash]) <= 2*len(snap.Validators) {
+		return
+	}
+	rawdb.WriteFinalizedBlockHash(c.db, hash)
+	f.number, f.hash = number, hash
+	finalizedGauge.Update(int64(number))
+	log.Info("Finalized block", "number", number, "hash", hash, "votes", len(f.votes[hash]), "validators", len(snap.Validators))
+
+	f.prune(number)
+}
+
+// update tallies the votes again after a new head, as a reorg may have turned voted
+// blocks canonical, and drops the votes of blocks too old to be finalized.
+func (f *finality) update(head *types.Header) {
+	f.lock.Lock()
+	hashes := make([]common.Hash, 0, len(f.votes))
+	for hash := range f.votes {
+		hashes = append(hashes, hash)
+	}
+	if number := head.Number.Uint64(); number > maxVoteAge {
+		f.prune(number - maxVoteAge)
+	}
+	f.lock.Unlock()
+
+	for _, hash := range hashes {
+		header := f.congress.chain.GetHeaderByHash(hash)
+		if header == nil {
+			continue
+		}
+		snap, err := f.congress.snapshot(f.congress.chain, header.Number.Uint64()-1, header.ParentHash, nil)
+		if err != nil {
+			continue
+		}
+		f.tally(header, snap)
+	}
+}
+
+// prune drops the votes on blocks at or below the given number. The lock must be held.
+func (f *finality) prune(number uint64) {
+	for hash, votes := range f.votes {
+		for _, vote := range votes {
+			if vote.Number <= number {
+				delete(f.votes, hash)
+			}
+			break
+		}
+	}
+}
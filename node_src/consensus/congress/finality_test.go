@@ -0,0 +1,216 @@
+package congress
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the validator of a vote is recovered from its signature, and that the
+// signature doesn't carry over to other blocks.
+func TestVoteSignature(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	hash := common.HexToHash("0x1234")
+	sig, err := crypto.Sign(crypto.Keccak256(voteSigningData(10, hash)), key)
+	if err != nil {
+		t.Fatalf("failed to sign vote: %v", err)
+	}
+	vote := &types.Vote{Number: 10, Hash: hash, Signature: sig}
+	if validator, err := ecrecoverVote(vote); err != nil || validator != addr {
+		t.Fatalf("validator mismatch: have %x (%v), want %x", validator, err, addr)
+	}
+	for i, forged := range []*types.Vote{
+		{Number: 11, Hash: hash, Signature: sig},
+		{Number: 10, Hash: common.HexToHash("0x5678"), Signature: sig},
+	} {
+		if validator, err := ecrecoverVote(forged); err == nil && validator == addr {
+			t.Errorf("test %d: forged vote attributed to the validator", i)
+		}
+	}
+	if _, err := ecrecoverVote(&types.Vote{Number: 10, Hash: hash, Signature: sig[:64]}); err != ErrInvalidVote {
+		t.Errorf("short signature error mismatch: have %v, want %v", err, ErrInvalidVote)
+	}
+}
+
+// canonicalReader is a chain of headers in memory with a switchable canonical chain.
+type canonicalReader struct {
+	*headReader
+	canonical map[uint64]common.Hash
+}
+
+func (r *canonicalReader) GetHeaderByNumber(number uint64) *types.Header {
+	return r.headers[r.canonical[number]]
+}
+
+// Tests that the votes are tallied into the finalized block once more than 2/3 of
+// the validators voted on a canonical block, and pruned once useless.
+func TestFinalityTally(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	validators := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		validators[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	var (
+		config = params.AllCongressProtocolChanges
+		engine = New(config, rawdb.NewMemoryDatabase())
+		chain  = &canonicalReader{
+			headReader: &headReader{testHeaderReader: &testHeaderReader{config: config, headers: make(map[common.Hash]*types.Header)}},
+			canonical:  make(map[uint64]common.Hash),
+		}
+	)
+	// All the blocks have the same validators, with a side block at 6
+	insert := func(parent *types.Header, time uint64) *types.Header {
+		header := &types.Header{Number: big.NewInt(1), Time: time, Difficulty: big.NewInt(1), Extra: make([]byte, extraVanity+extraSeal)}
+		if parent != nil {
+			header.ParentHash, header.Number = parent.Hash(), new(big.Int).Add(parent.Number, common.Big1)
+		}
+		chain.headers[header.Hash()] = header
+		engine.recents.Add(header.Hash(), newSnapshot(engine.config, engine.signatures, header.Number.Uint64(), header.Hash(), validators))
+		return header
+	}
+	var parent *types.Header
+	for i := 0; i < 10; i++ {
+		parent = insert(parent, 0)
+		chain.canonical[parent.Number.Uint64()], chain.head = parent.Hash(), parent
+	}
+	side := insert(chain.GetHeaderByNumber(5), 1)
+	engine.recents.Add(common.Hash{}, newSnapshot(engine.config, engine.signatures, 0, common.Hash{}, validators))
+	engine.SetChain(chain)
+
+	f := &finality{congress: engine, votes: make(map[common.Hash]map[common.Address]*types.Vote), quit: make(chan struct{})}
+	engine.finality = f
+
+	vote := func(key *ecdsa.PrivateKey, header *types.Header) *types.Vote {
+		sig, _ := crypto.Sign(crypto.Keccak256(voteSigningData(header.Number.Uint64(), header.Hash())), key)
+		return &types.Vote{Number: header.Number.Uint64(), Hash: header.Hash(), Signature: sig}
+	}
+	// Votes of outsiders and forged votes are rejected
+	outsider, _ := crypto.GenerateKey()
+	five := chain.GetHeaderByNumber(5)
+	if err := engine.AddVote(vote(outsider, five)); err != ErrInvalidVote {
+		t.Fatalf("outsider vote error mismatch: have %v, want %v", err, ErrInvalidVote)
+	}
+	forged := vote(keys[0], five)
+	forged.Signature[crypto.SignatureLength-1] = 9
+	if err := engine.AddVote(forged); err != ErrInvalidVote {
+		t.Fatalf("forged vote error mismatch: have %v, want %v", err, ErrInvalidVote)
+	}
+	// Two votes of four aren't enough, duplicates aren't counted
+	for _, key := range []*ecdsa.PrivateKey{keys[0], keys[1], keys[0]} {
+		if err := engine.AddVote(vote(key, five)); err != nil {
+			t.Fatalf("failed to add vote: %v", err)
+		}
+	}
+	if number, _ := engine.Finalized(); number != 0 || len(f.votes[five.Hash()]) != 2 {
+		t.Fatalf("finalized with 2 votes: number %d, votes %d", number, len(f.votes[five.Hash()]))
+	}
+	if err := engine.AddVote(vote(keys[2], five)); err != nil {
+		t.Fatalf("failed to add vote: %v", err)
+	}
+	if number, hash := engine.Finalized(); number != 5 || hash != five.Hash() {
+		t.Fatalf("finalized block mismatch: have %d [%x], want 5 [%x]", number, hash, five.Hash())
+	}
+	if hash := rawdb.ReadFinalizedBlockHash(engine.db); hash != five.Hash() {
+		t.Fatalf("stored finalized block mismatch: have %x, want %x", hash, five.Hash())
+	}
+	if len(f.votes[five.Hash()]) != 0 {
+		t.Fatalf("votes of the finalized block not pruned")
+	}
+	// The votes below the finalized block are dropped
+	four := chain.GetHeaderByNumber(4)
+	if err := engine.AddVote(vote(keys[3], four)); err != nil || len(f.votes[four.Hash()]) != 0 {
+		t.Fatalf("vote below the finalized block kept: %v", err)
+	}
+	// A side block isn't finalized until it turns canonical
+	eight := chain.GetHeaderByNumber(8)
+	for _, key := range keys[:3] {
+		if err := engine.AddVote(vote(key, side)); err != nil {
+			t.Fatalf("failed to add side vote: %v", err)
+		}
+	}
+	if err := engine.AddVote(vote(keys[0], eight)); err != nil {
+		t.Fatalf("failed to add vote: %v", err)
+	}
+	if number, _ := engine.Finalized(); number != 5 {
+		t.Fatalf("side block finalized: %d", number)
+	}
+	chain.canonical[6] = side.Hash()
+	f.update(chain.head)
+	if number, hash := engine.Finalized(); number != 6 || hash != side.Hash() {
+		t.Fatalf("reorged block not finalized: have %d [%x], want 6 [%x]", number, hash, side.Hash())
+	}
+	if len(f.votes) != 1 || len(f.votes[eight.Hash()]) != 1 {
+		t.Fatalf("votes above the finalized block not kept: %v", f.votes)
+	}
+	// The votes too old to finalize anything are dropped with the new heads
+	f.update(&types.Header{Number: new(big.Int).SetUint64(8 + maxVoteAge)})
+	if len(f.votes) != 0 {
+		t.Fatalf("old votes not pruned: %v", f.votes)
+	}
+}
+
+// chainHeadFeed is a chain notifying about the heads sent to its feed.
+type chainHeadFeed struct{ feed event.Feed }
+
+func (c *chainHeadFeed) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+// Tests that the local validator votes once per height, even after a restart.
+func TestFinalityVoteOnce(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+
+	var (
+		config = params.AllCongressProtocolChanges
+		db     = rawdb.NewMemoryDatabase()
+		engine = New(config, db)
+		chain  = &headReader{testHeaderReader: &testHeaderReader{config: config, headers: make(map[common.Hash]*types.Header)}}
+		signed int
+	)
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1), Extra: make([]byte, extraVanity+extraSeal)}
+	header := &types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1), Difficulty: big.NewInt(1), Extra: make([]byte, extraVanity+extraSeal)}
+	sibling := types.CopyHeader(header)
+	sibling.Time++
+	for _, h := range []*types.Header{genesis, header, sibling} {
+		chain.headers[h.Hash()] = h
+	}
+	chain.head = header
+	engine.recents.Add(genesis.Hash(), newSnapshot(engine.config, engine.signatures, 0, genesis.Hash(), []common.Address{validator}))
+	engine.SetChain(chain)
+	engine.Authorize(validator, func(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+		signed++
+		return crypto.Sign(crypto.Keccak256(data), key)
+	}, nil)
+
+	f := &finality{congress: engine, votes: make(map[common.Hash]map[common.Address]*types.Vote), quit: make(chan struct{})}
+	engine.finality = f
+	if err := f.vote(header); err != nil {
+		t.Fatalf("failed to vote: %v", err)
+	}
+	if number, hash := rawdb.ReadCongressLastVote(db); number != 1 || hash != header.Hash() {
+		t.Fatalf("stored vote mismatch: have %d [%x], want 1 [%x]", number, hash, header.Hash())
+	}
+	// A restarted gadget doesn't vote again at the same height
+	engine.StartFinality(&chainHeadFeed{}, FinalityConfig{Enabled: true})
+	defer engine.finality.stop()
+
+	if err := engine.finality.vote(sibling); err != nil {
+		t.Fatalf("failed to skip vote: %v", err)
+	}
+	if signed != 1 {
+		t.Fatalf("votes signed mismatch: have %d, want 1", signed)
+	}
+}
@@ -0,0 +1,240 @@
+package congress
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	chainHeadChanSize = 10              // Size of channel listening to ChainHeadEvent
+	webhookTimeout    = 5 * time.Second // Timeout of a single webhook request
+)
+
+var (
+	missedBlocksGauge        = metrics.NewRegisteredGauge("congress/missedblocks/counter", nil)
+	missedBlocksPercentGauge = metrics.NewRegisteredGauge("congress/missedblocks/percent", nil)
+)
+
+// MissedBlocksConfig are the configuration parameters of the missed blocks alerts.
+type MissedBlocksConfig struct {
+	Thresholds []uint64 // Percentages of the jail threshold (removeThreshold) at which to raise an alert
+	Webhook    string   // URL to POST alerts to, disabled if empty
+}
+
+// DefaultMissedBlocksConfig contains the default missed blocks alert settings.
+var DefaultMissedBlocksConfig = MissedBlocksConfig{
+	Thresholds: []uint64{50, 80, 90},
+}
+
+// Config contains the node-local settings of the congress engine, which are not
+// part of the consensus rules.
+type Config struct {
+	MissedBlocks MissedBlocksConfig
+	Finality     FinalityConfig
+	Snapshots    SnapshotStoreConfig
+	Denials      DenialAuditConfig
+}
+
+// DefaultConfig contains the default node-local settings of the congress engine.
+var DefaultConfig = Config{
+	MissedBlocks: DefaultMissedBlocksConfig,
+}
+
+// MissedBlocksEvent is posted when the missed blocks counter of the local validator
+// changes in the Punish contract.
+type MissedBlocksEvent struct {
+	Validator       common.Address `json:"validator"`
+	Number          uint64         `json:"number"`
+	Hash            common.Hash    `json:"hash"`
+	Counter         uint64         `json:"counter"`
+	PunishThreshold uint64         `json:"punishThreshold"` // Counter at which the validator loses its rewards
+	RemoveThreshold uint64         `json:"removeThreshold"` // Counter at which the validator is jailed
+	Threshold       uint64         `json:"threshold"`       // Highest alert threshold crossed in percent of removeThreshold, 0 if none
+}
+
+// chainHeadSubscriber is the chain which can notify the monitor about new heads.
+type chainHeadSubscriber interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// missedBlocksMonitor watches the missed blocks counter of the local validator and
+// warns before it gets jailed.
+type missedBlocksMonitor struct {
+	cfg      MissedBlocksConfig
+	congress *Congress
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+
+	counter   uint64 // Last known counter of the local validator
+	threshold uint64 // Last alert threshold crossed
+
+	quit chan struct{}
+}
+
+func newMissedBlocksMonitor(cfg MissedBlocksConfig, congress *Congress) *missedBlocksMonitor {
+	thresholds := make([]uint64, len(cfg.Thresholds))
+	copy(thresholds, cfg.Thresholds)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+	cfg.Thresholds = thresholds
+
+	return &missedBlocksMonitor{
+		cfg:      cfg,
+		congress: congress,
+		quit:     make(chan struct{}),
+	}
+}
+
+// StartMissedBlocksMonitor starts watching the missed blocks counter of the local
+// validator on every new chain head.
+func (c *Congress) StartMissedBlocksMonitor(chain chainHeadSubscriber, cfg MissedBlocksConfig) {
+	c.monitor = newMissedBlocksMonitor(cfg, c)
+	go c.monitor.loop(chain)
+}
+
+// SubscribeMissedBlocks registers a subscription of MissedBlocksEvent.
+func (c *Congress) SubscribeMissedBlocks(ch chan<- *MissedBlocksEvent) (event.Subscription, error) {
+	if c.monitor == nil {
+		return nil, errors.New("missed blocks monitor not running")
+	}
+	return c.monitor.scope.Track(c.monitor.feed.Subscribe(ch)), nil
+}
+
+func (m *missedBlocksMonitor) loop(chain chainHeadSubscriber) {
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-headCh:
+			if err := m.update(ev.Block.Header()); err != nil {
+				log.Debug("Failed to check missed blocks counter", "number", ev.Block.Number(), "err", err)
+			}
+		case <-sub.Err():
+			return
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+func (m *missedBlocksMonitor) stop() {
+	m.scope.Close()
+	close(m.quit)
+}
+
+// update reads the missed blocks counter of the local validator at the given head and
+// raises an alert if a new threshold is crossed.
+func (m *missedBlocksMonitor) update(header *types.Header) error {
+	m.congress.lock.RLock()
+	val := m.congress.validator
+	m.congress.lock.RUnlock()
+	if val == (common.Address{}) {
+		return nil
+	}
+	ev, err := m.congress.missedBlocksOf(val, header)
+	if err != nil {
+		return err
+	}
+	var percent uint64
+	if ev.RemoveThreshold > 0 {
+		percent = ev.Counter * 100 / ev.RemoveThreshold
+	}
+	for _, threshold := range m.cfg.Thresholds {
+		if percent >= threshold {
+			ev.Threshold = threshold
+		}
+	}
+	missedBlocksGauge.Update(int64(ev.Counter))
+	missedBlocksPercentGauge.Update(int64(percent))
+
+	if ev.Counter == m.counter {
+		return nil
+	}
+	m.counter = ev.Counter
+	log.Debug("Missed blocks counter changed", "validator", val, "number", ev.Number, "counter", ev.Counter, "remove", ev.RemoveThreshold)
+
+	if ev.Threshold > m.threshold {
+		log.Warn("Validator is approaching jail", "validator", val, "number", ev.Number, "counter", ev.Counter,
+			"punish", ev.PunishThreshold, "remove", ev.RemoveThreshold, "threshold", ev.Threshold)
+		if m.cfg.Webhook != "" {
+			go m.notifyWebhook(ev)
+		}
+	}
+	m.threshold = ev.Threshold
+	m.feed.Send(ev)
+	return nil
+}
+
+func (m *missedBlocksMonitor) notifyWebhook(ev *MissedBlocksEvent) {
+	blob, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(m.cfg.Webhook, "application/json", bytes.NewReader(blob))
+	if err != nil {
+		log.Warn("Failed to call missed blocks webhook", "url", m.cfg.Webhook, "err", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Warn("Missed blocks webhook rejected alert", "url", m.cfg.Webhook, "status", resp.Status)
+	}
+}
+
+// missedBlocksOf reads the missed blocks counter of a validator and the punish thresholds
+// from the Punish contract at the given header.
+func (c *Congress) missedBlocksOf(val common.Address, header *types.Header) (*MissedBlocksEvent, error) {
+	statedb, err := c.stateFn(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	punishABI := c.abi[systemcontract.PunishContractName]
+	addr := *systemcontract.GetPunishAddr(header.Number, c.chainConfig)
+	get := func(method string, args ...interface{}) (uint64, error) {
+		ret, err := c.commonCallContract(header, statedb, punishABI, addr, method, 1, args...)
+		if err != nil {
+			return 0, err
+		}
+		value, ok := ret[0].(*big.Int)
+		if !ok {
+			return 0, errors.New("invalid " + method + " format")
+		}
+		return value.Uint64(), nil
+	}
+	counter, err := get("getPunishRecord", val)
+	if err != nil {
+		return nil, err
+	}
+	punishThreshold, err := get("punishThreshold")
+	if err != nil {
+		return nil, err
+	}
+	removeThreshold, err := get("removeThreshold")
+	if err != nil {
+		return nil, err
+	}
+	return &MissedBlocksEvent{
+		Validator:       val,
+		Number:          header.Number.Uint64(),
+		Hash:            header.Hash(),
+		Counter:         counter,
+		PunishThreshold: punishThreshold,
+		RemoveThreshold: removeThreshold,
+	}, nil
+}
@@ -0,0 +1,162 @@
+package congress
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/consensus/congress/vmcaller"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the monitor follows the missed blocks counter of the Punish contract,
+// which is raised by the out-of-turn blocks only and reset by the jail threshold
+// and the epoch decrease, alerting again once a threshold is crossed after a reset.
+func TestMissedBlocksMonitor(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.RedCoastBlock = big.NewInt(0)
+	config.Congress = &params.CongressConfig{Period: 3, Epoch: 200}
+
+	var (
+		engine = New(&config, rawdb.NewMemoryDatabase())
+		chain  = &testHeaderReader{config: &config}
+		local  = common.HexToAddress("0xa")
+		other  = common.HexToAddress("0xb")
+		snap   = newSnapshot(config.Congress, nil, 0, common.Hash{}, []common.Address{local, other})
+		header = &types.Header{Number: big.NewInt(0), Coinbase: other, GasLimit: 8000000, Difficulty: new(big.Int).Set(diffInTurn)}
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err := systemcontract.ApplySystemContractUpgrade(systemcontract.SysContractDoubleSign, statedb, header, newChainContext(chain, engine), &config); err != nil {
+		t.Fatalf("failed to deploy punish contract: %v", err)
+	}
+	data, _ := engine.abi[systemcontract.PunishV1ContractName].Pack("initialize")
+	msg := vmcaller.NewLegacyMessage(other, &systemcontract.PunishV1ContractAddr, 0, new(big.Int), math.MaxUint64, new(big.Int), data, false)
+	if _, err := vmcaller.ExecuteMsg(msg, statedb, header, newChainContext(chain, engine), &config); err != nil {
+		t.Fatalf("failed to initialize punish contract: %v", err)
+	}
+	// Let the validators contract resolve the validator to a contract accepting any call
+	jailed := common.HexToAddress("0xbeef")
+	statedb.SetCode(systemcontract.ValidatorsV1ContractAddr, common.FromHex("0x73"+jailed.Hex()[2:]+"60005260206000f3"))
+	statedb.SetCode(jailed, []byte{0x00})
+	engine.SetStateFn(func(common.Hash) (*state.StateDB, error) { return statedb, nil })
+
+	alerts := make(chan uint64, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var ev MissedBlocksEvent
+		if err := json.NewDecoder(req.Body).Decode(&ev); err != nil {
+			t.Errorf("failed to decode alert: %v", err)
+		}
+		alerts <- ev.Threshold
+	}))
+	defer server.Close()
+
+	engine.Authorize(local, nil, nil)
+	engine.monitor = newMissedBlocksMonitor(MissedBlocksConfig{Thresholds: []uint64{80, 50}, Webhook: server.URL}, engine)
+	events := make(chan *MissedBlocksEvent, 1)
+	sub, _ := engine.SubscribeMissedBlocks(events)
+	defer sub.Unsubscribe()
+
+	// expect checks the outcome of the last block, waiting for the webhook if an
+	// alert is due
+	expect := func(counter uint64, threshold uint64, alert bool) {
+		t.Helper()
+		select {
+		case ev := <-events:
+			if ev.Counter != counter || ev.Threshold != threshold || ev.PunishThreshold != 24 || ev.RemoveThreshold != 48 {
+				t.Fatalf("block %d: event mismatch: have %+v, want counter %d, threshold %d", header.Number, ev, counter, threshold)
+			}
+		default:
+			t.Fatalf("block %d: missing event for counter %d", header.Number, counter)
+		}
+		if alert {
+			select {
+			case have := <-alerts:
+				if have != threshold {
+					t.Fatalf("block %d: alert threshold mismatch: have %d, want %d", header.Number, have, threshold)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("block %d: missing alert for threshold %d", header.Number, threshold)
+			}
+		}
+	}
+	var (
+		counter   uint64
+		threshold uint64
+	)
+	for missed := 0; missed < 72; {
+		header.Number = new(big.Int).Add(header.Number, common.Big1)
+		number := header.Number.Uint64()
+
+		// The blocks of the other validator are sealed in-turn and leave the counter alone
+		val, punish := missedValidator(snap, number)
+		if val != local {
+			if err := engine.monitor.update(header); err != nil {
+				t.Fatalf("block %d: failed to update monitor: %v", number, err)
+			}
+			if len(events) != 0 {
+				t.Fatalf("block %d: event for an in-turn block: %+v", number, <-events)
+			}
+			continue
+		}
+		// The blocks of the local validator are sealed out-of-turn by the other one
+		if !punish {
+			t.Fatalf("block %d: missed block not punished", number)
+		}
+		if err := engine.punishValidator(val, chain, header, statedb); err != nil {
+			t.Fatalf("block %d: failed to punish: %v", number, err)
+		}
+		if err := engine.monitor.update(header); err != nil {
+			t.Fatalf("block %d: failed to update monitor: %v", number, err)
+		}
+		missed++
+
+		// The counter is reset once the validator gets jailed
+		counter = uint64(missed % 48)
+		prev := threshold
+		switch {
+		case counter >= 39:
+			threshold = 80
+		case counter >= 24:
+			threshold = 50
+		default:
+			threshold = 0
+		}
+		expect(counter, threshold, threshold > prev)
+	}
+	// The epoch decrease lowers the counter by removeThreshold / decreaseRate, below
+	// the alert threshold which is crossed again by the next missed blocks
+	header.Number = new(big.Int).SetUint64(config.Congress.Epoch)
+	if err := engine.decreaseMissedBlocksCounter(chain, header, statedb); err != nil {
+		t.Fatalf("failed to decrease counter: %v", err)
+	}
+	if err := engine.monitor.update(header); err != nil {
+		t.Fatalf("failed to update monitor: %v", err)
+	}
+	expect(counter-2, 0, false)
+
+	for i := 0; i < 2; i++ {
+		header.Number = new(big.Int).Add(header.Number, common.Big1)
+		if err := engine.punishValidator(local, chain, header, statedb); err != nil {
+			t.Fatalf("failed to punish: %v", err)
+		}
+	}
+	if err := engine.monitor.update(header); err != nil {
+		t.Fatalf("failed to update monitor: %v", err)
+	}
+	expect(counter, 50, true)
+
+	select {
+	case have := <-alerts:
+		t.Fatalf("unexpected alert for threshold %d", have)
+	default:
+	}
+}
@@ -0,0 +1,323 @@
+package congress
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxProposalScanBlocks is the max number of blocks the proposal watcher scans for
+// committed proposals when the head moves by more than one block.
+const maxProposalScanBlocks = 128
+
+// proposalCommittedTopic is the topic of the event emitted by the governance contract
+// when a proposal is committed.
+var proposalCommittedTopic = crypto.Keccak256Hash([]byte("ProposalCommitted(uint256)"))
+
+// ProposalStatus is the stage of its lifecycle a system governance proposal is at.
+type ProposalStatus string
+
+const (
+	ProposalCreated  ProposalStatus = "created"  // Committed to the governance contract
+	ProposalPassed   ProposalStatus = "passed"   // Waiting to be executed by the next block
+	ProposalExecuted ProposalStatus = "executed" // Executed by a successful system transaction
+	ProposalFailed   ProposalStatus = "failed"   // Executed by a failed system transaction
+)
+
+// ProposalInfo is a system governance proposal along with its status.
+type ProposalInfo struct {
+	Id     *hexutil.Big   `json:"id"`
+	Action *hexutil.Big   `json:"action,omitempty"` // 0 for an EVM call, 1 to erase the code of To, unknown for created proposals not passed yet
+	From   common.Address `json:"from"`
+	To     common.Address `json:"to"`
+	Value  *hexutil.Big   `json:"value,omitempty"`
+	Data   hexutil.Bytes  `json:"data,omitempty"`
+	Status ProposalStatus `json:"status"`
+
+	Number        uint64          `json:"number"`                  // Block the status was observed at
+	Hash          *common.Hash    `json:"hash,omitempty"`          // Hash of the block, once sealed
+	Tx            *common.Hash    `json:"tx,omitempty"`            // Transaction committing or executing the proposal
+	ReceiptStatus *hexutil.Uint64 `json:"receiptStatus,omitempty"` // Status of the receipt of the executing system transaction
+	GasUsed       *hexutil.Uint64 `json:"gasUsed,omitempty"`       // Gas used by the proposal call, which isn't charged
+}
+
+// storedProposalExecution is a proposal execution along with the seal hash of the
+// block executing it, to tell whether the execution is canonical.
+type storedProposalExecution struct {
+	*ProposalInfo
+	SealHash common.Hash `json:"sealHash"`
+}
+
+func newProposalInfo(prop *Proposal, status ProposalStatus, number uint64) *ProposalInfo {
+	return &ProposalInfo{
+		Id:     (*hexutil.Big)(new(big.Int).Set(prop.Id)),
+		Action: (*hexutil.Big)(new(big.Int).Set(prop.Action)),
+		From:   prop.From,
+		To:     prop.To,
+		Value:  (*hexutil.Big)(new(big.Int).Set(prop.Value)),
+		Data:   common.CopyBytes(prop.Data),
+		Status: status,
+		Number: number,
+	}
+}
+
+// newProposalExecution reports the execution of a proposal by a system transaction,
+// along with the gas used by its call.
+func newProposalExecution(prop *Proposal, header *types.Header, receipt *types.Receipt, usedGas uint64) *ProposalInfo {
+	status := ProposalExecuted
+	if receipt.Status == types.ReceiptStatusFailed {
+		status = ProposalFailed
+	}
+	info := newProposalInfo(prop, status, header.Number.Uint64())
+	tx, receiptStatus, gasUsed := receipt.TxHash, hexutil.Uint64(receipt.Status), hexutil.Uint64(usedGas)
+	info.Tx, info.ReceiptStatus, info.GasUsed = &tx, &receiptStatus, &gasUsed
+	return info
+}
+
+// storeProposalExecutions persists the proposals executed by a canonical block.
+func storeProposalExecutions(db ethdb.KeyValueWriter, header *types.Header, executions []*ProposalInfo) error {
+	sealHash := SealHash(header)
+	for _, info := range executions {
+		blob, err := json.Marshal(&storedProposalExecution{ProposalInfo: info, SealHash: sealHash})
+		if err != nil {
+			return err
+		}
+		rawdb.WriteCongressProposal(db, info.Id.ToInt(), blob)
+	}
+	return nil
+}
+
+// processedExecutions returns the proposals executed by a processed block which may
+// not be indexed yet, keyed by id.
+func (c *Congress) processedExecutions(header *types.Header) map[string]*ProposalInfo {
+	data := c.processedBlock(header)
+	if data == nil {
+		return nil
+	}
+	executions := make(map[string]*ProposalInfo, len(data.executions))
+	for _, info := range data.executions {
+		cpy := *info
+		hash := header.Hash()
+		cpy.Hash = &hash
+		executions[info.Id.String()] = &cpy
+	}
+	return executions
+}
+
+// proposalExecution retrieves the execution of a proposal, nil if the proposal
+// wasn't executed by a canonical block.
+func (c *Congress) proposalExecution(id *big.Int) (*ProposalInfo, error) {
+	blob := rawdb.ReadCongressProposal(c.db, id)
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	stored := new(storedProposalExecution)
+	if err := json.Unmarshal(blob, stored); err != nil {
+		return nil, err
+	}
+	if stored.ProposalInfo == nil {
+		return nil, errors.New("invalid proposal execution")
+	}
+	header := c.chain.GetHeaderByNumber(stored.Number)
+	if header == nil || SealHash(header) != stored.SealHash {
+		return nil, nil
+	}
+	hash := header.Hash()
+	stored.Hash = &hash
+	return stored.ProposalInfo, nil
+}
+
+// pendingProposals returns the passed proposals waiting to be executed by the block
+// following the given header.
+func (c *Congress) pendingProposals(header *types.Header) ([]*ProposalInfo, error) {
+	pending := make([]*ProposalInfo, 0)
+	if !c.chainConfig.IsRedCoast(header.Number) {
+		return pending, nil
+	}
+	statedb, err := c.stateFn(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	count, err := c.getPassedProposalCount(c.chain, header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	hash := header.Hash()
+	for i := uint32(0); i < count; i++ {
+		prop, err := c.getPassedProposalByIndex(c.chain, header, statedb, i)
+		if err != nil {
+			return nil, err
+		}
+		info := newProposalInfo(prop, ProposalPassed, header.Number.Uint64())
+		info.Hash = &hash
+		pending = append(pending, info)
+	}
+	return pending, nil
+}
+
+// committedProposalId returns the id of the proposal committed by a log of the
+// governance contract, nil if the log isn't a commit.
+func committedProposalId(l *types.Log) *big.Int {
+	if l.Address != systemcontract.SysGovContractAddr || len(l.Topics) == 0 || l.Topics[0] != proposalCommittedTopic {
+		return nil
+	}
+	if len(l.Topics) > 1 {
+		return new(big.Int).SetBytes(l.Topics[1][:])
+	}
+	if len(l.Data) >= common.HashLength {
+		return new(big.Int).SetBytes(l.Data[:common.HashLength])
+	}
+	return nil
+}
+
+// proposalWatcher reports the lifecycle of the system governance proposals on every
+// new chain head.
+type proposalWatcher struct {
+	congress *Congress
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+
+	number  uint64                   // Number of the last head
+	pending map[string]*ProposalInfo // Proposals passed at the last head keyed by id, nil before the first head
+
+	quit chan struct{}
+}
+
+// StartProposalWatcher starts reporting the lifecycle of the system governance
+// proposals on every new chain head.
+func (c *Congress) StartProposalWatcher(chain chainHeadSubscriber) {
+	c.proposalWatcher = &proposalWatcher{
+		congress: c,
+		quit:     make(chan struct{}),
+	}
+	go c.proposalWatcher.loop(chain)
+}
+
+// SubscribeProposals registers a subscription of the proposal lifecycle events.
+func (c *Congress) SubscribeProposals(ch chan<- *ProposalInfo) (event.Subscription, error) {
+	if c.proposalWatcher == nil {
+		return nil, errors.New("proposal watcher not running")
+	}
+	return c.proposalWatcher.scope.Track(c.proposalWatcher.feed.Subscribe(ch)), nil
+}
+
+func (w *proposalWatcher) loop(chain chainHeadSubscriber) {
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	sub := chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-headCh:
+			if err := w.update(ev.Block.Header()); err != nil {
+				log.Debug("Failed to check governance proposals", "number", ev.Block.Number(), "err", err)
+			}
+		case <-sub.Err():
+			return
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+func (w *proposalWatcher) stop() {
+	w.scope.Close()
+	close(w.quit)
+}
+
+// update reports the proposals committed since the last head, the proposals which
+// passed and the ones which were executed.
+func (w *proposalWatcher) update(header *types.Header) error {
+	c := w.congress
+	number := header.Number.Uint64()
+	if !c.chainConfig.IsRedCoast(header.Number) {
+		w.number = number
+		return nil
+	}
+	pending, err := c.pendingProposals(header)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]*ProposalInfo, len(pending))
+	for _, info := range pending {
+		current[info.Id.String()] = info
+	}
+	var (
+		events     []*ProposalInfo
+		executions = make(map[string]*ProposalInfo) // Executions of the scanned blocks, as the block indexer may lag behind
+	)
+	// Scan the blocks since the last head for committed and executed proposals
+	start := w.number + 1
+	if w.pending == nil || start > number || number-start >= maxProposalScanBlocks {
+		start = number
+	}
+	for n := start; n <= number; n++ {
+		hash := rawdb.ReadCanonicalHash(c.db, n)
+		if block := rawdb.ReadHeader(c.db, hash, n); block != nil {
+			for id, info := range c.processedExecutions(block) {
+				executions[id] = info
+			}
+		}
+		for _, receipt := range rawdb.ReadReceipts(c.db, hash, n, c.chainConfig) {
+			for _, l := range receipt.Logs {
+				id := committedProposalId(l)
+				if id == nil {
+					continue
+				}
+				created := &ProposalInfo{Id: (*hexutil.Big)(id)}
+				if info, ok := current[created.Id.String()]; ok {
+					cpy := *info
+					created = &cpy
+				}
+				created.Status, created.Number = ProposalCreated, n
+				blockHash, txHash := hash, l.TxHash
+				created.Hash, created.Tx = &blockHash, &txHash
+				events = append(events, created)
+			}
+		}
+	}
+	// Report the newly passed proposals, and the execution of the ones gone
+	if w.pending != nil {
+		for _, info := range pending {
+			if _, ok := w.pending[info.Id.String()]; !ok {
+				events = append(events, info)
+			}
+		}
+		for id, info := range w.pending {
+			if _, ok := current[id]; ok {
+				continue
+			}
+			if execution, ok := executions[id]; ok {
+				events = append(events, execution)
+				continue
+			}
+			execution, err := c.proposalExecution(info.Id.ToInt())
+			if err != nil {
+				log.Debug("Failed to load proposal execution", "id", id, "err", err)
+				continue
+			}
+			if execution != nil {
+				events = append(events, execution)
+			}
+		}
+	}
+	w.number, w.pending = number, current
+
+	for _, ev := range events {
+		log.Debug("Governance proposal updated", "id", ev.Id, "status", ev.Status, "number", ev.Number)
+		w.feed.Send(ev)
+	}
+	return nil
+}
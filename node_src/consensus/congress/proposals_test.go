@@ -0,0 +1,137 @@
+package congress
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that proposal executions are only reported once executed by a canonical
+// block, and that the executions of the processed blocks are available before the
+// blocks get indexed, including the blocks assembled locally once sealed.
+func TestProposalExecutions(t *testing.T) {
+	var (
+		engine   = New(params.AllCongressProtocolChanges, rawdb.NewMemoryDatabase())
+		chain    = &testHeaderReader{config: params.AllCongressProtocolChanges, headers: make(map[common.Hash]*types.Header)}
+		prop     = &Proposal{Id: big.NewInt(7), Action: big.NewInt(0), To: common.HexToAddress("0xa"), Value: new(big.Int), Data: []byte{0x1}}
+		imported = &types.Header{Number: big.NewInt(10), Extra: make([]byte, extraVanity+extraSeal)}
+		mined    = &types.Header{Number: big.NewInt(11), Extra: make([]byte, extraVanity+extraSeal)}
+	)
+	engine.SetChain(chain)
+
+	receipt := types.NewReceipt(nil, true, 0)
+	receipt.TxHash = common.HexToHash("0x1")
+	if err := storeProposalExecutions(engine.db, imported, []*ProposalInfo{newProposalExecution(prop, imported, receipt, 21000)}); err != nil {
+		t.Fatalf("failed to store execution: %v", err)
+	}
+
+	// Executions of blocks off the canonical chain are ignored
+	if info, err := engine.proposalExecution(prop.Id); err != nil || info != nil {
+		t.Fatalf("non-canonical execution reported: %v, %v", info, err)
+	}
+	chain.headers[imported.Hash()] = imported
+	info, err := engine.proposalExecution(prop.Id)
+	if err != nil || info == nil {
+		t.Fatalf("failed to load execution: %v", err)
+	}
+	if info.Status != ProposalFailed || uint64(*info.GasUsed) != 21000 || *info.Tx != receipt.TxHash || *info.Hash != imported.Hash() {
+		t.Fatalf("execution mismatch: %+v", info)
+	}
+	// Executions of locally assembled blocks are available once sealed, before being indexed
+	prop.Id = big.NewInt(8)
+	receipt = types.NewReceipt(nil, false, 0)
+	executions := []*ProposalInfo{newProposalExecution(prop, mined, receipt, 21000)}
+	engine.addBlockData(mined, &blockData{executions: executions})
+	copy(mined.Extra[len(mined.Extra)-extraSeal:], common.FromHex("0xff"))
+	chain.headers[mined.Hash()] = mined
+
+	if info, err := engine.proposalExecution(prop.Id); err != nil || info != nil {
+		t.Fatalf("unindexed execution stored: %v, %v", info, err)
+	}
+	processed := engine.processedExecutions(mined)[(*hexutil.Big)(prop.Id).String()]
+	if processed == nil || processed.Status != ProposalExecuted || *processed.Hash != mined.Hash() {
+		t.Fatalf("processed execution mismatch: %+v", processed)
+	}
+	if err := storeProposalExecutions(engine.db, mined, executions); err != nil {
+		t.Fatalf("failed to store execution: %v", err)
+	}
+	if info, err := engine.proposalExecution(prop.Id); err != nil || info == nil || info.Status != ProposalExecuted {
+		t.Fatalf("sealed execution mismatch: %+v, %v", info, err)
+	}
+}
+
+func TestCommittedProposalId(t *testing.T) {
+	id := common.BigToHash(big.NewInt(3))
+	tests := []struct {
+		log  *types.Log
+		want *big.Int
+	}{
+		{&types.Log{Address: systemcontract.SysGovContractAddr, Topics: []common.Hash{proposalCommittedTopic, id}}, big.NewInt(3)},
+		{&types.Log{Address: systemcontract.SysGovContractAddr, Topics: []common.Hash{proposalCommittedTopic}, Data: id[:]}, big.NewInt(3)},
+		{&types.Log{Address: systemcontract.SysGovContractAddr, Topics: []common.Hash{id}, Data: id[:]}, nil},
+		{&types.Log{Address: common.HexToAddress("0xa"), Topics: []common.Hash{proposalCommittedTopic, id}}, nil},
+	}
+	for i, tt := range tests {
+		have := committedProposalId(tt.log)
+		if (have == nil) != (tt.want == nil) || (have != nil && have.Cmp(tt.want) != 0) {
+			t.Errorf("test %d: id mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that system governance transactions are rejected with the first field
+// of the carried proposal that differs from the passed one.
+func TestVerifyProposalTx(t *testing.T) {
+	prop := &Proposal{Id: big.NewInt(7), Action: big.NewInt(0), From: common.HexToAddress("0xf"), To: common.HexToAddress("0xa"), Value: big.NewInt(1), Data: []byte{0x1}}
+	newTx := func(to common.Address, mutate func(p *Proposal)) *types.Transaction {
+		cpy := *prop
+		if mutate != nil {
+			mutate(&cpy)
+		}
+		data, err := rlp.EncodeToBytes(&cpy)
+		if err != nil {
+			t.Fatalf("failed to encode proposal: %v", err)
+		}
+		return types.NewTransaction(0, to, new(big.Int), 0, new(big.Int), data)
+	}
+	if err := verifyProposalTx(prop, newTx(systemcontract.SysGovToAddr, nil)); err != nil {
+		t.Fatalf("failed to verify matching proposal: %v", err)
+	}
+	if err := verifyProposalTx(prop, newTx(common.HexToAddress("0xb"), nil)); !errors.Is(err, errInvalidSysGovTarget) {
+		t.Errorf("target error mismatch: have %v, want %v", err, errInvalidSysGovTarget)
+	}
+	garbage := types.NewTransaction(0, systemcontract.SysGovToAddr, new(big.Int), 0, new(big.Int), []byte{0x01, 0x02})
+	if err := verifyProposalTx(prop, garbage); !errors.Is(err, errInvalidSysGovData) {
+		t.Errorf("data error mismatch: have %v, want %v", err, errInvalidSysGovData)
+	}
+	tests := []struct {
+		field  string
+		mutate func(p *Proposal)
+	}{
+		{"id", func(p *Proposal) { p.Id = big.NewInt(8) }},
+		{"action", func(p *Proposal) { p.Action = big.NewInt(1) }},
+		{"from", func(p *Proposal) { p.From = common.HexToAddress("0xe") }},
+		{"to", func(p *Proposal) { p.To = common.HexToAddress("0xb") }},
+		{"value", func(p *Proposal) { p.Value = big.NewInt(2) }},
+		{"data", func(p *Proposal) { p.Data = []byte{0x2} }},
+	}
+	for _, tt := range tests {
+		err := verifyProposalTx(prop, newTx(systemcontract.SysGovToAddr, tt.mutate))
+		if !errors.Is(err, errSysGovProposalMismatch) {
+			t.Errorf("%s: error mismatch: have %v, want %v", tt.field, err, errSysGovProposalMismatch)
+			continue
+		}
+		var mismatch *proposalMismatchError
+		if !errors.As(err, &mismatch) || mismatch.Field != tt.field {
+			t.Errorf("%s: mismatching field reported: %v", tt.field, err)
+		}
+	}
+}
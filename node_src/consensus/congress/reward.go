@@ -0,0 +1,208 @@
+package congress
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// errInvalidRewardReceipts is returned if the receipts don't match the transactions
+// the block fee is shared among.
+var errInvalidRewardReceipts = errors.New("receipts mismatch block reward transactions")
+
+var rewardPrefix = []byte("congress-reward-") // rewardPrefix + seal hash -> block reward report
+
+// RewardShare is the part of the block fee assigned to the recipient of a transaction.
+type RewardShare struct {
+	Tx        common.Hash    `json:"tx"`
+	Recipient common.Address `json:"recipient"` // Recipient contract of the transaction, zero for contract creations
+	Computed  *hexutil.Big   `json:"computed"`  // Share computed from the transaction gas, gas used times effective tip after FeeShareBlock
+	Share     *hexutil.Big   `json:"share"`     // Share passed to distributeBlockReward after scaling
+}
+
+// BlockRewardReport is the breakdown of the fee distribution done by distributeBlockReward
+// in a block.
+type BlockRewardReport struct {
+	Number        uint64         `json:"number"`
+	Coinbase      common.Address `json:"coinbase"`
+	Fee           *hexutil.Big   `json:"fee"`                 // Fees collected in the block, credited to the coinbase and sent along the call
+	TotalComputed *hexutil.Big   `json:"totalComputed"`       // Sum of the computed shares before scaling
+	Scaling       float64        `json:"scalingPercent"`      // Percentage every share was decreased by to fit in the fee
+	Remainder     *hexutil.Big   `json:"remainder,omitempty"` // Wei left by the integer scaling and handed out one by one, after FeeShareBlock
+	Shares        []*RewardShare `json:"shares"`
+	Error         string         `json:"error,omitempty"` // Failure of the distributeBlockReward call, if any
+}
+
+func rewardKey(sealHash common.Hash) []byte {
+	return append(append([]byte{}, rewardPrefix...), sealHash[:]...)
+}
+
+func loadRewardReport(db ethdb.KeyValueReader, sealHash common.Hash) (*BlockRewardReport, error) {
+	blob, err := db.Get(rewardKey(sealHash))
+	if err != nil {
+		return nil, err
+	}
+	report := new(BlockRewardReport)
+	if err := json.Unmarshal(blob, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *BlockRewardReport) store(db ethdb.KeyValueWriter, sealHash common.Hash) error {
+	blob, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return db.Put(rewardKey(sealHash), blob)
+}
+
+// calcFeeShares computes the share of the block fee of every transaction recipient,
+// scaled down proportionally if they exceed the collected fee.
+func calcFeeShares(txs []*types.Transaction, fee *big.Int) ([]common.Address, []uint64, *BlockRewardReport) {
+	var (
+		addr        []common.Address
+		gass        []uint64
+		totalGasSum uint64
+		report      = &BlockRewardReport{Fee: (*hexutil.Big)(new(big.Int).Set(fee))}
+	)
+	for _, tx := range txs {
+		to := common.Address{}
+		if tx.To() != nil {
+			to = *tx.To()
+		}
+		gasFee := tx.Gas() * tx.GasPrice().Uint64()
+
+		addr = append(addr, to)
+		gass = append(gass, gasFee)
+		totalGasSum += gasFee
+		report.Shares = append(report.Shares, &RewardShare{
+			Tx:        tx.Hash(),
+			Recipient: to,
+			Computed:  (*hexutil.Big)(new(big.Int).SetUint64(gasFee)),
+		})
+	}
+	feeUint64 := fee.Uint64()
+	if totalGasSum > feeUint64 {
+		percentDifference := float64(totalGasSum-feeUint64) / float64(totalGasSum) * 100
+
+		for i := 0; i < len(gass); i++ {
+			decreaseAmount := uint64(float64(gass[i]) * (percentDifference / 100.0))
+			gass[i] -= decreaseAmount
+		}
+		report.Scaling = percentDifference
+	}
+	for i, share := range report.Shares {
+		share.Share = (*hexutil.Big)(new(big.Int).SetUint64(gass[i]))
+	}
+	report.TotalComputed = (*hexutil.Big)(new(big.Int).SetUint64(totalGasSum))
+	return addr, gass, report
+}
+
+// calcExactFeeShares computes the share of the block fee of every transaction recipient
+// as the gas used by the transaction times its effective tip, which is exactly what the
+// transaction paid into the fee recorder.
+//
+// If the shares exceed the collected fee, every share is scaled down to
+// share * fee / total, rounding down, and the wei left over are handed out one by one
+// to the non-empty shares in transaction order, so that the shares add up to the fee.
+// The Validators contract takes uint64 shares, so any share above that is capped.
+func calcExactFeeShares(txs []*types.Transaction, receipts []*types.Receipt, baseFee *big.Int, fee *big.Int) ([]common.Address, []uint64, *BlockRewardReport) {
+	var (
+		addr   = make([]common.Address, len(txs))
+		gass   = make([]uint64, len(txs))
+		shares = make([]*big.Int, len(txs))
+		total  = new(big.Int)
+		report = &BlockRewardReport{Fee: (*hexutil.Big)(new(big.Int).Set(fee))}
+	)
+	for i, tx := range txs {
+		if tx.To() != nil {
+			addr[i] = *tx.To()
+		}
+		shares[i] = new(big.Int)
+		if tip, err := tx.EffectiveGasTip(baseFee); err == nil {
+			shares[i].Mul(new(big.Int).SetUint64(receipts[i].GasUsed), tip)
+		}
+		total.Add(total, shares[i])
+		report.Shares = append(report.Shares, &RewardShare{
+			Tx:        tx.Hash(),
+			Recipient: addr[i],
+			Computed:  (*hexutil.Big)(new(big.Int).Set(shares[i])),
+		})
+	}
+	report.TotalComputed = (*hexutil.Big)(new(big.Int).Set(total))
+
+	if total.Cmp(fee) > 0 {
+		distributed := new(big.Int)
+		for _, share := range shares {
+			share.Mul(share, fee)
+			share.Quo(share, total)
+			distributed.Add(distributed, share)
+		}
+		remainder := new(big.Int).Sub(fee, distributed)
+		report.Remainder = (*hexutil.Big)(new(big.Int).Set(remainder))
+		for i := 0; remainder.Sign() > 0 && i < len(shares); i++ {
+			if report.Shares[i].Computed.ToInt().Sign() > 0 {
+				shares[i].Add(shares[i], common.Big1)
+				remainder.Sub(remainder, common.Big1)
+			}
+		}
+		scaling, _ := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Sub(total, fee)), new(big.Float).SetInt(total)).Float64()
+		report.Scaling = scaling * 100
+	}
+	for i, share := range shares {
+		if share.IsUint64() {
+			gass[i] = share.Uint64()
+		} else {
+			gass[i] = math.MaxUint64
+		}
+		report.Shares[i].Share = (*hexutil.Big)(new(big.Int).SetUint64(gass[i]))
+	}
+	return addr, gass, report
+}
+
+// distributeBlockReward shares the fee collected in the block among the recipients of
+// its transactions and reports how it was split. It's shared by Finalize and
+// FinalizeAndAssemble, so both must pass the receipts of the transactions only.
+func (c *Congress) distributeBlockReward(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, receipts []*types.Receipt) (*BlockRewardReport, error) {
+	var (
+		addr   []common.Address
+		gass   []uint64
+		report *BlockRewardReport
+		fee    = state.GetBalance(consensus.FeeRecoder)
+	)
+	if c.chainConfig.IsFeeShare(header.Number) {
+		if len(receipts) != len(txs) {
+			return nil, errInvalidRewardReceipts
+		}
+		addr, gass, report = calcExactFeeShares(txs, receipts, header.BaseFee, fee)
+	} else {
+		addr, gass, report = calcFeeShares(txs, fee)
+	}
+	report.Number = header.Number.Uint64()
+	report.Coinbase = header.Coinbase
+
+	if err := c.trySendBlockReward(chain, header, state, addr, gass); err != nil {
+		log.Info(err.Error())
+		report.Error = err.Error()
+	}
+	return report, nil
+}
+
+// rewardReport retrieves the reward report of a block, nil if no fee was distributed.
+func (c *Congress) rewardReport(header *types.Header) (*BlockRewardReport, error) {
+	sealHash := SealHash(header)
+	if has, err := c.db.Has(rewardKey(sealHash)); err != nil || !has {
+		return nil, err
+	}
+	return loadRewardReport(c.db, sealHash)
+}
@@ -0,0 +1,201 @@
+package congress
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testHeaderReader is a chain of headers kept in memory.
+type testHeaderReader struct {
+	config  *params.ChainConfig
+	headers map[common.Hash]*types.Header
+}
+
+func (r *testHeaderReader) Config() *params.ChainConfig  { return r.config }
+func (r *testHeaderReader) CurrentHeader() *types.Header { return nil }
+func (r *testHeaderReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return r.headers[hash]
+}
+func (r *testHeaderReader) GetHeaderByNumber(number uint64) *types.Header {
+	for _, header := range r.headers {
+		if header.Number.Uint64() == number {
+			return header
+		}
+	}
+	return nil
+}
+func (r *testHeaderReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	return r.headers[hash]
+}
+
+var (
+	feeShareA = common.HexToAddress("0xa")
+	feeShareB = common.HexToAddress("0xb")
+)
+
+func feeShareTxs() ([]*types.Transaction, []*types.Receipt) {
+	txs := []*types.Transaction{
+		types.NewTransaction(0, feeShareA, nil, 100000, big.NewInt(10), nil),
+		types.NewTx(&types.DynamicFeeTx{Nonce: 1, To: &feeShareB, Gas: 100000, GasTipCap: big.NewInt(3), GasFeeCap: big.NewInt(20)}),
+		types.NewContractCreation(2, nil, 100000, big.NewInt(7), nil),
+	}
+	receipts := []*types.Receipt{{GasUsed: 21000}, {GasUsed: 50000}, {GasUsed: 1000}}
+	return txs, receipts
+}
+
+func TestExactFeeShares(t *testing.T) {
+	txs, receipts := feeShareTxs()
+
+	// Shares are the gas used times the effective tip: 21000*(10-5), 50000*3 and 1000*(7-5)
+	addr, gass, report := calcExactFeeShares(txs, receipts, big.NewInt(5), big.NewInt(1000000))
+	if addr[0] != feeShareA || addr[1] != feeShareB || addr[2] != (common.Address{}) {
+		t.Fatalf("recipients mismatch: %v", addr)
+	}
+	if want := []uint64{105000, 150000, 2000}; gass[0] != want[0] || gass[1] != want[1] || gass[2] != want[2] {
+		t.Fatalf("unscaled shares mismatch: have %v, want %v", gass, want)
+	}
+	if report.Remainder != nil || report.Scaling != 0 {
+		t.Fatalf("unexpected scaling: %v%%, remainder %v", report.Scaling, report.Remainder)
+	}
+
+	// Scale 210000, 150000 and 7000 down to a fee which doesn't divide evenly among them
+	fee := big.NewInt(100001)
+	_, gass, report = calcExactFeeShares(txs, receipts, nil, fee)
+	if want := []uint64{57221 + 1, 40872, 1907}; gass[0] != want[0] || gass[1] != want[1] || gass[2] != want[2] {
+		t.Fatalf("scaled shares mismatch: have %v, want %v", gass, want)
+	}
+	var sum uint64
+	for _, share := range gass {
+		sum += share
+	}
+	if sum != fee.Uint64() {
+		t.Fatalf("scaled shares don't add up to the fee: have %d, want %d", sum, fee)
+	}
+	if report.TotalComputed.ToInt().Uint64() != 367000 || report.Remainder.ToInt().Uint64() != 1 {
+		t.Fatalf("report mismatch: total %v, remainder %v", report.TotalComputed, report.Remainder)
+	}
+}
+
+// Tests that importing a block and assembling it locally share the fees the same way.
+func TestFinalizeFeeShareConsistency(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.LondonBlock, config.RedCoastBlock, config.SophonBlock = nil, nil, nil
+	config.FeeShareBlock = big.NewInt(1)
+
+	parent := &types.Header{Number: big.NewInt(1), GasLimit: 8000000}
+	chain := &testHeaderReader{config: &config, headers: map[common.Hash]*types.Header{parent.Hash(): parent}}
+
+	txs, receipts := feeShareTxs()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(consensus.FeeRecoder, big.NewInt(100001))
+
+	newHeader := func() *types.Header {
+		return &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(2),
+			Coinbase:   common.HexToAddress("0xc0ffee"),
+			Difficulty: new(big.Int).Set(diffInTurn),
+			GasLimit:   parent.GasLimit,
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+	}
+	// Import path
+	imported := New(&config, rawdb.NewMemoryDatabase())
+	header := newHeader()
+	importTxs, importReceipts := append([]*types.Transaction{}, txs...), append([]*types.Receipt{}, receipts...)
+	if err := imported.Finalize(chain, header, statedb.Copy(), &importTxs, nil, &importReceipts, nil); err != nil {
+		t.Fatalf("failed to finalize block: %v", err)
+	}
+	// Mining path
+	mined := New(&config, rawdb.NewMemoryDatabase())
+	block, _, err := mined.FinalizeAndAssemble(chain, newHeader(), statedb.Copy(), txs, nil, receipts)
+	if err != nil {
+		t.Fatalf("failed to assemble block: %v", err)
+	}
+	if block.Root() != header.Root {
+		t.Fatalf("state root mismatch: mined %x, imported %x", block.Root(), header.Root)
+	}
+	importedData := imported.processedBlock(header)
+	if importedData == nil || importedData.reward == nil {
+		t.Fatalf("missing imported reward report")
+	}
+	minedData := mined.processedBlock(block.Header())
+	if minedData == nil || minedData.reward == nil {
+		t.Fatalf("missing mined reward report")
+	}
+	have, _ := json.Marshal(importedData.reward)
+	want, _ := json.Marshal(minedData.reward)
+	if string(have) != string(want) {
+		t.Fatalf("reward report mismatch:\nimported %s\nmined    %s", have, want)
+	}
+}
+
+// Tests that the reward reports are persisted once their blocks turn canonical, served
+// by the block breakdown API, and dropped along the blocks reorged away.
+func TestRewardReportIndex(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.Congress = &params.CongressConfig{Period: 3, Epoch: 4}
+
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	blocks, _ := activityTestChain(&config, 3, nil)
+	chain := &canonicalReader{headReader: blocks, canonical: make(map[uint64]common.Hash)}
+	for _, header := range blocks.headers {
+		chain.canonical[header.Number.Uint64()] = header.Hash()
+	}
+	engine.SetChain(chain)
+	api := &API{chain: chain, congress: engine}
+
+	txs, receipts := feeShareTxs()
+	_, _, report := calcExactFeeShares(txs, receipts, big.NewInt(5), big.NewInt(1000000))
+	report.Number = 2
+
+	// A processed block doesn't expose its report before being indexed
+	block := chain.GetHeaderByNumber(2)
+	engine.addBlockData(block, &blockData{reward: report})
+	if have, err := api.GetBlockRewardBreakdown(2); err != nil || have != nil {
+		t.Fatalf("unindexed report mismatch: have %v, %v, want nil", have, err)
+	}
+	indexer := &blockIndexer{congress: engine}
+	indexer.update(chain.head)
+
+	have, err := api.GetBlockRewardBreakdown(2)
+	if err != nil || have == nil {
+		t.Fatalf("failed to retrieve reward report: %v", err)
+	}
+	haveBlob, _ := json.Marshal(have)
+	wantBlob, _ := json.Marshal(report)
+	if string(haveBlob) != string(wantBlob) {
+		t.Fatalf("reward report mismatch:\nhave %s\nwant %s", haveBlob, wantBlob)
+	}
+	if have, err := api.GetBlockRewardBreakdown(3); err != nil || have != nil {
+		t.Fatalf("block without fees report mismatch: have %v, %v, want nil", have, err)
+	}
+	// Reorg the blocks 2 and 3 away in favour of a side block 2
+	side := &types.Header{
+		ParentHash: block.ParentHash,
+		Number:     big.NewInt(2),
+		Coinbase:   block.Coinbase,
+		Time:       1,
+		Difficulty: new(big.Int).Set(diffInTurn),
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+	chain.headers[side.Hash()], chain.head = side, side
+	chain.canonical[2] = side.Hash()
+	delete(chain.canonical, 3)
+	indexer.update(side)
+
+	if has, _ := engine.db.Has(rewardKey(SealHash(block))); has {
+		t.Fatalf("reorged block reward report not dropped")
+	}
+	if have, err := api.GetBlockRewardBreakdown(2); err != nil || have != nil {
+		t.Fatalf("side block report mismatch: have %v, %v, want nil", have, err)
+	}
+}
@@ -19,13 +19,16 @@ package congress
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
+	"math/big"
 	"sort"
   "errors"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 	lru "github.com/hashicorp/golang-lru"
@@ -36,10 +39,11 @@ type Snapshot struct {
 	config   *params.CongressConfig // Consensus engine parameters to fine tune behavior
 	sigcache *lru.ARCCache          // Cache of recent block signatures to speed up ecrecover
 
-	Number     uint64                      `json:"number"`     // Block number where the snapshot was created
-	Hash       common.Hash                 `json:"hash"`       // Block hash where the snapshot was created
-	Validators map[common.Address]struct{} `json:"validators"` // Set of authorized validators at this moment
-	Recents    map[uint64]common.Address   `json:"recents"`    // Set of recent validators for spam protections
+	Number     uint64                      `json:"number"`              // Block number where the snapshot was created
+	Hash       common.Hash                 `json:"hash"`                // Block hash where the snapshot was created
+	Validators map[common.Address]struct{} `json:"validators"`          // Set of authorized validators at this moment
+	Recents    map[uint64]common.Address   `json:"recents"`             // Set of recent validators for spam protections
+	Proposers  []common.Address            `json:"proposers,omitempty"` // Order the validators take turns in after the shuffle fork, ascending if empty
 }
 
 // validatorsAscending implements the sort interface to allow sorting a list of addresses
@@ -108,6 +112,10 @@ func (s *Snapshot) copy() *Snapshot {
 	for block, validator := range s.Recents {
 		cpy.Recents[block] = validator
 	}
+	if s.Proposers != nil {
+		cpy.Proposers = make([]common.Address, len(s.Proposers))
+		copy(cpy.Proposers, s.Proposers)
+	}
 
 	return cpy
 }
@@ -133,13 +141,16 @@ func (s *Snapshot) apply(headers []*types.Header, chain consensus.ChainHeaderRea
 		// Remove any votes on checkpoint blocks
 		number := header.Number.Uint64()
 		// Delete the oldest validator from the recent list to allow it signing again
-		var limit uint64
-		if len(snap.Validators) > 21 || len(snap.Validators) == 1 {
-			limit = uint64(len(snap.Validators)/2 + 1)
-		} else { //if number > 9299500 {
-			limit = 2
-		}
-		if number >= limit {
+		limit := recentsLimit(s.config, number, len(snap.Validators))
+		if s.config.IsValidatorsLimit(header.Number) {
+			// Keep the validators of the last limit-1 blocks only, the same as Seal and
+			// verifySeal expect, also dropping the older ones if the limit decreased.
+			for block := range snap.Recents {
+				if block+limit <= number {
+					delete(snap.Recents, block)
+				}
+			}
+		} else if number >= limit {
 			for i := uint64(0); i < limit; i++ {
 				delete(snap.Recents, number-limit+i)
 			}
@@ -173,19 +184,21 @@ func (s *Snapshot) apply(headers []*types.Header, chain consensus.ChainHeaderRea
 			for _, validator := range validators {
 				newValidators[validator] = struct{}{}
 			}
+			if s.config.IsValidatorsLimit(header.Number) && (len(newValidators) == 0 || len(newValidators) > validatorsLimit(s.config, number)) {
+				return nil, errInvalidValidatorsLength
+			}
 
 			// Need to delete recorded recent seen blocks if necessary, it may pause whole chain when validators length decreases.
-			var epochLimit uint64      
-			if len(newValidators) > 21 || len(newValidators) == 1 {
-				epochLimit = uint64(len(newValidators)/2 + 1)
-			} else { //if number > 9299500 {
-				epochLimit = 2
-			} 
-			for i := 0; i < len(snap.Validators)/2-len(newValidators)/2; i++ {
-				delete(snap.Recents, number-epochLimit-uint64(i))
+			// After the validators limit fork, they are dropped with the limit of the next block.
+			if !s.config.IsValidatorsLimit(header.Number) {
+				epochLimit := recentsLimit(s.config, number, len(newValidators))
+				for i := 0; i < len(snap.Validators)/2-len(newValidators)/2; i++ {
+					delete(snap.Recents, number-epochLimit-uint64(i))
+				}
 			}
 
 			snap.Validators = newValidators
+			snap.updateProposers(header)
 		}
 	}
 
@@ -206,11 +219,89 @@ func (s *Snapshot) validators() []common.Address {
 	return sigs
 }
 
+// validatorsLimit returns the max number of validators allowed to seal at the given height.
+func validatorsLimit(config *params.CongressConfig, number uint64) int {
+	if config.IsValidatorsLimit(new(big.Int).SetUint64(number)) && config.MaxValidators > 0 {
+		return int(config.MaxValidators)
+	}
+	return maxValidators
+}
+
+// recentsLimit returns the number of consecutive blocks of which a validator may seal
+// only one at the given height, depending on the number of validators.
+func recentsLimit(config *params.CongressConfig, number uint64, validators int) uint64 {
+	if config.IsValidatorsLimit(new(big.Int).SetUint64(number)) {
+		limit := uint64(validators/2 + 1)
+		if config.RecentsLimit > 0 {
+			limit = config.RecentsLimit
+		}
+		// Nobody could seal with a limit above the number of validators
+		if limit > uint64(validators) {
+			limit = uint64(validators)
+		}
+		return limit
+	}
+	if validators > maxValidators || validators == 1 {
+		return uint64(validators/2 + 1)
+	}
+	return 2
+}
+
+// signedRecently reports whether a validator which sealed block seen is still among
+// the recents at the given height, so it may not seal it. Before the validators limit
+// fork the check underflows for the first blocks the same way the live chains did.
+func signedRecently(config *params.CongressConfig, number, seen, limit uint64) bool {
+	if config.IsValidatorsLimit(new(big.Int).SetUint64(number)) {
+		return seen+limit > number
+	}
+	return seen > number-limit
+}
+
+// proposers retrieves the list of authorized validators in the order they take turns.
+func (s *Snapshot) proposers() []common.Address {
+	if len(s.Proposers) > 0 {
+		return s.Proposers
+	}
+	return s.validators()
+}
+
+// updateProposers sets the proposer rotation of the epoch starting at the given
+// checkpoint header. After the shuffle fork, the validators take turns in an order
+// shuffled with the seed of the epoch, otherwise in ascending order.
+func (s *Snapshot) updateProposers(checkpoint *types.Header) {
+	s.Proposers = nil
+	if s.config.IsShuffle(checkpoint.Number) {
+		s.Proposers = shuffleValidators(s.validators(), shuffleSeed(s.config, checkpoint))
+	}
+}
+
+// shuffleSeed derives the shuffle seed of the epoch starting at the given checkpoint
+// from its parent hash and the epoch number. The seed isn't unbiasable: the sealer
+// of the parent block can grind its hash, e.g. through the timestamp or the vanity,
+// to pick among orders of the next epoch. The shuffle only spreads the turns of the
+// validators, it doesn't prevent a validator from influencing them.
+func shuffleSeed(config *params.CongressConfig, checkpoint *types.Header) common.Hash {
+	epoch := make([]byte, 8)
+	binary.BigEndian.PutUint64(epoch, checkpoint.Number.Uint64()/config.Epoch)
+	return crypto.Keccak256Hash(checkpoint.ParentHash[:], epoch)
+}
+
+// shuffleValidators shuffles the validators with the Fisher-Yates algorithm, drawing
+// the random numbers from the hash chain of the seed.
+func shuffleValidators(validators []common.Address, seed common.Hash) []common.Address {
+	for i := len(validators) - 1; i > 0; i-- {
+		seed = crypto.Keccak256Hash(seed[:])
+		j := binary.BigEndian.Uint64(seed[:8]) % uint64(i+1)
+		validators[i], validators[j] = validators[j], validators[i]
+	}
+	return validators
+}
+
 // inturn returns if a validator at a given block height is in-turn or not.
 func (s *Snapshot) inturn(number uint64, validator common.Address) bool {
-	validators, offset := s.validators(), 0
-	for offset < len(validators) && validators[offset] != validator {
+	proposers, offset := s.proposers(), 0
+	for offset < len(proposers) && proposers[offset] != validator {
 		offset++
 	}
-	return (number % uint64(len(validators))) == uint64(offset)
+	return (number % uint64(len(proposers))) == uint64(offset)
 }
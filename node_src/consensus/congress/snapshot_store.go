@@ -0,0 +1,117 @@
+package congress
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// errUnknownEpoch is returned if no snapshot is stored for the requested epoch.
+var errUnknownEpoch = errors.New("unknown epoch snapshot")
+
+// SnapshotStoreConfig are the configuration parameters of the epoch snapshot store.
+type SnapshotStoreConfig struct {
+	Retain uint64 // Number of recent epoch snapshots to keep on disk, 0 to keep all
+}
+
+// SetSnapshotStore sets the configuration of the epoch snapshot store.
+func (c *Congress) SetSnapshotStore(cfg SnapshotStoreConfig) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.snapStore = cfg
+}
+
+// loadEpochSnapshot loads the snapshot taken at the checkpoint block of an epoch.
+func loadEpochSnapshot(config *params.CongressConfig, sigcache *lru.ARCCache, db ethdb.KeyValueReader, epoch uint64) (*Snapshot, error) {
+	blob := rawdb.ReadCongressSnapshot(db, epoch)
+	if len(blob) == 0 {
+		return nil, errUnknownEpoch
+	}
+	snap := new(Snapshot)
+	if err := json.Unmarshal(blob, snap); err != nil {
+		return nil, err
+	}
+	snap.config = config
+	snap.sigcache = sigcache
+
+	return snap, nil
+}
+
+// storeEpoch inserts the snapshot of a checkpoint block into the epoch index.
+func (s *Snapshot) storeEpoch(db ethdb.KeyValueWriter) error {
+	blob, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteCongressSnapshot(db, s.Number/s.config.Epoch, blob)
+	return nil
+}
+
+// storeEpochSnapshot indexes the snapshot of a checkpoint block by its epoch, and
+// drops the epoch falling out of the retention window if one is configured.
+func (c *Congress) storeEpochSnapshot(snap *Snapshot) error {
+	if err := snap.storeEpoch(c.db); err != nil {
+		return err
+	}
+	c.lock.RLock()
+	retain := c.snapStore.Retain
+	c.lock.RUnlock()
+
+	if epoch := snap.Number / c.config.Epoch; retain > 0 && epoch >= retain {
+		rawdb.DeleteCongressSnapshot(c.db, epoch-retain)
+	}
+	log.Trace("Stored epoch snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+	return nil
+}
+
+// epochSnapshot retrieves the snapshot of the checkpoint block with the given hash
+// from the epoch index, nil if it isn't indexed. The index is keyed by number, so a
+// snapshot of a sidechain checkpoint is ignored.
+func (c *Congress) epochSnapshot(number uint64, hash common.Hash) *Snapshot {
+	snap, err := loadEpochSnapshot(c.config, c.signatures, c.db, number/c.config.Epoch)
+	if err != nil || snap.Number != number || snap.Hash != hash {
+		return nil
+	}
+	return snap
+}
+
+// PruneEpochSnapshots deletes the epoch snapshots below the given epoch, returning
+// the number of snapshots deleted.
+func PruneEpochSnapshots(db ethdb.Database, below uint64) (int, error) {
+	var (
+		it      = rawdb.IterateCongressSnapshots(db, 0)
+		batch   = db.NewBatch()
+		deleted int
+	)
+	defer it.Release()
+
+	for it.Next() {
+		epoch, ok := rawdb.CongressSnapshotEpoch(it.Key())
+		if !ok {
+			continue
+		}
+		if epoch >= below {
+			break
+		}
+		rawdb.DeleteCongressSnapshot(batch, epoch)
+		deleted++
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return deleted, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return deleted, err
+	}
+	return deleted, batch.Write()
+}
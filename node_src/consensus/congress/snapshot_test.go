@@ -0,0 +1,325 @@
+package congress
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+func TestRecentsLimit(t *testing.T) {
+	config := &params.CongressConfig{Epoch: 200, ValidatorsLimitBlock: big.NewInt(100), RecentsLimit: 5}
+	tests := []struct {
+		number     uint64
+		validators int
+		want       uint64
+	}{
+		// Legacy rule before the fork
+		{99, 1, 1},
+		{99, 7, 2},
+		{99, 21, 2},
+		{99, 51, 26},
+		// Configured limit after the fork, capped to the number of validators
+		{100, 7, 5},
+		{100, 51, 5},
+		{100, 3, 3},
+	}
+	for i, tt := range tests {
+		if have := recentsLimit(config, tt.number, tt.validators); have != tt.want {
+			t.Errorf("test %d: limit mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+	config.RecentsLimit = 0
+	if have := recentsLimit(config, 100, 7); have != 4 {
+		t.Errorf("default limit mismatch: have %d, want %d", have, 4)
+	}
+	if have := validatorsLimit(config, 100); have != maxValidators {
+		t.Errorf("default max validators mismatch: have %d, want %d", have, maxValidators)
+	}
+	config.MaxValidators = 51
+	if have := validatorsLimit(config, 99); have != maxValidators {
+		t.Errorf("max validators before fork mismatch: have %d, want %d", have, maxValidators)
+	}
+	if have := validatorsLimit(config, 100); have != 51 {
+		t.Errorf("max validators after fork mismatch: have %d, want %d", have, 51)
+	}
+	// The early blocks underflow the legacy recents check only
+	if signedRecently(config, 1, 0, 2) {
+		t.Errorf("legacy recents check mismatch: block 0 recent at 1")
+	}
+	if !signedRecently(config, 99, 98, 2) || signedRecently(config, 99, 97, 2) {
+		t.Errorf("legacy recents check mismatch at block 99")
+	}
+	config.ValidatorsLimitBlock = big.NewInt(0)
+	if !signedRecently(config, 1, 0, 2) || signedRecently(config, 2, 0, 2) {
+		t.Errorf("recents check mismatch after fork")
+	}
+}
+
+// Tests that the snapshot rejects validators sealing again within the configured limit.
+func TestSnapshotRecentsLimit(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	addrs := make([]common.Address, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	sign := func(number int64, key *ecdsa.PrivateKey) *types.Header {
+		header := &types.Header{Number: big.NewInt(number), Extra: make([]byte, extraVanity+extraSeal)}
+		sig, _ := crypto.Sign(SealHash(header).Bytes(), key)
+		copy(header.Extra[extraVanity:], sig)
+		return header
+	}
+	// Validators 0, 1, 0 are allowed by the legacy limit of 2, but not by a limit of 3
+	headers := []*types.Header{sign(1, keys[0]), sign(2, keys[1]), sign(3, keys[0])}
+
+	for _, fork := range []*big.Int{nil, big.NewInt(1)} {
+		config := &params.CongressConfig{Epoch: 200, ValidatorsLimitBlock: fork, RecentsLimit: 3}
+		sigcache, _ := lru.NewARC(inmemorySignatures)
+		snap := newSnapshot(config, sigcache, 0, common.Hash{}, addrs)
+
+		_, err := snap.apply(headers, nil, nil)
+		if fork == nil && err != nil {
+			t.Errorf("legacy limit: unexpected error: %v", err)
+		}
+		if fork != nil && err == nil {
+			t.Errorf("configured limit: validator allowed to seal recently")
+		}
+	}
+}
+
+func TestShuffleValidators(t *testing.T) {
+	validators := make([]common.Address, 21)
+	for i := range validators {
+		validators[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	seed := common.HexToHash("0x1234")
+
+	a := shuffleValidators(append([]common.Address{}, validators...), seed)
+	b := shuffleValidators(append([]common.Address{}, validators...), seed)
+	c := shuffleValidators(append([]common.Address{}, validators...), common.HexToHash("0x5678"))
+
+	seen := make(map[common.Address]bool)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("shuffle not deterministic at %d: %x != %x", i, a[i], b[i])
+		}
+		seen[a[i]] = true
+	}
+	if len(seen) != len(validators) {
+		t.Fatalf("shuffle is not a permutation: %d distinct of %d", len(seen), len(validators))
+	}
+	differs := false
+	for i := range a {
+		differs = differs || a[i] != c[i]
+	}
+	if !differs {
+		t.Fatalf("different seeds produced the same order")
+	}
+}
+
+// Tests that the proposers are shuffled at the checkpoints after the fork.
+func TestSnapshotShuffleProposers(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	addrs := make([]common.Address, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	sign := func(number int64, key *ecdsa.PrivateKey, validators []common.Address) *types.Header {
+		header := &types.Header{Number: big.NewInt(number), Extra: make([]byte, extraVanity)}
+		for _, validator := range validators {
+			header.Extra = append(header.Extra, validator.Bytes()...)
+		}
+		header.Extra = append(header.Extra, make([]byte, extraSeal)...)
+		sig, _ := crypto.Sign(SealHash(header).Bytes(), key)
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		return header
+	}
+	config := &params.CongressConfig{Epoch: 2, ShuffleBlock: big.NewInt(1)}
+	sigcache, _ := lru.NewARC(inmemorySignatures)
+	genesis := newSnapshot(config, sigcache, 0, common.Hash{}, addrs)
+
+	checkpoint := sign(2, keys[1], genesis.validators())
+	snap, err := genesis.apply([]*types.Header{sign(1, keys[0], nil), checkpoint}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to apply headers: %v", err)
+	}
+	want := shuffleValidators(genesis.validators(), shuffleSeed(config, checkpoint))
+	if len(snap.Proposers) != len(want) {
+		t.Fatalf("proposers mismatch: have %d, want %d", len(snap.Proposers), len(want))
+	}
+	for i := range want {
+		if snap.Proposers[i] != want[i] {
+			t.Fatalf("proposer %d mismatch: have %x, want %x", i, snap.Proposers[i], want[i])
+		}
+		if !snap.inturn(uint64(3+i), want[i]) {
+			t.Errorf("proposer %x not in-turn at %d", want[i], 3+i)
+		}
+	}
+	if len(genesis.Proposers) != 0 {
+		t.Errorf("parent snapshot modified: %v", genesis.Proposers)
+	}
+	// The sealer of the checkpoint can't pick the order by grinding its header
+	ground := types.CopyHeader(checkpoint)
+	ground.Extra[0], ground.Time = 0xff, ground.Time+1
+	if shuffleSeed(config, ground) != shuffleSeed(config, checkpoint) {
+		t.Errorf("shuffle seed depends on the checkpoint header")
+	}
+	ground.Number = big.NewInt(4)
+	if shuffleSeed(config, ground) == shuffleSeed(config, checkpoint) {
+		t.Errorf("shuffle seed doesn't depend on the epoch")
+	}
+}
+
+// Tests that checkpoint snapshots are indexed by epoch and pruned out of the
+// retention window.
+func TestEpochSnapshotStore(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.Congress = &params.CongressConfig{Epoch: 10}
+
+	db := rawdb.NewMemoryDatabase()
+	engine := New(&config, db)
+	engine.SetSnapshotStore(SnapshotStoreConfig{Retain: 3})
+
+	validators := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+	for epoch := uint64(0); epoch < 5; epoch++ {
+		snap := newSnapshot(config.Congress, engine.signatures, epoch*10, common.BigToHash(new(big.Int).SetUint64(epoch+1)), validators)
+		if err := engine.storeEpochSnapshot(snap); err != nil {
+			t.Fatalf("epoch %d: failed to store snapshot: %v", epoch, err)
+		}
+	}
+	for epoch := uint64(0); epoch < 5; epoch++ {
+		number, hash := epoch*10, common.BigToHash(new(big.Int).SetUint64(epoch+1))
+		snap := engine.epochSnapshot(number, hash)
+		if epoch < 2 && snap != nil {
+			t.Errorf("epoch %d: snapshot not pruned", epoch)
+		}
+		if epoch >= 2 && (snap == nil || len(snap.Validators) != len(validators)) {
+			t.Errorf("epoch %d: snapshot missing or corrupt: %v", epoch, snap)
+		}
+		if engine.epochSnapshot(number, common.Hash{}) != nil {
+			t.Errorf("epoch %d: snapshot returned for another checkpoint", epoch)
+		}
+	}
+	if deleted, err := PruneEpochSnapshots(db, 4); err != nil || deleted != 2 {
+		t.Fatalf("prune mismatch: deleted %d, err %v", deleted, err)
+	}
+	if engine.epochSnapshot(30, common.BigToHash(big.NewInt(4))) != nil || engine.epochSnapshot(40, common.BigToHash(big.NewInt(5))) == nil {
+		t.Fatalf("wrong snapshots pruned")
+	}
+	// The API rebuilds the snapshots missing from the index from the headers
+	chain := &headReader{testHeaderReader: &testHeaderReader{config: &config, headers: make(map[common.Hash]*types.Header)}}
+	checkpoint := &types.Header{Number: big.NewInt(10), Extra: make([]byte, extraVanity+extraSeal)}
+	chain.headers[checkpoint.Hash()] = checkpoint
+	engine.recents.Add(checkpoint.Hash(), newSnapshot(config.Congress, engine.signatures, 10, checkpoint.Hash(), validators))
+
+	api := &API{chain: chain, congress: engine}
+	if snap, err := api.GetEpochSnapshot(1); err != nil || snap.Hash != checkpoint.Hash() || len(snap.Validators) != len(validators) {
+		t.Fatalf("rebuilt epoch snapshot mismatch: %v, err %v", snap, err)
+	}
+	if snap, err := api.GetEpochSnapshot(4); err != nil || snap.Number != 40 {
+		t.Fatalf("indexed epoch snapshot mismatch: %v, err %v", snap, err)
+	}
+	if _, err := api.GetEpochSnapshot(2); err != errUnknownEpoch {
+		t.Fatalf("unknown epoch error mismatch: have %v, want %v", err, errUnknownEpoch)
+	}
+}
+
+// Tests that the proposer schedule follows the in-turn order of the snapshot and
+// stops at the checkpoint, after which the next epoch is shuffled with a new set.
+func TestProposerSchedule(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.Congress = &params.CongressConfig{Period: 3, Epoch: 4, ShuffleBlock: big.NewInt(0)}
+
+	keys := make(map[common.Address]*ecdsa.PrivateKey)
+	addrs := make([]common.Address, 3)
+	for i := range addrs {
+		key, _ := crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+		keys[addrs[i]] = key
+	}
+	var (
+		engine = New(&config, rawdb.NewMemoryDatabase())
+		chain  = &headReader{testHeaderReader: &testHeaderReader{config: &config, headers: make(map[common.Hash]*types.Header)}}
+		api    = &API{chain: chain, congress: engine}
+	)
+	extra := func(validators []common.Address) []byte {
+		extra := make([]byte, extraVanity)
+		for _, validator := range validators {
+			extra = append(extra, validator.Bytes()...)
+		}
+		return append(extra, make([]byte, extraSeal)...)
+	}
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1), Extra: extra(addrs)}
+	chain.headers[genesis.Hash()], chain.head = genesis, genesis
+
+	// Seal every block in-turn, dropping a validator at the checkpoint
+	sealers := make(map[uint64]common.Address)
+	for n := uint64(1); n <= 8; n++ {
+		snap, err := engine.snapshot(chain, n-1, chain.head.Hash(), nil)
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve snapshot: %v", n, err)
+		}
+		proposers := snap.proposers()
+		sealer := proposers[n%uint64(len(proposers))]
+
+		header := &types.Header{ParentHash: chain.head.Hash(), Number: new(big.Int).SetUint64(n), Difficulty: new(big.Int).Set(diffInTurn), Extra: extra(nil)}
+		if n%config.Congress.Epoch == 0 {
+			header.Extra = extra(addrs[:2])
+		}
+		sig, _ := crypto.Sign(SealHash(header).Bytes(), keys[sealer])
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+
+		chain.headers[header.Hash()], chain.head = header, header
+		sealers[n] = sealer
+	}
+	tests := []struct {
+		head     uint64
+		count    *uint64
+		from, to uint64
+	}{
+		{1, nil, 2, 4},               // Up to the checkpoint
+		{3, nil, 4, 4},               // The checkpoint is still sealed by the old set
+		{4, nil, 5, 8},               // The next epoch follows the new shuffled set
+		{5, new(uint64), 6, 5},       // Nothing asked
+		{1, &[]uint64{2}[0], 2, 3},   // Limited to the requested count
+		{5, &[]uint64{100}[0], 6, 8}, // Capped to the next checkpoint
+	}
+	for i, tt := range tests {
+		chain.head = chain.GetHeaderByNumber(tt.head)
+		schedule, err := api.GetProposerSchedule(tt.count)
+		if err != nil {
+			t.Fatalf("test %d: failed to get schedule: %v", i, err)
+		}
+		if schedule.Number != tt.head || schedule.Epoch != (tt.head/4+1)*4 {
+			t.Errorf("test %d: schedule range mismatch: head %d, epoch %d", i, schedule.Number, schedule.Epoch)
+		}
+		if want := int(tt.to + 1 - tt.from); len(schedule.Slots) != want {
+			t.Fatalf("test %d: slots mismatch: have %d, want %d", i, len(schedule.Slots), want)
+		}
+		for j, slot := range schedule.Slots {
+			if n := tt.from + uint64(j); slot.Number != n || slot.Validator != sealers[n] {
+				t.Errorf("test %d: slot %d mismatch: have %d by %x, want %d by %x", i, j, slot.Number, slot.Validator, n, sealers[n])
+			}
+			// The sealer of a block just before may still be blocked, even across the checkpoint
+			validators := 3
+			if tt.head >= 4 {
+				validators = 2
+			}
+			recent := false
+			for m := tt.head; m > 0 && m+recentsLimit(config.Congress, slot.Number, validators) > slot.Number; m-- {
+				recent = recent || sealers[m] == slot.Validator
+			}
+			if slot.Recent != recent {
+				t.Errorf("test %d: slot %d recent mismatch: have %v, want %v", i, slot.Number, slot.Recent, recent)
+			}
+		}
+	}
+}
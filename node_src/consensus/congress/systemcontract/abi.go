@@ -1336,6 +1336,31 @@ const PunishInteractiveABI = `[
 		"name": "LogDecreaseMissedBlocksCounter",
 		"type": "event"
 	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "val",
+				"type": "address"
+			},
+			{
+				"indexed": true,
+				"internalType": "uint256",
+				"name": "height",
+				"type": "uint256"
+			},
+			{
+				"indexed": false,
+				"internalType": "uint256",
+				"name": "time",
+				"type": "uint256"
+			}
+		],
+		"name": "LogPunishDoubleSign",
+		"type": "event"
+	},
 	{
 		"anonymous": false,
 		"inputs": [
@@ -1517,6 +1542,30 @@ const PunishInteractiveABI = `[
 		"stateMutability": "view",
 		"type": "function"
 	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "val",
+				"type": "address"
+			},
+			{
+				"internalType": "uint256",
+				"name": "height",
+				"type": "uint256"
+			}
+		],
+		"name": "doubleSignPunished",
+		"outputs": [
+			{
+				"internalType": "bool",
+				"name": "",
+				"type": "bool"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
 	{
 		"inputs": [
 			{
@@ -1595,6 +1644,24 @@ const PunishInteractiveABI = `[
 		"stateMutability": "nonpayable",
 		"type": "function"
 	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "val",
+				"type": "address"
+			},
+			{
+				"internalType": "uint256",
+				"name": "number",
+				"type": "uint256"
+			}
+		],
+		"name": "punishDoubleSign",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
 	{
 		"inputs": [],
 		"name": "punishThreshold",
@@ -2313,6 +2380,39 @@ const SysGovInteractiveABI = `
 
 const AddrListInteractiveABI = `
 [
+	{
+	  "inputs": [
+		{
+		  "internalType": "bytes32",
+		  "name": "sig",
+		  "type": "bytes32"
+		},
+		{
+		  "internalType": "uint128",
+		  "name": "checkIdx",
+		  "type": "uint128"
+		},
+		{
+		  "internalType": "enum AddressList.CheckType",
+		  "name": "t",
+		  "type": "uint8"
+		},
+		{
+		  "internalType": "enum AddressList.CheckLocation",
+		  "name": "loc",
+		  "type": "uint8"
+		},
+		{
+		  "internalType": "address",
+		  "name": "contractAddr",
+		  "type": "address"
+		}
+	  ],
+	  "name": "addOrUpdateRuleV2",
+	  "outputs": [],
+	  "stateMutability": "nonpayable",
+	  "type": "function"
+	},
 	{
 	  "inputs": [],
 	  "name": "blackLastUpdatedNumber",
@@ -2394,6 +2494,45 @@ const AddrListInteractiveABI = `
 	  "stateMutability": "view",
 	  "type": "function"
 	},
+	{
+	  "inputs": [
+		{
+		  "internalType": "uint32",
+		  "name": "i",
+		  "type": "uint32"
+		}
+	  ],
+	  "name": "getRuleV2ByIndex",
+	  "outputs": [
+		{
+		  "internalType": "bytes32",
+		  "name": "",
+		  "type": "bytes32"
+		},
+		{
+		  "internalType": "uint128",
+		  "name": "",
+		  "type": "uint128"
+		},
+		{
+		  "internalType": "enum AddressList.CheckType",
+		  "name": "",
+		  "type": "uint8"
+		},
+		{
+		  "internalType": "enum AddressList.CheckLocation",
+		  "name": "",
+		  "type": "uint8"
+		},
+		{
+		  "internalType": "address",
+		  "name": "",
+		  "type": "address"
+		}
+	  ],
+	  "stateMutability": "view",
+	  "type": "function"
+	},
 	{
 	  "inputs": [],
 	  "name": "initializeV2",
@@ -2433,6 +2572,34 @@ const AddrListInteractiveABI = `
 	  "stateMutability": "view",
 	  "type": "function"
 	},
+	{
+	  "inputs": [
+		{
+		  "internalType": "bytes32",
+		  "name": "sig",
+		  "type": "bytes32"
+		},
+		{
+		  "internalType": "uint128",
+		  "name": "checkIdx",
+		  "type": "uint128"
+		},
+		{
+		  "internalType": "enum AddressList.CheckLocation",
+		  "name": "loc",
+		  "type": "uint8"
+		},
+		{
+		  "internalType": "address",
+		  "name": "contractAddr",
+		  "type": "address"
+		}
+	  ],
+	  "name": "removeRuleV2",
+	  "outputs": [],
+	  "stateMutability": "nonpayable",
+	  "type": "function"
+	},
 	{
 	  "inputs": [],
 	  "name": "rulesLastUpdatedNumber",
@@ -2458,6 +2625,19 @@ const AddrListInteractiveABI = `
 	  ],
 	  "stateMutability": "view",
 	  "type": "function"
+	},
+	{
+	  "inputs": [],
+	  "name": "rulesV2Len",
+	  "outputs": [
+		{
+		  "internalType": "uint32",
+		  "name": "",
+		  "type": "uint32"
+		}
+	  ],
+	  "stateMutability": "view",
+	  "type": "function"
 	}
 ]`
 
@@ -2602,6 +2782,8 @@ var (
 	PunishV1ContractAddr     = common.HexToAddress("0x000000000000000000000000000000000000F006")
 	// SysGovToAddr is the To address for the system governance transaction, NOT contract address
 	SysGovToAddr = common.HexToAddress("0x000000000000000000000000000000000000ffff")
+	// DoubleSignEvidenceToAddr is the To address for the double-sign evidence transaction, NOT contract address
+	DoubleSignEvidenceToAddr = common.HexToAddress("0x000000000000000000000000000000000000fffe")
 
 	abiMap map[string]abi.ABI
 )
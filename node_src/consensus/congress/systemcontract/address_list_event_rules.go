@@ -0,0 +1,194 @@
+package systemcontract
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// rulesSlot is the slot of the legacy event check rules array of the AddressList
+	// contract, whose elements are (sig, idx | ct << 128).
+	rulesSlot = common.BigToHash(big.NewInt(9))
+	rulesBase = crypto.Keccak256Hash(rulesSlot.Bytes())
+
+	// rulesV2Slot is the slot of the event check rules array added at EventRulesBlock,
+	// whose elements are (sig, idx | ct << 128 | loc << 136, contract).
+	rulesV2Slot = storageSlot("congress.addressList.rulesV2")
+	rulesV2Base = crypto.Keccak256Hash(rulesV2Slot.Bytes())
+
+	// uint128Mask is the mask of the low 128 bits of a word.
+	uint128Mask = new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 128), common.Big1).Bytes()
+
+	// ruleKeyMask is the mask of a rules V2 word without its check type.
+	ruleKeyMask = common.BigToHash(new(big.Int).Xor(common.MaxHash.Big(), new(big.Int).Lsh(big.NewInt(0xff), 128)))
+
+	// addressListEventRulesCode is the AddressList V2 contract extended with:
+	//
+	//   function rulesV2Len() external view returns (uint32)
+	//   function getRuleV2ByIndex(uint32 i) external view returns (bytes32, uint128, CheckType, CheckLocation, address)
+	//   function addOrUpdateRuleV2(bytes32 sig, uint128 idx, CheckType t, CheckLocation loc, address contract) external onlyAdmin
+	//   function removeRuleV2(bytes32 sig, uint128 idx, CheckLocation loc, address contract) external onlyAdmin
+	//
+	// The V2 getters list the legacy rules first, as topic rules of any contract,
+	// followed by the rules added with addOrUpdateRuleV2.
+	addressListEventRulesCode = extendCode(common.FromHex(addressListV2Code), []extMethod{
+		{"rulesV2Len()", 0, rulesV2Len},
+		{"getRuleV2ByIndex(uint32)", 1, getRuleV2ByIndex},
+		{"addOrUpdateRuleV2(bytes32,uint128,uint8,uint8,address)", 5, addOrUpdateRuleV2},
+		{"removeRuleV2(bytes32,uint128,uint8,address)", 4, removeRuleV2},
+	})
+)
+
+// onlyAdmin reverts unless the caller is the admin of the AddressList contract.
+func onlyAdmin(b *codeBuilder) {
+	b.push(0).op(vm.SLOAD).push(16).op(vm.SHR).pushBytes(addressMask).op(vm.AND, vm.CALLER, vm.EQ).require("Admin only")
+}
+
+// ruleV2Key emits the word of a V2 rule without its check type.
+func ruleV2Key(b *codeBuilder, idx, loc uint64) {
+	b.argument(idx).pushBytes(uint128Mask).op(vm.AND)
+	b.argument(loc).push(136).op(vm.SHL, vm.OR)
+}
+
+// findRuleV2 emits the lookup of the V2 rule of the arguments, leaving the slot of
+// the rule on the stack, or jumping to notFound with a clean stack.
+func findRuleV2(b *codeBuilder, sig, idx, loc, contract uint64, notFound string) {
+	loop, next, found, missing := b.newLabel(), b.newLabel(), b.newLabel(), b.newLabel()
+
+	b.pushHash(rulesV2Base)
+	b.pushHash(rulesV2Slot).op(vm.SLOAD).push(3).op(vm.MUL, vm.DUP2, vm.ADD)
+	b.label(loop)
+	b.op(vm.DUP2, vm.DUP2, vm.EQ).jumpi(missing)
+	b.op(vm.DUP2, vm.SLOAD).argument(sig).op(vm.EQ, vm.ISZERO).jumpi(next)
+	b.op(vm.DUP2).push(1).op(vm.ADD, vm.SLOAD).pushHash(ruleKeyMask).op(vm.AND)
+	ruleV2Key(b, idx, loc)
+	b.op(vm.EQ, vm.ISZERO).jumpi(next)
+	b.op(vm.DUP2).push(2).op(vm.ADD, vm.SLOAD).address(contract).op(vm.EQ).jumpi(found)
+	b.label(next)
+	b.op(vm.SWAP1).push(3).op(vm.ADD, vm.SWAP1).jump(loop)
+	b.label(missing)
+	b.op(vm.POP, vm.POP).jump(notFound)
+	b.label(found)
+	b.op(vm.POP)
+}
+
+func rulesV2Len(b *codeBuilder) {
+	b.pushHash(rulesSlot).op(vm.SLOAD).pushHash(rulesV2Slot).op(vm.SLOAD, vm.ADD).push(0x80).op(vm.MSTORE)
+	b.push(0x20).push(0x80).op(vm.RETURN)
+}
+
+func getRuleV2ByIndex(b *codeBuilder) {
+	legacy := b.newLabel()
+
+	b.argument(0).pushBytes([]byte{0xff, 0xff, 0xff, 0xff}).op(vm.AND)
+	b.pushHash(rulesSlot).op(vm.SLOAD).op(vm.DUP2, vm.LT).jumpi(legacy)
+
+	// Rules added at EventRulesBlock
+	b.pushHash(rulesSlot).op(vm.SLOAD, vm.SWAP1, vm.SUB)
+	b.pushHash(rulesV2Slot).op(vm.SLOAD, vm.DUP2, vm.LT).require("index out of range")
+	b.push(3).op(vm.MUL).pushHash(rulesV2Base).op(vm.ADD)
+	b.op(vm.DUP1, vm.SLOAD).push(0x80).op(vm.MSTORE)
+	b.op(vm.DUP1).push(1).op(vm.ADD, vm.SLOAD)
+	b.op(vm.DUP1).pushBytes(uint128Mask).op(vm.AND).push(0xa0).op(vm.MSTORE)
+	b.op(vm.DUP1).push(128).op(vm.SHR).push(0xff).op(vm.AND).push(0xc0).op(vm.MSTORE)
+	b.push(136).op(vm.SHR).push(0xff).op(vm.AND).push(0xe0).op(vm.MSTORE)
+	b.push(2).op(vm.ADD, vm.SLOAD).pushBytes(addressMask).op(vm.AND).push(0x100).op(vm.MSTORE)
+	b.push(0xa0).push(0x80).op(vm.RETURN)
+
+	// Legacy rules, checking the topics of any contract
+	b.label(legacy)
+	b.push(2).op(vm.MUL).pushHash(rulesBase).op(vm.ADD)
+	b.op(vm.DUP1, vm.SLOAD).push(0x80).op(vm.MSTORE)
+	b.push(1).op(vm.ADD, vm.SLOAD)
+	b.op(vm.DUP1).pushBytes(uint128Mask).op(vm.AND).push(0xa0).op(vm.MSTORE)
+	b.push(128).op(vm.SHR).push(0xff).op(vm.AND).push(0xc0).op(vm.MSTORE)
+	b.push(0).push(0xe0).op(vm.MSTORE)
+	b.push(0).push(0x100).op(vm.MSTORE)
+	b.push(0xa0).push(0x80).op(vm.RETURN)
+}
+
+func addOrUpdateRuleV2(b *codeBuilder) {
+	onlyAdmin(b)
+	b.argument(0).require("eventSignature must not empty")
+	b.argument(1).push(128).op(vm.SHR).jumpi("revert")
+	b.argument(4).push(160).op(vm.SHR).jumpi("revert")
+	b.push(3).argument(2).push(1).op(vm.SWAP1, vm.SUB, vm.LT).require("invalid check type")
+	b.push(2).argument(3).op(vm.LT).require("invalid check location")
+
+	// Topic 0 is the event signature, while data word 0 is a valid offset
+	data := b.newLabel()
+	b.argument(3).jumpi(data)
+	b.argument(1).require("check index must greater than 0")
+	b.label(data)
+
+	// Update the check type of an existing rule
+	add, updated := b.newLabel(), b.newLabel()
+	findRuleV2(b, 0, 1, 3, 4, add)
+	b.push(1).op(vm.ADD)
+	ruleV2Key(b, 1, 3)
+	b.argument(2).push(128).op(vm.SHL, vm.OR, vm.SWAP1, vm.SSTORE)
+	b.jump(updated)
+
+	// Or append a new one
+	b.label(add)
+	b.pushHash(rulesV2Slot).op(vm.SLOAD)
+	b.op(vm.DUP1).push(1).op(vm.ADD).pushHash(rulesV2Slot).op(vm.SSTORE)
+	b.push(3).op(vm.MUL).pushHash(rulesV2Base).op(vm.ADD)
+	b.argument(0).op(vm.DUP2, vm.SSTORE)
+	ruleV2Key(b, 1, 3)
+	b.argument(2).push(128).op(vm.SHL, vm.OR, vm.DUP2).push(1).op(vm.ADD, vm.SSTORE)
+	b.address(4).op(vm.SWAP1).push(2).op(vm.ADD, vm.SSTORE)
+
+	// rulesLastUpdatedNumber = block.number
+	b.label(updated)
+	b.op(vm.NUMBER).pushHash(RulesLastUpdatedNumberPosition).op(vm.SSTORE, vm.STOP)
+}
+
+func removeRuleV2(b *codeBuilder) {
+	onlyAdmin(b)
+
+	missing := b.newLabel()
+	findRuleV2(b, 0, 1, 2, 3, missing)
+
+	// Move the last rule into the removed one and clear it
+	b.pushHash(rulesV2Slot).op(vm.SLOAD).push(1).op(vm.SWAP1, vm.SUB)
+	b.op(vm.DUP1).pushHash(rulesV2Slot).op(vm.SSTORE)
+	b.push(3).op(vm.MUL).pushHash(rulesV2Base).op(vm.ADD)
+	for word := uint64(0); word < 3; word++ {
+		b.op(vm.DUP1).push(word).op(vm.ADD, vm.SLOAD)
+		b.op(vm.DUP3).push(word).op(vm.ADD, vm.SSTORE)
+		b.push(0).op(vm.DUP2).push(word).op(vm.ADD, vm.SSTORE)
+	}
+	b.op(vm.POP, vm.POP)
+	b.op(vm.NUMBER).pushHash(RulesLastUpdatedNumberPosition).op(vm.SSTORE, vm.STOP)
+
+	b.label(missing)
+	b.revert("rule not found")
+}
+
+// hardForkAddressListEventRules adds the event check rules on data words and scoped
+// by contract to the AddressList V2 contract, keeping its storage.
+type hardForkAddressListEventRules struct {
+}
+
+func (s *hardForkAddressListEventRules) GetName() string {
+	return AddressListContractName
+}
+
+func (s *hardForkAddressListEventRules) Update(config *params.ChainConfig, height *big.Int, state *state.StateDB) (err error) {
+	state.SetCode(AddressListContractAddr, addressListEventRulesCode)
+	log.Debug("Upgrade code to system contract account", "addr", AddressListContractAddr.String(), "size", len(addressListEventRulesCode))
+	return
+}
+
+func (s *hardForkAddressListEventRules) Execute(state *state.StateDB, header *types.Header, chainContext core.ChainContext, config *params.ChainConfig) (err error) {
+	return
+}
@@ -0,0 +1,146 @@
+package systemcontract
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the extended AddressList contract lists the legacy rules followed by
+// the V2 ones, which only the admin can add, update and remove.
+func TestAddressListEventRules(t *testing.T) {
+	var (
+		admin  = devAdminTestnet
+		token  = common.HexToAddress("0x20")
+		sig    = common.HexToHash("0xddf252ad")
+		header = &types.Header{Number: big.NewInt(100), Coinbase: common.HexToAddress("0xc0ffee"), Difficulty: big.NewInt(1)}
+		config = params.AllCongressProtocolChanges
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err := applyUpgradeActions(SysContractV2, []IUpgradeAction{&hardForkAddressList{}, &hardForkAddressListV2{}}, statedb, header, testChainContext{}, config); err != nil {
+		t.Fatalf("failed to deploy address list v2: %v", err)
+	}
+	if err := ApplySystemContractUpgrade(SysContractEventRules, statedb, header, testChainContext{}, config); err != nil {
+		t.Fatalf("failed to upgrade address list: %v", err)
+	}
+	call := func(from common.Address, method string, args ...interface{}) ([]interface{}, error) {
+		t.Helper()
+		return callContract(t, statedb, header, from, AddressListContractAddr, AddressListContractName, method, args...)
+	}
+	// The legacy rules come first, checking the topics of any contract
+	res, err := call(admin, "rulesLen")
+	if err != nil {
+		t.Fatalf("failed to get rules length: %v", err)
+	}
+	legacy := res[0].(uint32)
+	if legacy == 0 {
+		t.Fatalf("no legacy rules")
+	}
+	checkLen := func(want uint32) {
+		t.Helper()
+		if res, err := call(admin, "rulesV2Len"); err != nil || res[0].(uint32) != want {
+			t.Fatalf("rules length mismatch: have %v (err %v), want %d", res, err, want)
+		}
+	}
+	checkLen(legacy)
+	for i := uint32(0); i < legacy; i++ {
+		want, err := call(admin, "getRuleByIndex", i)
+		if err != nil {
+			t.Fatalf("failed to get legacy rule %d: %v", i, err)
+		}
+		have, err := call(admin, "getRuleV2ByIndex", i)
+		if err != nil {
+			t.Fatalf("failed to get rule %d: %v", i, err)
+		}
+		if have[0] != want[0] || have[1].(*big.Int).Cmp(want[1].(*big.Int)) != 0 || have[2] != want[2] || have[3].(uint8) != 0 || have[4].(common.Address) != (common.Address{}) {
+			t.Errorf("rule %d mismatch: have %v, want %v", i, have, want)
+		}
+	}
+	if _, err := call(admin, "getRuleV2ByIndex", legacy); err == nil || err.Error() != "execution reverted: index out of range" {
+		t.Fatalf("rule out of range: have %v, want index out of range", err)
+	}
+	// Only the admin adds valid rules
+	tests := []struct {
+		from   common.Address
+		idx    int64
+		ct     uint8
+		loc    uint8
+		reason string
+	}{
+		{token, 1, 2, 1, "Admin only"},
+		{admin, 1, 0, 1, "invalid check type"},
+		{admin, 1, 4, 1, "invalid check type"},
+		{admin, 1, 2, 2, "invalid check location"},
+		{admin, 0, 2, 0, "check index must greater than 0"},
+	}
+	for i, tt := range tests {
+		if _, err := call(tt.from, "addOrUpdateRuleV2", sig, big.NewInt(tt.idx), tt.ct, tt.loc, token); err == nil || err.Error() != "execution reverted: "+tt.reason {
+			t.Errorf("test %d: have %v, want %s", i, err, tt.reason)
+		}
+	}
+	checkLen(legacy)
+
+	checkRule := func(i uint32, idx int64, ct uint8, loc uint8, contract common.Address) {
+		t.Helper()
+		have, err := call(admin, "getRuleV2ByIndex", i)
+		if err != nil {
+			t.Fatalf("failed to get rule %d: %v", i, err)
+		}
+		if have[0].([32]byte) != sig || have[1].(*big.Int).Int64() != idx || have[2].(uint8) != ct || have[3].(uint8) != loc || have[4].(common.Address) != contract {
+			t.Fatalf("rule %d mismatch: have %v", i, have)
+		}
+	}
+	// Data word 0 is a valid offset, scoped to a contract
+	header.Number = big.NewInt(101)
+	if _, err := call(admin, "addOrUpdateRuleV2", sig, big.NewInt(0), uint8(2), uint8(1), token); err != nil {
+		t.Fatalf("failed to add rule: %v", err)
+	}
+	checkLen(legacy + 1)
+	checkRule(legacy, 0, 2, 1, token)
+	if updated := statedb.GetState(AddressListContractAddr, RulesLastUpdatedNumberPosition); updated.Big().Cmp(header.Number) != 0 {
+		t.Fatalf("rules last updated number mismatch: have %v, want %v", updated.Big(), header.Number)
+	}
+	// The same rule is updated in place, another contract or location is a new rule
+	if _, err := call(admin, "addOrUpdateRuleV2", sig, big.NewInt(0), uint8(3), uint8(1), token); err != nil {
+		t.Fatalf("failed to update rule: %v", err)
+	}
+	checkLen(legacy + 1)
+	checkRule(legacy, 0, 3, 1, token)
+
+	if _, err := call(admin, "addOrUpdateRuleV2", sig, big.NewInt(1), uint8(1), uint8(0), common.Address{}); err != nil {
+		t.Fatalf("failed to add wildcard rule: %v", err)
+	}
+	checkLen(legacy + 2)
+	checkRule(legacy+1, 1, 1, 0, common.Address{})
+
+	// Removing a rule moves the last one in its place
+	header.Number = big.NewInt(102)
+	if _, err := call(token, "removeRuleV2", sig, big.NewInt(0), uint8(1), token); err == nil || err.Error() != "execution reverted: Admin only" {
+		t.Fatalf("remove by non admin: have %v, want Admin only", err)
+	}
+	if _, err := call(admin, "removeRuleV2", sig, big.NewInt(0), uint8(1), token); err != nil {
+		t.Fatalf("failed to remove rule: %v", err)
+	}
+	checkLen(legacy + 1)
+	checkRule(legacy, 1, 1, 0, common.Address{})
+	if updated := statedb.GetState(AddressListContractAddr, RulesLastUpdatedNumberPosition); updated.Big().Cmp(header.Number) != 0 {
+		t.Fatalf("rules last updated number mismatch: have %v, want %v", updated.Big(), header.Number)
+	}
+	if _, err := call(admin, "removeRuleV2", sig, big.NewInt(0), uint8(1), token); err == nil || err.Error() != "execution reverted: rule not found" {
+		t.Fatalf("remove missing rule: have %v, want rule not found", err)
+	}
+	if _, err := call(admin, "removeRuleV2", sig, big.NewInt(1), uint8(0), common.Address{}); err != nil {
+		t.Fatalf("failed to remove last rule: %v", err)
+	}
+	checkLen(legacy)
+
+	// The original functions are still served
+	if res, err := call(admin, "rulesLen"); err != nil || res[0].(uint32) != legacy {
+		t.Fatalf("legacy rules length mismatch: have %v (err %v), want %d", res, err, legacy)
+	}
+}
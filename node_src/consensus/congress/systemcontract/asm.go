@@ -0,0 +1,210 @@
+package systemcontract
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// extPadding is the number of zero bytes between a contract and its extension,
+// so the extension starts at an instruction boundary even if the metadata at
+// the end of the contract happens to decode as a truncated PUSH.
+const extPadding = 32
+
+// solidityPrologue is the free memory pointer setup the embedded contracts start
+// with, replaced by the jump into their extension.
+var solidityPrologue = []byte{byte(vm.PUSH1), 0x80, byte(vm.PUSH1), 0x40, byte(vm.MSTORE)}
+
+// addressMask is the mask of the low 160 bits of a word.
+var addressMask = common.MaxAddress.Bytes()
+
+// extMethod is a function added to an embedded contract.
+type extMethod struct {
+	signature string
+	args      uint64               // Number of words of the arguments
+	body      func(b *codeBuilder) // Code of the function, the call value and data size are already checked
+}
+
+// extendCode appends the given functions to an embedded solidity contract. The
+// prologue of the contract is patched to jump into a dispatcher in front of the
+// functions, which falls back to the original dispatcher for any other call, so
+// all the offsets of the original code stay valid.
+func extendCode(code []byte, methods []extMethod) []byte {
+	if !bytes.HasPrefix(code, solidityPrologue) {
+		panic("unexpected contract prologue")
+	}
+	b := newCodeBuilder(len(code) + extPadding)
+	b.label("dispatch")
+	b.push(0x80).push(0x40).op(vm.MSTORE)
+	b.push(4).op(vm.CALLDATASIZE, vm.LT).jumpi("fallback")
+	b.push(0).op(vm.CALLDATALOAD).push(0xe0).op(vm.SHR)
+	for _, method := range methods {
+		b.op(vm.DUP1).pushBytes(crypto.Keccak256([]byte(method.signature))[:4]).op(vm.EQ).jumpi(method.signature)
+	}
+	b.op(vm.POP)
+	b.label("fallback")
+	b.push(uint64(len(solidityPrologue) - 1)).op(vm.JUMP)
+
+	for _, method := range methods {
+		b.label(method.signature)
+		b.op(vm.POP, vm.CALLVALUE).jumpi("revert")
+		b.push(4+32*method.args).op(vm.CALLDATASIZE, vm.LT).jumpi("revert")
+		method.body(b)
+	}
+	b.label("revert")
+	b.push(0).op(vm.DUP1, vm.REVERT)
+	b.label("bubble")
+	b.op(vm.RETURNDATASIZE).push(0).op(vm.DUP1, vm.RETURNDATACOPY, vm.RETURNDATASIZE).push(0).op(vm.REVERT)
+
+	ext := b.assemble()
+	extended := make([]byte, b.base, b.base+len(ext))
+	copy(extended, code)
+	extended = append(extended, ext...)
+
+	// The last byte of the prologue becomes the JUMPDEST the fallback returns to
+	copy(extended, []byte{byte(vm.PUSH2), byte(b.base >> 8), byte(b.base), byte(vm.JUMP), byte(vm.JUMPDEST)})
+	return extended
+}
+
+// storageSlot derives the slot of a variable of an extension from its name, in
+// the same way as the EIP-1967 proxy slots so it can't clash with the solidity
+// layout of the contract.
+func storageSlot(name string) common.Hash {
+	slot := new(big.Int).SetBytes(crypto.Keccak256([]byte(name)))
+	return common.BigToHash(slot.Sub(slot, common.Big1))
+}
+
+// codeBuilder is a minimal assembler for the extensions of the embedded contracts,
+// resolving the jump labels once all the code is emitted.
+type codeBuilder struct {
+	base   int            // Offset of the assembled code in the extended contract
+	code   []byte         // Code emitted so far
+	labels map[string]int // Offsets of the labels in the extended contract
+	refs   map[int]string // Positions of the PUSH2 operands to fill with a label offset
+	anon   int            // Counter for the anonymous labels
+}
+
+func newCodeBuilder(base int) *codeBuilder {
+	return &codeBuilder{
+		base:   base,
+		labels: make(map[string]int),
+		refs:   make(map[int]string),
+	}
+}
+
+// op emits the given opcodes without any immediate.
+func (b *codeBuilder) op(ops ...vm.OpCode) *codeBuilder {
+	for _, op := range ops {
+		b.code = append(b.code, byte(op))
+	}
+	return b
+}
+
+// push emits the shortest PUSH of an integer.
+func (b *codeBuilder) push(v uint64) *codeBuilder {
+	return b.pushBytes(new(big.Int).SetUint64(v).Bytes())
+}
+
+// pushBytes emits a PUSH of the given big endian word, at least one byte wide.
+func (b *codeBuilder) pushBytes(word []byte) *codeBuilder {
+	if len(word) == 0 {
+		word = []byte{0}
+	}
+	if len(word) > 32 {
+		panic("push wider than a word")
+	}
+	b.code = append(b.code, byte(vm.PUSH1)+byte(len(word)-1))
+	b.code = append(b.code, word...)
+	return b
+}
+
+// pushHash emits a PUSH32 of the given word.
+func (b *codeBuilder) pushHash(h common.Hash) *codeBuilder {
+	return b.pushBytes(h.Bytes())
+}
+
+// pushLabel emits a PUSH2 of the offset of a label, which may be defined later.
+func (b *codeBuilder) pushLabel(name string) *codeBuilder {
+	b.code = append(b.code, byte(vm.PUSH2))
+	b.refs[len(b.code)] = name
+	b.code = append(b.code, 0, 0)
+	return b
+}
+
+// label defines a label at the current offset, emitting its JUMPDEST.
+func (b *codeBuilder) label(name string) *codeBuilder {
+	if _, ok := b.labels[name]; ok {
+		panic(fmt.Sprintf("label %q redefined", name))
+	}
+	b.labels[name] = b.base + len(b.code)
+	return b.op(vm.JUMPDEST)
+}
+
+// newLabel returns a unique label name for a local branch.
+func (b *codeBuilder) newLabel() string {
+	b.anon++
+	return fmt.Sprintf("@%d", b.anon)
+}
+
+// jump emits an unconditional jump to a label.
+func (b *codeBuilder) jump(name string) *codeBuilder {
+	return b.pushLabel(name).op(vm.JUMP)
+}
+
+// jumpi emits a jump to a label taken if the top of the stack is non zero.
+func (b *codeBuilder) jumpi(name string) *codeBuilder {
+	return b.pushLabel(name).op(vm.JUMPI)
+}
+
+// require consumes the top of the stack, reverting with the given reason if
+// it's zero like the solidity require statement.
+func (b *codeBuilder) require(reason string) *codeBuilder {
+	ok := b.newLabel()
+	b.jumpi(ok)
+	b.revert(reason)
+	return b.label(ok)
+}
+
+// revert emits a revert with a solidity Error(string) reason, using the memory
+// from the free memory pointer.
+func (b *codeBuilder) revert(reason string) *codeBuilder {
+	if len(reason) > 32 {
+		panic("revert reason longer than a word")
+	}
+	b.pushBytes([]byte{0x08, 0xc3, 0x79, 0xa0}).push(0xe0).op(vm.SHL).push(0x80).op(vm.MSTORE)
+	b.push(0x20).push(0x84).op(vm.MSTORE)
+	b.push(uint64(len(reason))).push(0xa4).op(vm.MSTORE)
+	b.pushHash(common.BytesToHash(common.RightPadBytes([]byte(reason), 32))).push(0xc4).op(vm.MSTORE)
+	return b.push(0x64).push(0x80).op(vm.REVERT)
+}
+
+// address emits the address in the given word of the call data, masking the dirty
+// high bits like solidity does.
+func (b *codeBuilder) address(word uint64) *codeBuilder {
+	return b.push(4 + 32*word).op(vm.CALLDATALOAD).pushBytes(addressMask).op(vm.AND)
+}
+
+// argument emits the given word of the call data.
+func (b *codeBuilder) argument(word uint64) *codeBuilder {
+	return b.push(4 + 32*word).op(vm.CALLDATALOAD)
+}
+
+// assemble resolves the labels, returning the code.
+func (b *codeBuilder) assemble() []byte {
+	code := common.CopyBytes(b.code)
+	for pos, name := range b.refs {
+		offset, ok := b.labels[name]
+		if !ok {
+			panic(fmt.Sprintf("undefined label %q", name))
+		}
+		if offset > 0xffff {
+			panic("label offset overflows PUSH2")
+		}
+		code[pos], code[pos+1] = byte(offset>>8), byte(offset)
+	}
+	return code
+}
@@ -0,0 +1,64 @@
+package systemcontract
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/consensus/congress/vmcaller"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// manifestVersion is the version logged for the upgrades scheduled in the chain config.
+const manifestVersion = "manifest"
+
+// manifestUpgrade is a system contract upgrade described in the chain config
+// instead of being built into the node.
+type manifestUpgrade struct {
+	*params.SysContractUpgrade
+}
+
+func (s *manifestUpgrade) GetName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Contract.String()
+}
+
+func (s *manifestUpgrade) Update(config *params.ChainConfig, height *big.Int, state *state.StateDB) (err error) {
+	if len(s.Code) > 0 {
+		state.SetCode(s.Contract, s.Code)
+		log.Debug("Upgrade code to system contract account", "addr", s.Contract.String(), "code", s.Code)
+	}
+	for key, value := range s.Storage {
+		state.SetState(s.Contract, key, value)
+	}
+	return
+}
+
+func (s *manifestUpgrade) Execute(state *state.StateDB, header *types.Header, chainContext core.ChainContext, config *params.ChainConfig) (err error) {
+	if len(s.InitCall) == 0 {
+		return
+	}
+	msg := vmcaller.NewLegacyMessage(header.Coinbase, &s.Contract, 0, new(big.Int), math.MaxUint64, new(big.Int), s.InitCall, false)
+	_, err = vmcaller.ExecuteMsg(msg, state, header, chainContext, config)
+	return
+}
+
+// ApplyManifestUpgrades applies the system contract upgrades scheduled in the chain
+// config at the given block, in the order they are listed.
+func ApplyManifestUpgrades(state *state.StateDB, header *types.Header, chainContext core.ChainContext, config *params.ChainConfig) error {
+	if config == nil || config.Congress == nil || header == nil || state == nil {
+		return nil
+	}
+	var sysContracts []IUpgradeAction
+	for _, upgrade := range config.Congress.Upgrades {
+		if upgrade.Block != nil && upgrade.Block.Cmp(header.Number) == 0 {
+			sysContracts = append(sysContracts, &manifestUpgrade{upgrade})
+		}
+	}
+	return applyUpgradeActions(manifestVersion, sysContracts, state, header, chainContext, config)
+}
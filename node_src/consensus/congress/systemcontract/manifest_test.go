@@ -0,0 +1,69 @@
+package systemcontract
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testChainContext is a chain context without any header.
+type testChainContext struct{}
+
+func (testChainContext) Engine() consensus.Engine                    { return ethash.NewFaker() }
+func (testChainContext) GetHeader(common.Hash, uint64) *types.Header { return nil }
+
+// Tests that the upgrades scheduled in the chain config replace the code, patch the
+// storage and run the init call of the contract, at their block only.
+func TestApplyManifestUpgrades(t *testing.T) {
+	var config params.ChainConfig
+	if err := json.Unmarshal([]byte(`{
+		"chainId": 128,
+		"congress": {
+			"period": 3,
+			"epoch": 200,
+			"upgrades": [{
+				"name": "address list v3",
+				"block": 10,
+				"contract": "0x000000000000000000000000000000000000f004",
+				"code": "0x600160015500",
+				"storage": {"0x0000000000000000000000000000000000000000000000000000000000000002": "0x00000000000000000000000000000000000000000000000000000000000000aa"},
+				"initCall": "0x01"
+			}]
+		}
+	}`), &config); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(AddressListContractAddr, []byte{0x00})
+
+	// Nothing is scheduled at other blocks
+	header := &types.Header{Number: big.NewInt(9), Difficulty: big.NewInt(1)}
+	if err := ApplyManifestUpgrades(statedb, header, testChainContext{}, &config); err != nil {
+		t.Fatalf("failed to apply upgrades: %v", err)
+	}
+	if code := statedb.GetCode(AddressListContractAddr); len(code) != 1 {
+		t.Fatalf("code upgraded too early: %x", code)
+	}
+	header.Number = big.NewInt(10)
+	if err := ApplyManifestUpgrades(statedb, header, testChainContext{}, &config); err != nil {
+		t.Fatalf("failed to apply upgrades: %v", err)
+	}
+	if code := statedb.GetCode(AddressListContractAddr); common.Bytes2Hex(code) != "600160015500" {
+		t.Errorf("code mismatch: have %x, want 600160015500", code)
+	}
+	if slot := statedb.GetState(AddressListContractAddr, common.HexToHash("0x02")); slot != common.HexToHash("0xaa") {
+		t.Errorf("patched slot mismatch: have %x, want 0xaa", slot)
+	}
+	// The init call stores 1 at slot 1
+	if slot := statedb.GetState(AddressListContractAddr, common.HexToHash("0x01")); slot != common.HexToHash("0x01") {
+		t.Errorf("initialized slot mismatch: have %x, want 0x01", slot)
+	}
+}
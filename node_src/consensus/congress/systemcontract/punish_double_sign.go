@@ -0,0 +1,100 @@
+package systemcontract
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// punishDoubleSignSlot is the base slot of the (validator, height) pairs already
+	// punished for double signing.
+	punishDoubleSignSlot = storageSlot("congress.punish.doubleSign")
+
+	// PunishDoubleSignTopic is the topic of LogPunishDoubleSign(address indexed val, uint256 indexed height, uint256 time).
+	PunishDoubleSignTopic = crypto.Keccak256Hash([]byte("LogPunishDoubleSign(address,uint256,uint256)"))
+
+	// punishDoubleSignCode is the Punish V1 contract extended with:
+	//
+	//   function punishDoubleSign(address val, uint256 height) external onlyMiner onlyInitialized
+	//   function doubleSignPunished(address val, uint256 height) external view returns (bool)
+	//
+	// The first one jails the validator through its validator contract like the removal
+	// threshold of the punish function does, at most once for a given height.
+	punishDoubleSignCode = extendCode(common.FromHex(punishV1Code), []extMethod{
+		{"punishDoubleSign(address,uint256)", 2, punishDoubleSign},
+		{"doubleSignPunished(address,uint256)", 2, doubleSignPunished},
+	})
+)
+
+// doubleSignKey emits the slot flagging the validator of the arguments as punished
+// for the height of the arguments.
+func doubleSignKey(b *codeBuilder) {
+	b.address(0).push(0x80).op(vm.MSTORE)
+	b.argument(1).push(0xa0).op(vm.MSTORE)
+	b.pushHash(punishDoubleSignSlot).push(0xc0).op(vm.MSTORE)
+	b.push(0x60).push(0x80).op(vm.SHA3)
+}
+
+func punishDoubleSign(b *codeBuilder) {
+	b.op(vm.COINBASE, vm.CALLER, vm.EQ).require("Miner only")
+	b.push(0).op(vm.SLOAD).push(0xff).op(vm.AND).require("Not init yet")
+	b.argument(1).op(vm.NUMBER, vm.GT).require("Invalid height")
+
+	// Flag the height as punished
+	doubleSignKey(b)
+	b.op(vm.DUP1, vm.SLOAD, vm.ISZERO).require("Already punished")
+	b.push(1).op(vm.SWAP1, vm.SSTORE)
+
+	// Look the validator contract up
+	b.pushBytes([]byte{0x65, 0xf6, 0x9f, 0x97}).push(0xe0).op(vm.SHL).push(0x80).op(vm.MSTORE)
+	b.address(0).push(0x84).op(vm.MSTORE)
+	b.push(0x20).push(0x80).push(0x24).push(0x80).pushBytes(ValidatorsV1ContractAddr.Bytes()).op(vm.GAS, vm.STATICCALL)
+	b.op(vm.ISZERO).jumpi("bubble")
+	b.push(0x20).op(vm.RETURNDATASIZE, vm.LT).jumpi("revert")
+	b.push(0x80).op(vm.MLOAD).pushBytes(addressMask).op(vm.AND)
+	b.op(vm.DUP1, vm.EXTCODESIZE, vm.ISZERO).jumpi("revert")
+
+	// Jail it with punish()
+	b.pushBytes([]byte{0x82, 0x6d, 0x3d, 0xec}).push(0xe0).op(vm.SHL).push(0x80).op(vm.MSTORE)
+	b.push(0).push(0).push(4).push(0x80).push(0).op(vm.DUP6, vm.GAS, vm.CALL)
+	b.op(vm.ISZERO).jumpi("bubble")
+	b.op(vm.POP)
+
+	// emit LogPunishDoubleSign(val, height, block.timestamp)
+	b.op(vm.TIMESTAMP).push(0x80).op(vm.MSTORE)
+	b.argument(1).address(0).pushHash(PunishDoubleSignTopic).push(0x20).push(0x80).op(vm.LOG3)
+	b.op(vm.STOP)
+}
+
+func doubleSignPunished(b *codeBuilder) {
+	doubleSignKey(b)
+	b.op(vm.SLOAD, vm.ISZERO, vm.ISZERO).push(0x80).op(vm.MSTORE)
+	b.push(0x20).push(0x80).op(vm.RETURN)
+}
+
+// hardForkPunishDoubleSign adds the double sign slashing to the Punish V1 contract,
+// keeping its storage.
+type hardForkPunishDoubleSign struct {
+}
+
+func (s *hardForkPunishDoubleSign) GetName() string {
+	return PunishV1ContractName
+}
+
+func (s *hardForkPunishDoubleSign) Update(config *params.ChainConfig, height *big.Int, state *state.StateDB) (err error) {
+	state.SetCode(PunishV1ContractAddr, punishDoubleSignCode)
+	log.Debug("Upgrade code to system contract account", "addr", PunishV1ContractAddr.String(), "size", len(punishDoubleSignCode))
+	return
+}
+
+func (s *hardForkPunishDoubleSign) Execute(state *state.StateDB, header *types.Header, chainContext core.ChainContext, config *params.ChainConfig) (err error) {
+	return
+}
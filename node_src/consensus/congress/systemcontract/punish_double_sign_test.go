@@ -0,0 +1,126 @@
+package systemcontract
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress/vmcaller"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// callContract packs and executes a call to a system contract, returning the revert
+// reason as the error if any.
+func callContract(t *testing.T, statedb *state.StateDB, header *types.Header, from, to common.Address, name string, method string, args ...interface{}) ([]interface{}, error) {
+	t.Helper()
+
+	contractABI := GetInteractiveABI()[name]
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		t.Fatalf("failed to pack %s: %v", method, err)
+	}
+	msg := vmcaller.NewLegacyMessage(from, &to, 0, new(big.Int), math.MaxUint64, new(big.Int), data, false)
+	ret, err := vmcaller.ExecuteMsg(msg, statedb, header, testChainContext{}, params.AllCongressProtocolChanges)
+	if err == vm.ErrExecutionReverted {
+		if reason, unpackErr := abi.UnpackRevert(ret); unpackErr == nil {
+			return nil, fmt.Errorf("%w: %s", vm.ErrExecutionReverted, reason)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return contractABI.Unpack(method, ret)
+}
+
+// Tests that the extended Punish V1 contract jails a validator once per double signed
+// height, and still serves the original functions.
+func TestPunishDoubleSign(t *testing.T) {
+	var (
+		miner     = common.HexToAddress("0xc0ffee")
+		validator = common.HexToAddress("0x1234")
+		contract  = common.HexToAddress("0xbeef") // Validator contract returned by the validators stub
+		header    = &types.Header{Number: big.NewInt(100), Coinbase: miner, Time: 1000, Difficulty: big.NewInt(1)}
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	// The validators contract always returns the same validator contract, counting
+	// the calls to punish() and recording the caller
+	lookup := newCodeBuilder(0)
+	lookup.pushBytes(contract.Bytes()).push(0).op(vm.MSTORE).push(0x20).push(0).op(vm.RETURN)
+	statedb.SetCode(ValidatorsV1ContractAddr, lookup.assemble())
+
+	jail := newCodeBuilder(0)
+	jail.push(0).op(vm.SLOAD).push(1).op(vm.ADD).push(0).op(vm.SSTORE)
+	jail.op(vm.CALLER).push(1).op(vm.SSTORE, vm.STOP)
+	statedb.SetCode(contract, jail.assemble())
+
+	v1 := &hardForkPunishV1{}
+	if err := v1.Update(params.AllCongressProtocolChanges, header.Number, statedb); err != nil {
+		t.Fatalf("failed to deploy punish v1: %v", err)
+	}
+	if err := v1.Execute(statedb, header, testChainContext{}, params.AllCongressProtocolChanges); err != nil {
+		t.Fatalf("failed to initialize punish v1: %v", err)
+	}
+	threshold := statedb.GetState(PunishV1ContractAddr, common.BigToHash(common.Big1))
+
+	if err := ApplySystemContractUpgrade(SysContractDoubleSign, statedb, header, testChainContext{}, params.AllCongressProtocolChanges); err != nil {
+		t.Fatalf("failed to upgrade punish contract: %v", err)
+	}
+	if _, err := callContract(t, statedb, header, validator, PunishV1ContractAddr, PunishContractName, "punishDoubleSign", validator, big.NewInt(90)); err == nil || err.Error() != "execution reverted: Miner only" {
+		t.Fatalf("punish by non miner: have %v, want Miner only", err)
+	}
+	if _, err := callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "punishDoubleSign", validator, big.NewInt(100)); err == nil || err.Error() != "execution reverted: Invalid height" {
+		t.Fatalf("punish of current height: have %v, want Invalid height", err)
+	}
+	punished, err := callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "doubleSignPunished", validator, big.NewInt(90))
+	if err != nil || punished[0].(bool) {
+		t.Fatalf("height punished before submission: %v, err %v", punished, err)
+	}
+	statedb.Prepare(common.HexToHash("0x01"), 0)
+	if _, err := callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "punishDoubleSign", validator, big.NewInt(90)); err != nil {
+		t.Fatalf("failed to punish double sign: %v", err)
+	}
+	if calls := statedb.GetState(contract, common.Hash{}); calls != common.BigToHash(common.Big1) {
+		t.Fatalf("validator contract punish calls mismatch: have %x, want 1", calls)
+	}
+	if caller := statedb.GetState(contract, common.BigToHash(common.Big1)); caller != common.BytesToHash(PunishV1ContractAddr.Bytes()) {
+		t.Fatalf("validator contract caller mismatch: have %x, want %x", caller, PunishV1ContractAddr)
+	}
+	logs := statedb.GetLogs(common.HexToHash("0x01"), common.Hash{})
+	if len(logs) != 1 || len(logs[0].Topics) != 3 || logs[0].Topics[0] != PunishDoubleSignTopic {
+		t.Fatalf("double sign log mismatch: %v", logs)
+	}
+	if logs[0].Topics[1] != common.BytesToHash(validator.Bytes()) || logs[0].Topics[2] != common.BigToHash(big.NewInt(90)) || new(big.Int).SetBytes(logs[0].Data).Uint64() != header.Time {
+		t.Fatalf("double sign log fields mismatch: %v", logs[0])
+	}
+	punished, err = callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "doubleSignPunished", validator, big.NewInt(90))
+	if err != nil || !punished[0].(bool) {
+		t.Fatalf("height not punished after submission: %v, err %v", punished, err)
+	}
+	if _, err := callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "punishDoubleSign", validator, big.NewInt(90)); err == nil || err.Error() != "execution reverted: Already punished" {
+		t.Fatalf("punish twice: have %v, want Already punished", err)
+	}
+	// Another height is punished on its own
+	if _, err := callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "punishDoubleSign", validator, big.NewInt(91)); err != nil {
+		t.Fatalf("failed to punish another height: %v", err)
+	}
+	// The original functions are still served
+	res, err := callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "punishThreshold")
+	if err != nil || common.BigToHash(res[0].(*big.Int)) != threshold {
+		t.Fatalf("punish threshold mismatch: have %v, want %x, err %v", res, threshold, err)
+	}
+	if _, err := callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "punish", validator); err != nil {
+		t.Fatalf("failed to punish missed block: %v", err)
+	}
+	res, err = callContract(t, statedb, header, miner, PunishV1ContractAddr, PunishContractName, "getPunishRecord", validator)
+	if err != nil || res[0].(*big.Int).Uint64() != 1 {
+		t.Fatalf("punish record mismatch: have %v, err %v", res, err)
+	}
+}
@@ -13,6 +13,8 @@ import (
 const (
 	SysContractV1 SysContractVersion = iota + 1
 	SysContractV2
+	SysContractDoubleSign
+	SysContractEventRules
 )
 
 type SysContractVersion int
@@ -27,7 +29,6 @@ func ApplySystemContractUpgrade(version SysContractVersion, state *state.StateDB
 	if config == nil || header == nil || state == nil {
 		return
 	}
-	height := header.Number
 
 	var sysContracts []IUpgradeAction
 	switch version {
@@ -43,10 +44,26 @@ func ApplySystemContractUpgrade(version SysContractVersion, state *state.StateDB
 			&hardForkAddressListV2{},
 			&hardForkValidatorsV2{},
 		}
+	case SysContractDoubleSign:
+		sysContracts = []IUpgradeAction{
+			&hardForkPunishDoubleSign{},
+		}
+	case SysContractEventRules:
+		sysContracts = []IUpgradeAction{
+			&hardForkAddressListEventRules{},
+		}
 	default:
 		log.Crit("unsupported SysContractVersion", "version", version)
 	}
 
+	return applyUpgradeActions(version, sysContracts, state, header, chainContext, config)
+}
+
+// applyUpgradeActions updates then executes the given upgrade actions in order,
+// stopping at the first one which fails.
+func applyUpgradeActions(version interface{}, sysContracts []IUpgradeAction, state *state.StateDB, header *types.Header, chainContext core.ChainContext, config *params.ChainConfig) (err error) {
+	height := header.Number
+
 	for _, contract := range sysContracts {
 		log.Info("system contract upgrade", "version", version, "name", contract.GetName(), "height", height, "chainId", config.ChainID.String())
 
@@ -0,0 +1,331 @@
+package congress
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// MaxSimulatedBlocks is the maximum number of blocks an upgrade simulation replays,
+// as each of them is processed twice in memory.
+const MaxSimulatedBlocks = 1024
+
+var (
+	// errUnsupportedSysContractVersion is returned if a simulated upgrade targets an
+	// unknown version of the system contracts.
+	errUnsupportedSysContractVersion = errors.New("unsupported system contract version")
+
+	// systemContracts are the accounts whose code and storage are compared by an
+	// upgrade simulation.
+	systemContracts = []common.Address{
+		systemcontract.ValidatorsContractAddr,
+		systemcontract.PunishContractAddr,
+		systemcontract.ProposalAddr,
+		systemcontract.SysGovContractAddr,
+		systemcontract.AddressListContractAddr,
+		systemcontract.ValidatorsV1ContractAddr,
+		systemcontract.PunishV1ContractAddr,
+	}
+
+	// validatorsContracts are the system contracts the validators are read from.
+	validatorsContracts = []struct {
+		addr common.Address
+		name string
+	}{
+		{systemcontract.ValidatorsContractAddr, systemcontract.ValidatorsContractName},
+		{systemcontract.ValidatorsV1ContractAddr, systemcontract.ValidatorsV1ContractName},
+	}
+)
+
+// UpgradeSimulation is the outcome of applying a system contract upgrade on a copy
+// of the state and replaying the following blocks on top of it. The diffs compare
+// the upgraded state with the state replayed without the upgrade.
+type UpgradeSimulation struct {
+	Version    systemcontract.SysContractVersion `json:"version"`
+	Number     uint64                            `json:"number"`          // Block the upgrade is applied at
+	Replayed   uint64                            `json:"replayed"`        // Number of blocks replayed on both states
+	Error      string                            `json:"error,omitempty"` // Failure of the upgrade or of the replay on the upgraded state
+	Validators []*ValidatorsDiff                 `json:"validators"`
+	Balances   []*BalanceDiff                    `json:"balances"`
+	Contracts  []*ContractDiff                   `json:"contracts"`
+}
+
+// ValidatorsDiff is the difference of the top validators returned by a validators
+// contract. A nil list means the contract has no code or the call failed.
+type ValidatorsDiff struct {
+	Contract common.Address   `json:"contract"`
+	Before   []common.Address `json:"before"`
+	After    []common.Address `json:"after"`
+}
+
+// BalanceDiff is the difference of the balance of an account.
+type BalanceDiff struct {
+	Address common.Address `json:"address"`
+	Before  *hexutil.Big   `json:"before"`
+	After   *hexutil.Big   `json:"after"`
+}
+
+// ContractDiff is the difference of the code and storage of a system contract.
+type ContractDiff struct {
+	Address        common.Address `json:"address"`
+	CodeHashBefore common.Hash    `json:"codeHashBefore"`
+	CodeHashAfter  common.Hash    `json:"codeHashAfter"`
+	Storage        []*SlotDiff    `json:"storage"`
+}
+
+// SlotDiff is the difference of a storage slot. Slots are keyed by the hash of
+// their position, the position itself is only known if its preimage was recorded.
+type SlotDiff struct {
+	Key    common.Hash  `json:"key"`
+	Slot   *common.Hash `json:"slot,omitempty"`
+	Before common.Hash  `json:"before"`
+	After  common.Hash  `json:"after"`
+}
+
+// SimulateUpgrade applies the given system contract upgrade at the start of the
+// block with the given number on a copy of the state, replays up to the given
+// number of canonical blocks from there on both the upgraded copy and a plain
+// one, and reports how the two states differ. Nothing is written to the chain.
+//
+// The replay on the upgraded state stops at the first block which fails, as the
+// upgrade may well invalidate the blocks sealed without it.
+func (c *Congress) SimulateUpgrade(chain *core.BlockChain, version systemcontract.SysContractVersion, number uint64, blocks uint64) (*UpgradeSimulation, error) {
+	switch version {
+	case systemcontract.SysContractV1, systemcontract.SysContractV2, systemcontract.SysContractDoubleSign, systemcontract.SysContractEventRules:
+	default:
+		return nil, fmt.Errorf("%w: %d", errUnsupportedSysContractVersion, version)
+	}
+	if number == 0 {
+		return nil, errors.New("can't upgrade at the genesis block")
+	}
+	if blocks > MaxSimulatedBlocks {
+		return nil, fmt.Errorf("too many blocks to replay: have %d, max %d", blocks, MaxSimulatedBlocks)
+	}
+	block := chain.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	parent := chain.GetHeader(block.ParentHash(), number-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	baseline, err := chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block #%d unavailable: %v", number-1, err)
+	}
+	upgraded := baseline.Copy()
+
+	// Replay on engines of their own, so that the records of the canonical blocks
+	// aren't overwritten, and the upgraded blocks read the upgraded validators.
+	baseEngine, err := c.simulator(chain, parent, chain.StateAt)
+	if err != nil {
+		return nil, err
+	}
+	upgradeEngine, err := c.simulator(chain, parent, func(common.Hash) (*state.StateDB, error) {
+		return upgraded.Copy(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sim := &UpgradeSimulation{
+		Version: version,
+		Number:  number,
+	}
+	header := block.Header()
+	if err := systemcontract.ApplySystemContractUpgrade(version, upgraded, header, newChainContext(chain, upgradeEngine), c.chainConfig); err != nil {
+		sim.Error = fmt.Sprintf("upgrade: %v", err)
+		blocks = 0
+	}
+	var (
+		baseProcessor    = core.NewStateProcessor(c.chainConfig, chain, baseEngine)
+		upgradeProcessor = core.NewStateProcessor(c.chainConfig, chain, upgradeEngine)
+		coinbases        []common.Address
+	)
+	for ; sim.Replayed < blocks; sim.Replayed++ {
+		block := chain.GetBlockByNumber(number + sim.Replayed)
+		if block == nil {
+			break
+		}
+		next := upgraded.Copy()
+		if _, _, _, err := upgradeProcessor.Process(block, next, vm.Config{}); err != nil {
+			sim.Error = fmt.Sprintf("block #%d: %v", block.NumberU64(), err)
+			break
+		}
+		if _, _, _, err := baseProcessor.Process(block, baseline, vm.Config{}); err != nil {
+			return nil, fmt.Errorf("replay of block #%d failed: %v", block.NumberU64(), err)
+		}
+		upgraded = next
+		header = block.Header()
+		coinbases = append(coinbases, header.Coinbase)
+	}
+	if err := c.diffUpgrade(sim, header, baseline, upgraded, coinbases); err != nil {
+		return nil, err
+	}
+	log.Info("Simulated system contract upgrade", "version", version, "number", number, "replayed", sim.Replayed, "err", sim.Error)
+	return sim, nil
+}
+
+// simulator creates an engine replaying blocks on simulated states. It starts from
+// the validator snapshot of the given parent block, but doesn't share the database
+// of c, so nothing it records outlives the simulation.
+func (c *Congress) simulator(chain *core.BlockChain, parent *types.Header, stateFn StateFn) (*Congress, error) {
+	snap, err := c.snapshot(chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	sim := New(c.chainConfig, rawdb.NewMemoryDatabase())
+	sim.SetChain(chain)
+	sim.SetStateFn(stateFn)
+	sim.recents.Add(snap.Hash, snap.copy())
+	return sim, nil
+}
+
+// diffUpgrade fills the diffs of the simulation, comparing the validators, the
+// balances of the system contracts, validators and given coinbases, and the code
+// and storage of the system contracts in the two states.
+func (c *Congress) diffUpgrade(sim *UpgradeSimulation, header *types.Header, baseline, upgraded *state.StateDB, coinbases []common.Address) error {
+	sim.Validators = []*ValidatorsDiff{}
+	sim.Balances = []*BalanceDiff{}
+	sim.Contracts = []*ContractDiff{}
+
+	accounts := make(map[common.Address]struct{})
+	for _, addr := range append(append([]common.Address{consensus.FeeRecoder}, systemContracts...), coinbases...) {
+		accounts[addr] = struct{}{}
+	}
+	for _, contract := range validatorsContracts {
+		before := c.simulatedValidators(header, baseline, contract.addr, contract.name)
+		after := c.simulatedValidators(header, upgraded, contract.addr, contract.name)
+		for _, val := range append(append([]common.Address{}, before...), after...) {
+			accounts[val] = struct{}{}
+		}
+		if !equalAddresses(before, after) {
+			sim.Validators = append(sim.Validators, &ValidatorsDiff{Contract: contract.addr, Before: before, After: after})
+		}
+	}
+	for addr := range accounts {
+		before, after := baseline.GetBalance(addr), upgraded.GetBalance(addr)
+		if before.Cmp(after) != 0 {
+			sim.Balances = append(sim.Balances, &BalanceDiff{Address: addr, Before: (*hexutil.Big)(before), After: (*hexutil.Big)(after)})
+		}
+	}
+	sort.Slice(sim.Balances, func(i, j int) bool {
+		return bytes.Compare(sim.Balances[i].Address[:], sim.Balances[j].Address[:]) < 0
+	})
+	for _, addr := range systemContracts {
+		diff, err := diffContract(addr, baseline, upgraded)
+		if err != nil {
+			return err
+		}
+		if diff != nil {
+			sim.Contracts = append(sim.Contracts, diff)
+		}
+	}
+	return nil
+}
+
+// simulatedValidators returns the top validators of the given validators contract,
+// nil if the contract has no code or the call fails.
+func (c *Congress) simulatedValidators(header *types.Header, statedb *state.StateDB, addr common.Address, name string) []common.Address {
+	if statedb.GetCodeSize(addr) == 0 {
+		return nil
+	}
+	ret, err := c.commonCallContract(header, statedb.Copy(), c.abi[name], addr, "getTopValidators", 1)
+	if err != nil {
+		log.Debug("Failed to get simulated validators", "contract", addr, "err", err)
+		return nil
+	}
+	validators, ok := ret[0].([]common.Address)
+	if !ok {
+		return nil
+	}
+	sort.Sort(validatorsAscending(validators))
+	return validators
+}
+
+// diffContract compares the code and storage of a contract in the two states, nil
+// if they are the same.
+func diffContract(addr common.Address, baseline, upgraded *state.StateDB) (*ContractDiff, error) {
+	before, err := storageOf(baseline, addr)
+	if err != nil {
+		return nil, err
+	}
+	after, err := storageOf(upgraded, addr)
+	if err != nil {
+		return nil, err
+	}
+	diff := &ContractDiff{
+		Address:        addr,
+		CodeHashBefore: baseline.GetCodeHash(addr),
+		CodeHashAfter:  upgraded.GetCodeHash(addr),
+		Storage:        []*SlotDiff{},
+	}
+	for key, value := range before {
+		if after[key] != value {
+			diff.Storage = append(diff.Storage, &SlotDiff{Key: key, Before: value, After: after[key]})
+		}
+	}
+	for key, value := range after {
+		if _, exist := before[key]; !exist {
+			diff.Storage = append(diff.Storage, &SlotDiff{Key: key, After: value})
+		}
+	}
+	if diff.CodeHashBefore == diff.CodeHashAfter && len(diff.Storage) == 0 {
+		return nil, nil
+	}
+	sort.Slice(diff.Storage, func(i, j int) bool {
+		return bytes.Compare(diff.Storage[i].Key[:], diff.Storage[j].Key[:]) < 0
+	})
+	if tr := upgraded.StorageTrie(addr); tr != nil {
+		for _, slot := range diff.Storage {
+			if preimage := tr.GetKey(slot.Key[:]); preimage != nil {
+				position := common.BytesToHash(preimage)
+				slot.Slot = &position
+			}
+		}
+	}
+	return diff, nil
+}
+
+// storageOf returns the storage of an account keyed by the hash of the slots.
+func storageOf(statedb *state.StateDB, addr common.Address) (map[common.Hash]common.Hash, error) {
+	slots := make(map[common.Hash]common.Hash)
+	tr := statedb.StorageTrie(addr)
+	if tr == nil {
+		return slots, nil
+	}
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		_, content, _, err := rlp.Split(it.Value)
+		if err != nil {
+			return nil, err
+		}
+		slots[common.BytesToHash(it.Key)] = common.BytesToHash(content)
+	}
+	return slots, it.Err
+}
+
+func equalAddresses(a, b []common.Address) bool {
+	if len(a) != len(b) || (a == nil) != (b == nil) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
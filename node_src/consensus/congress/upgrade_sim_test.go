@@ -0,0 +1,154 @@
+package congress
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the diffs of an upgrade simulation report the changed code, storage
+// and balances only.
+func TestDiffUpgrade(t *testing.T) {
+	var (
+		engine  = New(params.AllCongressProtocolChanges, rawdb.NewMemoryDatabase())
+		header  = &types.Header{Number: big.NewInt(10), Coinbase: common.HexToAddress("0xc0ffee")}
+		list    = systemcontract.AddressListContractAddr
+		gov     = systemcontract.SysGovContractAddr
+		kept    = common.HexToHash("0x01")
+		changed = common.HexToHash("0x02")
+		cleared = common.HexToHash("0x03")
+	)
+	baseline, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	baseline.SetCode(list, []byte{0x60, 0x00})
+	baseline.SetState(list, kept, common.HexToHash("0xaa"))
+	baseline.SetState(list, changed, common.HexToHash("0xbb"))
+	baseline.SetState(list, cleared, common.HexToHash("0xcc"))
+	baseline.SetCode(gov, []byte{0x60, 0x01})
+	baseline.AddBalance(consensus.FeeRecoder, big.NewInt(100))
+	baseline.AddBalance(header.Coinbase, big.NewInt(100))
+	baseline.IntermediateRoot(true)
+
+	upgraded := baseline.Copy()
+	upgraded.SetCode(list, []byte{0x60, 0x02})
+	upgraded.SetState(list, changed, common.HexToHash("0xdd"))
+	upgraded.SetState(list, cleared, common.Hash{})
+	upgraded.AddBalance(header.Coinbase, big.NewInt(1))
+	upgraded.IntermediateRoot(true)
+
+	sim := new(UpgradeSimulation)
+	if err := engine.diffUpgrade(sim, header, baseline, upgraded, []common.Address{header.Coinbase}); err != nil {
+		t.Fatalf("failed to diff states: %v", err)
+	}
+	if len(sim.Validators) != 0 {
+		t.Errorf("validators diff mismatch: have %d, want 0", len(sim.Validators))
+	}
+	if len(sim.Balances) != 1 || sim.Balances[0].Address != header.Coinbase || sim.Balances[0].After.ToInt().Int64() != 101 {
+		t.Fatalf("balances diff mismatch: %+v", sim.Balances)
+	}
+	if len(sim.Contracts) != 1 {
+		t.Fatalf("contracts diff mismatch: have %d, want 1", len(sim.Contracts))
+	}
+	diff := sim.Contracts[0]
+	if diff.Address != list || diff.CodeHashBefore == diff.CodeHashAfter {
+		t.Fatalf("contract diff mismatch: %+v", diff)
+	}
+	if len(diff.Storage) != 2 {
+		t.Fatalf("storage diff mismatch: have %d slots, want 2", len(diff.Storage))
+	}
+	for _, slot := range diff.Storage {
+		switch slot.Before {
+		case common.HexToHash("0xbb"):
+			if slot.After != common.HexToHash("0xdd") {
+				t.Errorf("changed slot mismatch: have %x, want 0xdd", slot.After)
+			}
+		case common.HexToHash("0xcc"):
+			if slot.After != (common.Hash{}) {
+				t.Errorf("cleared slot mismatch: have %x, want empty", slot.After)
+			}
+		default:
+			t.Errorf("unexpected slot diff: %+v", slot)
+		}
+	}
+}
+
+// Tests that an upgrade is simulated on a copy of the state of the chain, replaying
+// the requested blocks on both the upgraded and the plain state.
+func TestSimulateUpgrade(t *testing.T) {
+	config := *params.AllCongressProtocolChanges
+	config.RedCoastBlock, config.SophonBlock = nil, nil
+	config.Congress = &params.CongressConfig{Period: 3, Epoch: 30000}
+
+	var (
+		db        = rawdb.NewMemoryDatabase()
+		key, _    = crypto.GenerateKey()
+		validator = crypto.PubkeyToAddress(key.PublicKey)
+		extra     = make([]byte, extraVanity+common.AddressLength+extraSeal)
+	)
+	copy(extra[extraVanity:], validator.Bytes())
+	genesis := (&core.Genesis{Config: &config, ExtraData: extra, GasLimit: 8000000, BaseFee: big.NewInt(params.InitialBaseFee)}).MustCommit(db)
+
+	// The blocks are made by a faker and sealed afterwards, the replays only need
+	// their headers to be signed in-turn
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), db, 4, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(validator)
+		b.SetDifficulty(new(big.Int).Set(diffInTurn))
+	})
+	for i, block := range blocks {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		sig, _ := crypto.Sign(SealHash(header).Bytes(), key)
+		copy(header.Extra[extraVanity:], sig)
+		blocks[i] = block.WithSeal(header)
+	}
+	chain, err := core.NewBlockChain(db, nil, &config, ethash.NewFullFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	engine := New(&config, rawdb.NewMemoryDatabase())
+	engine.SetChain(chain)
+	engine.SetStateFn(chain.StateAt)
+
+	sim, err := engine.SimulateUpgrade(chain, systemcontract.SysContractDoubleSign, 2, 2)
+	if err != nil {
+		t.Fatalf("failed to simulate upgrade: %v", err)
+	}
+	if sim.Error != "" || sim.Number != 2 || sim.Replayed != 2 {
+		t.Fatalf("simulation mismatch: number %d, replayed %d, error %q", sim.Number, sim.Replayed, sim.Error)
+	}
+	if len(sim.Contracts) != 1 || sim.Contracts[0].Address != systemcontract.PunishV1ContractAddr {
+		t.Fatalf("contracts diff mismatch: %+v", sim.Contracts)
+	}
+	// The replay stops at the head of the chain
+	if sim, err = engine.SimulateUpgrade(chain, systemcontract.SysContractDoubleSign, 3, 10); err != nil || sim.Replayed != 2 {
+		t.Fatalf("replay past the head mismatch: %+v, err %v", sim, err)
+	}
+	// The requests which can't be simulated are rejected
+	if _, err := engine.SimulateUpgrade(chain, systemcontract.SysContractDoubleSign, 2, MaxSimulatedBlocks+1); err == nil {
+		t.Fatalf("too many blocks accepted")
+	}
+	if _, err := engine.SimulateUpgrade(chain, systemcontract.SysContractVersion(255), 2, 1); !errors.Is(err, errUnsupportedSysContractVersion) {
+		t.Fatalf("unsupported version: have %v, want %v", err, errUnsupportedSysContractVersion)
+	}
+	if _, err := engine.SimulateUpgrade(chain, systemcontract.SysContractDoubleSign, 5, 1); err == nil {
+		t.Fatalf("unknown block accepted")
+	}
+}
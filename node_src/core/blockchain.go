@@ -2178,7 +2178,11 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 
 // reportBlock logs a bad block error.
 func (bc *BlockChain) reportBlock(block *types.Block, receipts types.Receipts, err error) {
-	rawdb.WriteBadBlock(bc.db, block)
+	var reason string
+	if err != nil {
+		reason = err.Error()
+	}
+	rawdb.WriteBadBlockWithReason(bc.db, block, reason)
 
 	var receiptString string
 	for i, receipt := range receipts {
@@ -72,6 +72,10 @@ var (
 	// is higher than the balance of the meta fee address's account.
 	ErrInsufficientMetaFunds = errors.New("meta address insufficient funds for gas * price + value")
 
+	// ErrMetaTxExpired is returned if the fee cover of a meta transaction, or a
+	// sponsored transaction, is no longer valid in the block.
+	ErrMetaTxExpired = errors.New("meta transaction expired")
+
 	// ErrGasUintOverflow is returned when calculating gas usage.
 	ErrGasUintOverflow = errors.New("gas uint64 overflow")
 
@@ -25,6 +25,9 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// JamIndexEvent is posted when the transaction pool evaluates a new jam index.
+type JamIndexEvent struct{ Sample *JamSample }
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
@@ -217,6 +217,22 @@ func WriteHeadFastBlockHash(db ethdb.KeyValueWriter, hash common.Hash) {
 	}
 }
 
+// ReadFinalizedBlockHash retrieves the hash of the latest finalized block.
+func ReadFinalizedBlockHash(db ethdb.KeyValueReader) common.Hash {
+	data, _ := db.Get(headFinalizedBlockKey)
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteFinalizedBlockHash stores the hash of the latest finalized block.
+func WriteFinalizedBlockHash(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Put(headFinalizedBlockKey, hash.Bytes()); err != nil {
+		log.Crit("Failed to store last finalized block's hash", "err", err)
+	}
+}
+
 // ReadLastPivotNumber retrieves the number of the last pivot block. If the node
 // full synced, the last pivot will always be nil.
 func ReadLastPivotNumber(db ethdb.KeyValueReader) *uint64 {
@@ -813,6 +829,7 @@ const badBlockToKeep = 10
 type badBlock struct {
 	Header *types.Header
 	Body   *types.Body
+	Reason string `rlp:"optional"` // Error the block was rejected with, empty for old entries
 }
 
 // badBlockList implements the sort interface to allow sorting a list of
@@ -843,6 +860,25 @@ func ReadBadBlock(db ethdb.Reader, hash common.Hash) *types.Block {
 	return nil
 }
 
+// ReadBadBlockReason retrieves the error the bad block with the corresponding block
+// hash was rejected with.
+func ReadBadBlockReason(db ethdb.Reader, hash common.Hash) string {
+	blob, err := db.Get(badBlockKey)
+	if err != nil {
+		return ""
+	}
+	var badBlocks badBlockList
+	if err := rlp.DecodeBytes(blob, &badBlocks); err != nil {
+		return ""
+	}
+	for _, bad := range badBlocks {
+		if bad.Header.Hash() == hash {
+			return bad.Reason
+		}
+	}
+	return ""
+}
+
 // ReadAllBadBlocks retrieves all the bad blocks in the database.
 // All returned blocks are sorted in reverse order by number.
 func ReadAllBadBlocks(db ethdb.Reader) []*types.Block {
@@ -864,6 +900,12 @@ func ReadAllBadBlocks(db ethdb.Reader) []*types.Block {
 // WriteBadBlock serializes the bad block into the database. If the cumulated
 // bad blocks exceeds the limitation, the oldest will be dropped.
 func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
+	WriteBadBlockWithReason(db, block, "")
+}
+
+// WriteBadBlockWithReason serializes the bad block into the database along with
+// the error it was rejected with.
+func WriteBadBlockWithReason(db ethdb.KeyValueStore, block *types.Block, reason string) {
 	blob, err := db.Get(badBlockKey)
 	if err != nil {
 		log.Warn("Failed to load old bad blocks", "error", err)
@@ -883,6 +925,7 @@ func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
 	badBlocks = append(badBlocks, &badBlock{
 		Header: block.Header(),
 		Body:   block.Body(),
+		Reason: reason,
 	})
 	sort.Sort(sort.Reverse(badBlocks))
 	if len(badBlocks) > badBlockToKeep {
@@ -260,6 +260,56 @@ func TestBadBlockStorage(t *testing.T) {
 	}
 }
 
+// Tests that the reason a bad block was rejected with is stored along with it, and
+// that the entries stored without one are still decoded.
+func TestBadBlockReason(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:      big.NewInt(1),
+		Extra:       []byte("bad block"),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+	})
+	// Store the block the way the bad blocks used to be stored, without a reason
+	legacy := []struct {
+		Header *types.Header
+		Body   *types.Body
+	}{{block.Header(), block.Body()}}
+	blob, err := rlp.EncodeToBytes(legacy)
+	if err != nil {
+		t.Fatalf("Failed to encode legacy bad blocks: %v", err)
+	}
+	if err := db.Put(badBlockKey, blob); err != nil {
+		t.Fatalf("Failed to store legacy bad blocks: %v", err)
+	}
+	if entry := ReadBadBlock(db, block.Hash()); entry == nil || entry.Hash() != block.Hash() {
+		t.Fatalf("Legacy block mismatch: have %v, want %v", entry, block)
+	}
+	if reason := ReadBadBlockReason(db, block.Hash()); reason != "" {
+		t.Fatalf("Unexpected reason for legacy block: %q", reason)
+	}
+	// Write a block along with the reason it was rejected with
+	blockTwo := types.NewBlockWithHeader(&types.Header{
+		Number:      big.NewInt(2),
+		Extra:       []byte("bad block two"),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+	})
+	WriteBadBlockWithReason(db, blockTwo, "invalid merkle root")
+	if reason := ReadBadBlockReason(db, blockTwo.Hash()); reason != "invalid merkle root" {
+		t.Fatalf("Retrieved reason mismatch: have %q, want %q", reason, "invalid merkle root")
+	}
+	if reason := ReadBadBlockReason(db, block.Hash()); reason != "" {
+		t.Fatalf("Unexpected reason for block without one: %q", reason)
+	}
+	if badBlocks := ReadAllBadBlocks(db); len(badBlocks) != 2 {
+		t.Fatalf("Bad blocks mismatch: have %d, want 2", len(badBlocks))
+	}
+}
+
 // Tests block total difficulty storage and retrieval operations.
 func TestTdStorage(t *testing.T) {
 	db := NewMemoryDatabase()
@@ -0,0 +1,151 @@
+package rawdb
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadCongressSnapshot retrieves the serialized Congress snapshot taken at the
+// checkpoint block of an epoch.
+func ReadCongressSnapshot(db ethdb.KeyValueReader, epoch uint64) []byte {
+	data, _ := db.Get(congressEpochSnapshotKey(epoch))
+	return data
+}
+
+// WriteCongressSnapshot stores the serialized Congress snapshot taken at the
+// checkpoint block of an epoch.
+func WriteCongressSnapshot(db ethdb.KeyValueWriter, epoch uint64, snapshot []byte) {
+	if err := db.Put(congressEpochSnapshotKey(epoch), snapshot); err != nil {
+		log.Crit("Failed to store congress snapshot", "err", err)
+	}
+}
+
+// DeleteCongressSnapshot removes the Congress snapshot of an epoch.
+func DeleteCongressSnapshot(db ethdb.KeyValueWriter, epoch uint64) {
+	if err := db.Delete(congressEpochSnapshotKey(epoch)); err != nil {
+		log.Crit("Failed to delete congress snapshot", "err", err)
+	}
+}
+
+// IterateCongressSnapshots returns an iterator over the Congress snapshots in
+// ascending epoch order, starting at the given epoch.
+func IterateCongressSnapshots(db ethdb.Iteratee, from uint64) ethdb.Iterator {
+	return db.NewIterator(CongressEpochSnapshotPrefix, encodeBlockNumber(from))
+}
+
+// CongressSnapshotEpoch returns the epoch of a Congress snapshot key, or false
+// if the key isn't one.
+func CongressSnapshotEpoch(key []byte) (uint64, bool) {
+	if len(key) != len(CongressEpochSnapshotPrefix)+8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(key[len(CongressEpochSnapshotPrefix):]), true
+}
+
+// ReadCongressEvidence retrieves the serialized double sign evidence of a validator
+// at the given height.
+func ReadCongressEvidence(db ethdb.KeyValueReader, validator common.Address, number uint64) []byte {
+	data, _ := db.Get(congressEvidenceKey(validator, number))
+	return data
+}
+
+// WriteCongressEvidence stores the serialized double sign evidence of a validator
+// at the given height.
+func WriteCongressEvidence(db ethdb.KeyValueWriter, validator common.Address, number uint64, evidence []byte) {
+	if err := db.Put(congressEvidenceKey(validator, number), evidence); err != nil {
+		log.Crit("Failed to store congress evidence", "err", err)
+	}
+}
+
+// IterateCongressEvidences returns an iterator over the double sign evidences
+// ordered by validator and height.
+func IterateCongressEvidences(db ethdb.Iteratee) ethdb.Iterator {
+	return db.NewIterator(CongressEvidencePrefix, nil)
+}
+
+// ReadCongressActivity retrieves the serialized sealing activity of an epoch.
+func ReadCongressActivity(db ethdb.KeyValueReader, epoch uint64) []byte {
+	data, _ := db.Get(congressActivityKey(epoch))
+	return data
+}
+
+// WriteCongressActivity stores the serialized sealing activity of an epoch.
+func WriteCongressActivity(db ethdb.KeyValueWriter, epoch uint64, activity []byte) {
+	if err := db.Put(congressActivityKey(epoch), activity); err != nil {
+		log.Crit("Failed to store congress activity", "err", err)
+	}
+}
+
+// DeleteCongressActivity removes the sealing activity of an epoch.
+func DeleteCongressActivity(db ethdb.KeyValueWriter, epoch uint64) {
+	if err := db.Delete(congressActivityKey(epoch)); err != nil {
+		log.Crit("Failed to delete congress activity", "err", err)
+	}
+}
+
+// ReadCongressBlockActivity retrieves the serialized activity of an out-of-turn
+// block by number and seal hash.
+func ReadCongressBlockActivity(db ethdb.KeyValueReader, number uint64, sealHash common.Hash) []byte {
+	data, _ := db.Get(congressBlockActivityKey(number, sealHash))
+	return data
+}
+
+// WriteCongressBlockActivity stores the serialized activity of an out-of-turn
+// block by number and seal hash.
+func WriteCongressBlockActivity(db ethdb.KeyValueWriter, number uint64, sealHash common.Hash, activity []byte) {
+	if err := db.Put(congressBlockActivityKey(number, sealHash), activity); err != nil {
+		log.Crit("Failed to store congress block activity", "err", err)
+	}
+}
+
+// IterateCongressBlockActivities returns an iterator over the activities of the
+// out-of-turn blocks in ascending block number order.
+func IterateCongressBlockActivities(db ethdb.Iteratee) ethdb.Iterator {
+	return db.NewIterator(CongressBlockActivityPrefix, nil)
+}
+
+// CongressBlockActivityNumber returns the block number of a Congress block activity
+// key, or false if the key isn't one.
+func CongressBlockActivityNumber(key []byte) (uint64, bool) {
+	if len(key) != len(CongressBlockActivityPrefix)+8+common.HashLength {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(key[len(CongressBlockActivityPrefix):]), true
+}
+
+// ReadCongressProposal retrieves the serialized execution of a system governance
+// proposal.
+func ReadCongressProposal(db ethdb.KeyValueReader, id *big.Int) []byte {
+	data, _ := db.Get(congressProposalKey(id))
+	return data
+}
+
+// WriteCongressProposal stores the serialized execution of a system governance
+// proposal.
+func WriteCongressProposal(db ethdb.KeyValueWriter, id *big.Int, execution []byte) {
+	if err := db.Put(congressProposalKey(id), execution); err != nil {
+		log.Crit("Failed to store congress proposal execution", "err", err)
+	}
+}
+
+// ReadCongressLastVote retrieves the number and hash of the latest block the local
+// validator voted on, zero if it never voted.
+func ReadCongressLastVote(db ethdb.KeyValueReader) (uint64, common.Hash) {
+	data, _ := db.Get(congressLastVoteKey)
+	if len(data) != 8+common.HashLength {
+		return 0, common.Hash{}
+	}
+	return binary.BigEndian.Uint64(data[:8]), common.BytesToHash(data[8:])
+}
+
+// WriteCongressLastVote stores the number and hash of the latest block the local
+// validator voted on.
+func WriteCongressLastVote(db ethdb.KeyValueWriter, number uint64, hash common.Hash) {
+	if err := db.Put(congressLastVoteKey, append(encodeBlockNumber(number), hash.Bytes()...)); err != nil {
+		log.Crit("Failed to store congress last vote", "err", err)
+	}
+}
@@ -324,6 +324,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		bloomBits       stat
 		cliqueSnaps     stat
 		congressSnaps   stat
+		congressEpochs  stat
+		doubleSigns     stat
 
 		// Ancient store statistics
 		ancientHeadersSize  common.StorageSize
@@ -385,6 +387,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			cliqueSnaps.Add(size)
 		case bytes.HasPrefix(key, []byte("congress-")) && len(key) == 7+common.HashLength:
 			congressSnaps.Add(size)
+		case bytes.HasPrefix(key, CongressEpochSnapshotPrefix) && len(key) == len(CongressEpochSnapshotPrefix)+8:
+			congressEpochs.Add(size)
+		case bytes.HasPrefix(key, CongressEvidencePrefix) && len(key) == len(CongressEvidencePrefix)+common.AddressLength+8:
+			doubleSigns.Add(size)
 		case bytes.HasPrefix(key, []byte("cht-")) ||
 			bytes.HasPrefix(key, []byte("chtIndexV2-")) ||
 			bytes.HasPrefix(key, []byte("chtRootV2-")): // Canonical hash trie
@@ -399,7 +405,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, lastPivotKey,
 				fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
-				uncleanShutdownKey, badBlockKey,
+				uncleanShutdownKey, badBlockKey, headFinalizedBlockKey, congressLastVoteKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -447,6 +453,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
 		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
 		{"Key-Value store", "Congress snapshots", congressSnaps.Size(), congressSnaps.Count()},
+		{"Key-Value store", "Congress epoch snapshots", congressEpochs.Size(), congressEpochs.Count()},
+		{"Key-Value store", "Congress double sign evidences", doubleSigns.Size(), doubleSigns.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Ancient store", "Headers", ancientHeadersSize.String(), ancients.String()},
 		{"Ancient store", "Bodies", ancientBodiesSize.String(), ancients.String()},
@@ -21,6 +21,7 @@ package rawdb
 import (
 	"bytes"
 	"encoding/binary"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -40,6 +41,12 @@ var (
 	// headFastBlockKey tracks the latest known incomplete block's hash during fast sync.
 	headFastBlockKey = []byte("LastFast")
 
+	// headFinalizedBlockKey tracks the latest block finalized by the Congress finality gadget.
+	headFinalizedBlockKey = []byte("LastFinalized")
+
+	// congressLastVoteKey tracks the latest block the local validator voted on for the Congress finality gadget.
+	congressLastVoteKey = []byte("CongressLastVote")
+
 	// lastPivotKey tracks the last pivot block used by fast sync (to reenable on sethead).
 	lastPivotKey = []byte("LastPivot")
 
@@ -94,6 +101,12 @@ var (
 	PreimagePrefix = []byte("secure-key-")      // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
+	CongressEpochSnapshotPrefix = []byte("congress-epoch-")    // CongressEpochSnapshotPrefix + epoch (uint64 big endian) -> congress snapshot
+	CongressEvidencePrefix      = []byte("congress-evidence-") // CongressEvidencePrefix + validator + number (uint64 big endian) -> double sign evidence
+	CongressActivityPrefix      = []byte("congress-activity-") // CongressActivityPrefix + epoch (uint64 big endian) -> epoch activity
+	CongressBlockActivityPrefix = []byte("congress-sealing-")  // CongressBlockActivityPrefix + num (uint64 big endian) + seal hash -> out-of-turn block activity
+	CongressProposalPrefix      = []byte("congress-proposal-") // CongressProposalPrefix + proposal id (uint256 big endian) -> proposal execution
+
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 
@@ -227,6 +240,33 @@ func IsCodeKey(key []byte) (bool, []byte) {
 	return false, nil
 }
 
+// congressEpochSnapshotKey = CongressEpochSnapshotPrefix + epoch (uint64 big endian)
+func congressEpochSnapshotKey(epoch uint64) []byte {
+	return append(append([]byte{}, CongressEpochSnapshotPrefix...), encodeBlockNumber(epoch)...)
+}
+
+// congressEvidenceKey = CongressEvidencePrefix + validator + number (uint64 big endian)
+func congressEvidenceKey(validator common.Address, number uint64) []byte {
+	key := append(append([]byte{}, CongressEvidencePrefix...), validator.Bytes()...)
+	return append(key, encodeBlockNumber(number)...)
+}
+
+// congressActivityKey = CongressActivityPrefix + epoch (uint64 big endian)
+func congressActivityKey(epoch uint64) []byte {
+	return append(append([]byte{}, CongressActivityPrefix...), encodeBlockNumber(epoch)...)
+}
+
+// congressBlockActivityKey = CongressBlockActivityPrefix + num (uint64 big endian) + seal hash
+func congressBlockActivityKey(number uint64, sealHash common.Hash) []byte {
+	key := append(append([]byte{}, CongressBlockActivityPrefix...), encodeBlockNumber(number)...)
+	return append(key, sealHash.Bytes()...)
+}
+
+// congressProposalKey = CongressProposalPrefix + proposal id (uint256 big endian)
+func congressProposalKey(id *big.Int) []byte {
+	return append(append([]byte{}, CongressProposalPrefix...), common.BigToHash(id).Bytes()...)
+}
+
 // configKey = configPrefix + hash
 func configKey(hash common.Hash) []byte {
 	return append(configPrefix, hash.Bytes()...)
@@ -19,6 +19,7 @@ package core
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -341,3 +343,57 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	// Assemble and return the final block for sealing
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
 }
+
+// Tests that the sponsor of a sponsored transaction pays its part of the fee, and
+// that the transaction is invalid after its block limit.
+func TestApplySponsoredTransaction(t *testing.T) {
+	config := *params.TestChainConfig
+	config.LondonBlock, config.SponsoredTxBlock = nil, big.NewInt(0)
+
+	var (
+		signer        = types.MakeSigner(&config, common.Big0)
+		key, _        = crypto.GenerateKey()
+		sponsorKey, _ = crypto.GenerateKey()
+		from          = crypto.PubkeyToAddress(key.PublicKey)
+		sponsor       = crypto.PubkeyToAddress(sponsorKey.PublicKey)
+		to            = common.HexToAddress("0x1234")
+		fee           = big.NewInt(100000)
+	)
+	tx, _ := types.SignTx(types.NewTx(&types.SponsoredTx{
+		ChainID:       config.ChainID,
+		GasPrice:      big.NewInt(1),
+		Gas:           fee.Uint64(),
+		To:            &to,
+		Value:         big.NewInt(1),
+		Data:          common.FromHex(types.MetaPrefix), // Not a meta transaction
+		FeePercent:    10000,
+		BlockNumLimit: 100,
+	}), signer, key)
+	tx, _ = types.SignSponsor(tx, from, sponsorKey)
+
+	apply := func(number int64) (*state.StateDB, *types.Receipt, error) {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetBalance(from, big.NewInt(1))
+		statedb.SetBalance(sponsor, fee)
+
+		header := &types.Header{Number: big.NewInt(number), GasLimit: 10000000, Difficulty: common.Big1}
+		receipt, err := ApplyTransaction(&config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, new(uint64), vm.Config{}, nil)
+		return statedb, receipt, err
+	}
+	statedb, receipt, err := apply(100)
+	if err != nil {
+		t.Fatalf("failed to apply sponsored transaction: %v", err)
+	}
+	if receipt.Type != types.SponsoredTxType || receipt.Status != types.ReceiptStatusSuccessful || receipt.GasUsed != params.TxGas+uint64(types.MetaPrefixBytesLen)*params.TxDataNonZeroGasEIP2028 {
+		t.Fatalf("receipt mismatch: type %d, gas used %d", receipt.Type, receipt.GasUsed)
+	}
+	if balance := statedb.GetBalance(from); balance.Sign() != 0 {
+		t.Errorf("sender balance mismatch: have %v, want 0", balance)
+	}
+	if balance, want := statedb.GetBalance(sponsor), new(big.Int).Sub(fee, new(big.Int).SetUint64(receipt.GasUsed)); balance.Cmp(want) != 0 {
+		t.Errorf("sponsor balance mismatch: have %v, want %v", balance, want)
+	}
+	if _, _, err := apply(101); !errors.Is(err, ErrMetaTxExpired) {
+		t.Errorf("expired transaction: expected %v, got %v", ErrMetaTxExpired, err)
+	}
+}
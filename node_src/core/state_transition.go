@@ -85,6 +85,13 @@ type Message interface {
 	AccessList() types.AccessList
 }
 
+// sponsoredMessage is a message whose fee is partly covered by a sponsor.
+type sponsoredMessage interface {
+	Sponsor() *common.Address
+	FeePercent() uint64
+	BlockNumLimit() uint64
+}
+
 // ExecutionResult includes all output after executing given evm
 // message no matter the execution itself is successful or not.
 type ExecutionResult struct {
@@ -297,6 +304,18 @@ func (st *StateTransition) preCheck() error {
 
 //check if tx is meta tx
 func (st *StateTransition) metaTransactionCheck() error {
+	// Sponsored transactions carry the fee cover in their fields, so the input is
+	// never checked for the meta prefix.
+	if msg, ok := st.msg.(sponsoredMessage); ok && msg.Sponsor() != nil {
+		if limit := msg.BlockNumLimit(); limit < st.evm.Context.BlockNumber.Uint64() {
+			return fmt.Errorf("%w: limit %d, block %d", ErrMetaTxExpired, limit, st.evm.Context.BlockNumber)
+		}
+		st.isMeta = true
+		st.feeAddress = *msg.Sponsor()
+		st.realPayload = st.data
+		st.feePercent = msg.FeePercent()
+		return nil
+	}
 	if types.IsMetaTransaction(st.data) {
 		metaData, err := types.DecodeMetaData(st.data, st.evm.Context.BlockNumber)
 		if err != nil {
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// Metrics for the fairness policies of the pool
+	senderRateLimitMeter   = metrics.NewRegisteredMeter("txpool/fairness/sender/ratelimit", nil)   // Rejected due to the sender token bucket
+	contractRateLimitMeter = metrics.NewRegisteredMeter("txpool/fairness/contract/ratelimit", nil) // Rejected due to the contract token bucket
+	senderEvictionMeter    = metrics.NewRegisteredMeter("txpool/fairness/sender/eviction", nil)    // Evicted from the accounts above their pending share
+	contractEvictionMeter  = metrics.NewRegisteredMeter("txpool/fairness/contract/eviction", nil)  // Evicted from the contracts above their pending share
+	priorityTxMeter        = metrics.NewRegisteredMeter("txpool/fairness/priority", nil)           // Remote transactions admitted through the priority lane
+)
+
+var DefaultTxFairnessConfig = TxFairnessConfig{
+	SenderBurst:   16,
+	ContractBurst: 256,
+}
+
+// TxFairnessConfig are the fairness policies of the pool, protecting it from a
+// single spammer filling the slots with cheap transactions. The local accounts
+// and the allowlisted ones form a priority lane exempt from all of them.
+type TxFairnessConfig struct {
+	Allowlist string // File listing the addresses of the priority lane, one per line
+
+	SenderRate    float64 // Remote transactions per second refilled to each sender, disabled if zero
+	SenderBurst   uint64  // Maximum number of remote transactions a sender can submit at once
+	ContractRate  float64 // Remote transactions per second refilled to each called contract, disabled if zero
+	ContractBurst uint64  // Maximum number of remote transactions a contract can be called with at once
+
+	ContractSlots uint64 // Executable remote transaction slots per contract kept when the pool is full, disabled if zero
+}
+
+func (c *TxFairnessConfig) sanity() TxFairnessConfig {
+	cfg := *c
+	if cfg.SenderRate < 0 {
+		log.Info("FairnessConfig sanity SenderRate", "old", cfg.SenderRate, "new", 0)
+		cfg.SenderRate = 0
+	}
+	if cfg.SenderBurst < 1 {
+		log.Info("FairnessConfig sanity SenderBurst", "old", cfg.SenderBurst, "new", DefaultTxFairnessConfig.SenderBurst)
+		cfg.SenderBurst = DefaultTxFairnessConfig.SenderBurst
+	}
+	if cfg.ContractRate < 0 {
+		log.Info("FairnessConfig sanity ContractRate", "old", cfg.ContractRate, "new", 0)
+		cfg.ContractRate = 0
+	}
+	if cfg.ContractBurst < 1 {
+		log.Info("FairnessConfig sanity ContractBurst", "old", cfg.ContractBurst, "new", DefaultTxFairnessConfig.ContractBurst)
+		cfg.ContractBurst = DefaultTxFairnessConfig.ContractBurst
+	}
+	return cfg
+}
+
+// tokenBucket is the allowance of a single address, refilled over time.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// txLimiter is a set of token buckets, one per address, each one allowing
+// a burst of transactions and refilled at a constant rate.
+type txLimiter struct {
+	rate    float64
+	burst   float64
+	buckets map[common.Address]*tokenBucket
+}
+
+func newTxLimiter(rate float64, burst uint64) *txLimiter {
+	return &txLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[common.Address]*tokenBucket),
+	}
+}
+
+// refill returns the bucket of an address brought up to date, a missing one
+// being full.
+func (l *txLimiter) refill(addr common.Address, now time.Time) *tokenBucket {
+	bucket := l.buckets[addr]
+	if bucket == nil {
+		return &tokenBucket{tokens: l.burst, updated: now}
+	}
+	if elapsed := now.Sub(bucket.updated); elapsed > 0 {
+		bucket.tokens += elapsed.Seconds() * l.rate
+		if bucket.tokens > l.burst {
+			bucket.tokens = l.burst
+		}
+		bucket.updated = now
+	}
+	return bucket
+}
+
+// allow reports whether the address has a token left, always true if the
+// limiter is disabled.
+func (l *txLimiter) allow(addr common.Address, now time.Time) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	return l.refill(addr, now).tokens >= 1
+}
+
+// take consumes a token of the address.
+func (l *txLimiter) take(addr common.Address, now time.Time) {
+	if l.rate <= 0 {
+		return
+	}
+	bucket := l.refill(addr, now)
+	bucket.tokens--
+	l.buckets[addr] = bucket
+}
+
+// cleanup drops the buckets which are full again, as they're equivalent to
+// missing ones.
+func (l *txLimiter) cleanup(now time.Time) {
+	for addr := range l.buckets {
+		if l.refill(addr, now).tokens >= l.burst {
+			delete(l.buckets, addr)
+		}
+	}
+}
+
+// txFairness enforces the fairness policies of the pool.
+//
+// Note, it's not thread safe, the pool lock is expected to be held.
+type txFairness struct {
+	allowlist     map[common.Address]struct{} // Addresses of the priority lane beside the locals
+	senders       *txLimiter                  // Token buckets of the remote senders
+	contracts     *txLimiter                  // Token buckets of the contracts called by remote transactions
+	contractSlots uint64                      // Executable remote transaction slots per contract when the pool is full
+}
+
+func newTxFairness(config TxFairnessConfig) *txFairness {
+	config = (&config).sanity()
+
+	fairness := &txFairness{
+		allowlist:     make(map[common.Address]struct{}),
+		senders:       newTxLimiter(config.SenderRate, config.SenderBurst),
+		contracts:     newTxLimiter(config.ContractRate, config.ContractBurst),
+		contractSlots: config.ContractSlots,
+	}
+	if config.Allowlist != "" {
+		addrs, err := loadAllowlist(config.Allowlist)
+		if err != nil {
+			log.Warn("Failed to load transaction allowlist", "err", err)
+		}
+		for _, addr := range addrs {
+			fairness.allowlist[addr] = struct{}{}
+		}
+		log.Info("Loaded transaction allowlist", "addresses", len(fairness.allowlist))
+	}
+	return fairness
+}
+
+// allowed reports whether the address is in the allowlist.
+func (f *txFairness) allowed(addr common.Address) bool {
+	_, ok := f.allowlist[addr]
+	return ok
+}
+
+// check reports whether the token buckets of the sender and of the called contract
+// (if any) of a remote transaction allow it, without consuming any token.
+func (f *txFairness) check(from common.Address, contract *common.Address, now time.Time) error {
+	if !f.senders.allow(from, now) {
+		senderRateLimitMeter.Mark(1)
+		return ErrSenderRateLimited
+	}
+	if contract != nil && !f.contracts.allow(*contract, now) {
+		contractRateLimitMeter.Mark(1)
+		return ErrContractRateLimited
+	}
+	return nil
+}
+
+// take consumes a token from the sender and the called contract (if any) of a
+// remote transaction, once it's inserted into the pool.
+func (f *txFairness) take(from common.Address, contract *common.Address, now time.Time) {
+	f.senders.take(from, now)
+	if contract != nil {
+		f.contracts.take(*contract, now)
+	}
+}
+
+// cleanup drops the token buckets which are full again.
+func (f *txFairness) cleanup(now time.Time) {
+	f.senders.cleanup(now)
+	f.contracts.cleanup(now)
+}
+
+// loadAllowlist parses a file listing an address per line, skipping the empty
+// lines and the ones starting with '#'.
+func loadAllowlist(path string) ([]common.Address, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		addrs   []common.Address
+		scanner = bufio.NewScanner(file)
+	)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if !common.IsHexAddress(entry) {
+			return addrs, fmt.Errorf("invalid address %q at line %d", entry, line)
+		}
+		addrs = append(addrs, common.HexToAddress(entry))
+	}
+	return addrs, scanner.Err()
+}
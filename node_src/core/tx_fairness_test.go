@@ -0,0 +1,247 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// callTransaction creates a transaction calling into the given address.
+func callTransaction(nonce uint64, to common.Address, key *ecdsa.PrivateKey) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(100), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	return tx
+}
+
+// Tests that the token buckets allow a burst, get refilled at the configured
+// rate, and are dropped once full again.
+func TestTxLimiter(t *testing.T) {
+	var (
+		limiter = newTxLimiter(1, 2)
+		addr    = common.HexToAddress("0x01")
+		now     = time.Unix(1000, 0)
+	)
+	for i := 0; i < 2; i++ {
+		if !limiter.allow(addr, now) {
+			t.Fatalf("transaction %d of the burst denied", i)
+		}
+		limiter.take(addr, now)
+	}
+	if limiter.allow(addr, now) {
+		t.Fatalf("transaction beyond the burst allowed")
+	}
+	if limiter.allow(addr, now.Add(500*time.Millisecond)) {
+		t.Fatalf("transaction allowed before a token is refilled")
+	}
+	if !limiter.allow(addr, now.Add(time.Second)) {
+		t.Fatalf("transaction denied after a token is refilled")
+	}
+	limiter.cleanup(now.Add(time.Second))
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("partially refilled bucket dropped")
+	}
+	limiter.cleanup(now.Add(time.Hour))
+	if len(limiter.buckets) != 0 {
+		t.Fatalf("full bucket not dropped")
+	}
+	// A disabled limiter allows everything
+	disabled := newTxLimiter(0, 1)
+	for i := 0; i < 10; i++ {
+		disabled.take(addr, now)
+	}
+	if !disabled.allow(addr, now) {
+		t.Fatalf("disabled limiter denied a transaction")
+	}
+}
+
+// Tests that the remote transactions are rate limited per sender and per called
+// contract, except for the ones in the priority lane.
+func TestTransactionFairnessRateLimit(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	keys := make([]*ecdsa.PrivateKey, 6)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	allowlist := filepath.Join(dir, "allowlist.txt")
+	content := "# priority lane\n\n" + crypto.PubkeyToAddress(keys[3].PublicKey).Hex() + "\n"
+	if err := ioutil.WriteFile(allowlist, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write allowlist: %v", err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Fairness = TxFairnessConfig{
+		Allowlist:     allowlist,
+		SenderRate:    0.001,
+		SenderBurst:   2,
+		ContractRate:  0.001,
+		ContractBurst: 3,
+	}
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	contract := common.HexToAddress("0xc0")
+	pool.mu.Lock()
+	pool.currentState.SetCode(contract, []byte{0x00})
+	pool.mu.Unlock()
+	for _, key := range keys {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	tests := []struct {
+		tx    *types.Transaction
+		local bool
+		err   error
+	}{
+		// A plain sender is limited to its burst
+		{transaction(0, 100000, keys[0]), false, nil},
+		{transaction(1, 100000, keys[0]), false, nil},
+		{transaction(2, 100000, keys[0]), false, ErrSenderRateLimited},
+		// A contract is limited to its burst across senders
+		{callTransaction(0, contract, keys[1]), false, nil},
+		{callTransaction(1, contract, keys[1]), false, nil},
+		{callTransaction(0, contract, keys[2]), false, nil},
+		{callTransaction(1, contract, keys[2]), false, ErrContractRateLimited},
+		// The allowlisted and local senders aren't limited
+		{callTransaction(0, contract, keys[3]), false, nil},
+		{callTransaction(1, contract, keys[3]), false, nil},
+		{callTransaction(2, contract, keys[3]), false, nil},
+		{callTransaction(0, contract, keys[4]), true, nil},
+		{callTransaction(1, contract, keys[4]), true, nil},
+		{callTransaction(2, contract, keys[4]), true, nil},
+		// A transaction rejected after the rate limits doesn't consume a token
+		{transaction(0, 100000, keys[5]), false, nil},
+		{transaction(0, 100001, keys[5]), false, ErrReplaceUnderpriced},
+		{transaction(1, 100000, keys[5]), false, nil},
+		{transaction(2, 100000, keys[5]), false, ErrSenderRateLimited},
+	}
+	for i, tt := range tests {
+		var err error
+		if tt.local {
+			err = pool.AddLocal(tt.tx)
+		} else {
+			err = pool.addRemoteSync(tt.tx)
+		}
+		if err != tt.err {
+			t.Errorf("transaction %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// The transactions added back by the pool itself, like the reinjected ones,
+	// aren't limited
+	pool.mu.Lock()
+	errs, dirty := pool.addTxsLocked([]*types.Transaction{transaction(2, 100000, keys[0])}, false, false)
+	pool.mu.Unlock()
+	if errs[0] != nil {
+		t.Fatalf("re-added transaction limited: %v", errs[0])
+	}
+	<-pool.requestPromoteExecutables(dirty)
+
+	if pending, _ := pool.Stats(); pending != 14 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 14)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that when the pending pool is full, the trailing calls into the contracts
+// above their share are evicted first, leaving the other transactions alone.
+func TestTransactionFairnessContractSlots(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.AccountSlots = 16
+	config.GlobalSlots = 3
+	config.Fairness.ContractSlots = 1
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	contract := common.HexToAddress("0xc0")
+	pool.mu.Lock()
+	pool.currentState.SetCode(contract, []byte{0x00})
+	pool.mu.Unlock()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	// Two crowding callers, one of them ending with a plain transfer, and an
+	// unrelated account
+	txs := []*types.Transaction{
+		callTransaction(0, contract, keys[0]),
+		callTransaction(1, contract, keys[0]),
+		callTransaction(2, contract, keys[0]),
+		callTransaction(0, contract, keys[1]),
+		callTransaction(1, contract, keys[1]),
+		transaction(2, 100000, keys[1]),
+		transaction(0, 100000, keys[2]),
+	}
+	for i, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("transaction %d: failed to add: %v", i, err)
+		}
+	}
+	// The calls of the largest caller are all evicted, the other one being shielded
+	// by its trailing transfer even if the pool stays above its limit
+	pending, queued := pool.Stats()
+	if pending != 4 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 4)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	for i, tx := range txs {
+		if have, want := pool.Has(tx.Hash()), i >= 3; have != want {
+			t.Errorf("transaction %d: presence mismatch: have %v, want %v", i, have, want)
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the allowlist file skips the comments and rejects invalid entries.
+func TestLoadAllowlist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "allowlist.txt")
+	ioutil.WriteFile(path, []byte("# comment\n0x0000000000000000000000000000000000000001\n\n  0x0000000000000000000000000000000000000002  \n"), 0644)
+	addrs, err := loadAllowlist(path)
+	if err != nil {
+		t.Fatalf("failed to load allowlist: %v", err)
+	}
+	if len(addrs) != 2 || addrs[0] != common.HexToAddress("0x01") || addrs[1] != common.HexToAddress("0x02") {
+		t.Fatalf("allowlist mismatch: %v", addrs)
+	}
+	ioutil.WriteFile(path, []byte("0x0000000000000000000000000000000000000001\nnot an address\n"), 0644)
+	if _, err := loadAllowlist(path); err == nil {
+		t.Fatalf("invalid allowlist loaded")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 )
@@ -23,6 +24,7 @@ var DefaultJamConfig = TxJamConfig{
 	UnderPricedFactor:   3,
 	PendingFactor:       1,
 	MaxValidPendingSecs: 300,
+	HistorySize:         1200,
 }
 
 type TxJamConfig struct {
@@ -32,6 +34,19 @@ type TxJamConfig struct {
 	PendingFactor     int
 
 	MaxValidPendingSecs int //
+
+	HistorySize int // how many jam samples to keep in the history
+}
+
+// JamSample is a jam index along with the components and pending durations it
+// was evaluated from.
+type JamSample struct {
+	Time         uint64   `json:"time"`         // Unix time of the sample in seconds
+	Index        int      `json:"index"`        // Jam index, the weighted sum of the components
+	UnderPriced  int      `json:"underPriced"`  // Number of underpriced txs rejected during the last period
+	Pending      int      `json:"pending"`      // Percentage of the pending txs weighted by how many times they waited JamSecs
+	Percentiles  []uint64 `json:"percentiles"`  // Min, deciles and max of the pending durations in milliseconds
+	PendingCount int      `json:"pendingCount"` // Number of pending txs the durations are taken from
 }
 
 func (c *TxJamConfig) sanity() TxJamConfig {
@@ -56,6 +71,10 @@ func (c *TxJamConfig) sanity() TxJamConfig {
 		log.Info("JamConfig sanity MaxValidPendingSecs", "old", cfg.MaxValidPendingSecs, "new", DefaultJamConfig.MaxValidPendingSecs)
 		cfg.MaxValidPendingSecs = DefaultJamConfig.MaxValidPendingSecs
 	}
+	if cfg.HistorySize < 1 {
+		log.Info("JamConfig sanity HistorySize", "old", cfg.HistorySize, "new", DefaultJamConfig.HistorySize)
+		cfg.HistorySize = DefaultJamConfig.HistorySize
+	}
 	return cfg
 }
 
@@ -68,6 +87,11 @@ type txJamIndexer struct {
 	undCounter      *underPricedCounter
 	currentJamIndex int
 
+	history     []*JamSample // ring buffer of the latest samples
+	historyNext int          // position of the next sample in the ring buffer
+	historyLen  int          // number of samples in the ring buffer
+	jamFeed     event.Feed
+
 	pendingLock sync.Mutex
 	jamLock     sync.RWMutex
 
@@ -82,6 +106,7 @@ func newTxJamIndexer(cfg TxJamConfig, pool *TxPool) *txJamIndexer {
 		cfg:         cfg,
 		pool:        pool,
 		undCounter:  newUnderPricedCounter(cfg.PeriodsSecs),
+		history:     make([]*JamSample, cfg.HistorySize),
 		quit:        make(chan struct{}),
 		chainHeadCh: make(chan *types.Header, 1),
 	}
@@ -104,6 +129,43 @@ func (indexer *txJamIndexer) JamIndex() int {
 	return indexer.currentJamIndex
 }
 
+// JamIndexHistory returns the latest window samples of the jam index, from the
+// oldest to the newest. All the samples kept are returned if window is zero.
+func (indexer *txJamIndexer) JamIndexHistory(window int) []*JamSample {
+	indexer.jamLock.RLock()
+	defer indexer.jamLock.RUnlock()
+
+	if window <= 0 || window > indexer.historyLen {
+		window = indexer.historyLen
+	}
+	size := len(indexer.history)
+	samples := make([]*JamSample, 0, window)
+	for i := window; i > 0; i-- {
+		samples = append(samples, indexer.history[(indexer.historyNext-i+size)%size])
+	}
+	return samples
+}
+
+// SubscribeJamIndex registers a subscription of the jam samples.
+func (indexer *txJamIndexer) SubscribeJamIndex(ch chan<- JamIndexEvent) event.Subscription {
+	return indexer.jamFeed.Subscribe(ch)
+}
+
+// addSample records a new sample in the history, overwriting the oldest one once
+// the history is full.
+func (indexer *txJamIndexer) addSample(sample *JamSample) {
+	indexer.jamLock.Lock()
+	indexer.currentJamIndex = sample.Index
+	indexer.history[indexer.historyNext] = sample
+	indexer.historyNext = (indexer.historyNext + 1) % len(indexer.history)
+	if indexer.historyLen < len(indexer.history) {
+		indexer.historyLen++
+	}
+	indexer.jamLock.Unlock()
+
+	indexer.jamFeed.Send(JamIndexEvent{Sample: sample})
+}
+
 func (indexer *txJamIndexer) updateLoop() {
 	tick := time.NewTicker(time.Second * time.Duration(indexer.cfg.PeriodsSecs))
 	defer tick.Stop()
@@ -118,10 +180,6 @@ func (indexer *txJamIndexer) updateLoop() {
 			if d == 0 && len(pendings) == 0 {
 				break
 			}
-			// flatten
-			var p int
-			max := indexer.cfg.MaxValidPendingSecs
-			jamsecs := indexer.cfg.JamSecs
 			maxGas := uint64(10000000)
 			if indexer.head != nil {
 				maxGas = (indexer.head.GasLimit / 10) * 6
@@ -134,54 +192,68 @@ func (indexer *txJamIndexer) updateLoop() {
 						tx.Gas() > maxGas {
 						continue
 					}
-
-					dur := time.Since(tx.LocalSeenTime())
-					sec := int(dur / time.Second)
-					if sec > max {
-						continue
-					}
-
-					durs = append(durs, dur)
-					if sec >= jamsecs {
-						p += sec / jamsecs
-					}
-				}
-			}
-			nTotal := len(durs)
-
-			if nTotal == 0 {
-				p = 0
-			} else {
-				p = 100 * p / nTotal
-			}
-
-			idx := d*indexer.cfg.UnderPricedFactor + p*indexer.cfg.PendingFactor
-			indexer.jamLock.Lock()
-			indexer.currentJamIndex = idx
-			indexer.jamLock.Unlock()
-			jamIndexMeter.Update(int64(idx))
-
-			var dists []time.Duration
-			sort.Slice(durs, func(i, j int) bool {
-				return durs[i] < durs[j]
-			})
-			if nTotal > 10 {
-				dists = append(dists, durs[0])
-				for i := 1; i < 10; i++ {
-					dists = append(dists, durs[nTotal*i/10])
+					durs = append(durs, time.Since(tx.LocalSeenTime()))
 				}
-				dists = append(dists, durs[nTotal-1])
-			} else {
-				dists = durs
 			}
+			sample := EvalJamSample(indexer.cfg, d, durs, time.Now())
+			jamIndexMeter.Update(int64(sample.Index))
+			indexer.addSample(sample)
 
-			log.Trace("TxJamIndexer", "jamIndex", idx, "d", d, "p", p, "n", nTotal, "dists", dists)
+			log.Trace("TxJamIndexer", "jamIndex", sample.Index, "d", d, "p", sample.Pending, "n", sample.PendingCount, "dists", sample.Percentiles)
 		case <-indexer.quit:
 			return
 		}
 	}
 }
 
+// EvalJamSample evaluates the jam index from the number of underpriced txs rejected
+// during the last period and how long the pending txs have been waiting for.
+func EvalJamSample(cfg TxJamConfig, underPriced int, durs []time.Duration, now time.Time) *JamSample {
+	// flatten
+	var p int
+	max := cfg.MaxValidPendingSecs
+	jamsecs := cfg.JamSecs
+	valid := make([]time.Duration, 0, len(durs))
+	for _, dur := range durs {
+		sec := int(dur / time.Second)
+		if sec > max {
+			continue
+		}
+		valid = append(valid, dur)
+		if sec >= jamsecs {
+			p += sec / jamsecs
+		}
+	}
+	nTotal := len(valid)
+
+	if nTotal == 0 {
+		p = 0
+	} else {
+		p = 100 * p / nTotal
+	}
+	idx := underPriced*cfg.UnderPricedFactor + p*cfg.PendingFactor
+
+	sort.Slice(valid, func(i, j int) bool {
+		return valid[i] < valid[j]
+	})
+	var percentiles []uint64
+	if nTotal > 0 {
+		percentiles = append(percentiles, uint64(valid[0]/time.Millisecond))
+		for i := 1; i < 10; i++ {
+			percentiles = append(percentiles, uint64(valid[nTotal*i/10]/time.Millisecond))
+		}
+		percentiles = append(percentiles, uint64(valid[nTotal-1]/time.Millisecond))
+	}
+	return &JamSample{
+		Time:         uint64(now.Unix()),
+		Index:        idx,
+		UnderPriced:  underPriced,
+		Pending:      p,
+		Percentiles:  percentiles,
+		PendingCount: nTotal,
+	}
+}
+
 func (indexer *txJamIndexer) UpdateHeader(h *types.Header) {
 	indexer.chainHeadCh <- h
 }
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// Tests that the jam history keeps the latest samples in order, and that the new
+// samples are sent to the subscribers.
+func TestJamIndexHistory(t *testing.T) {
+	indexer := &txJamIndexer{history: make([]*JamSample, 3)}
+	if samples := indexer.JamIndexHistory(0); len(samples) != 0 {
+		t.Fatalf("history of empty indexer: have %d samples, want 0", len(samples))
+	}
+	events := make(chan JamIndexEvent, 5)
+	sub := indexer.SubscribeJamIndex(events)
+	defer sub.Unsubscribe()
+
+	for i := 1; i <= 5; i++ {
+		indexer.addSample(&JamSample{Index: i})
+	}
+	if index := indexer.JamIndex(); index != 5 {
+		t.Errorf("jam index mismatch: have %d, want 5", index)
+	}
+	tests := []struct {
+		window int
+		want   []int
+	}{
+		{0, []int{3, 4, 5}},
+		{2, []int{4, 5}},
+		{10, []int{3, 4, 5}},
+	}
+	for _, tt := range tests {
+		samples := indexer.JamIndexHistory(tt.window)
+		if len(samples) != len(tt.want) {
+			t.Errorf("window %d: have %d samples, want %d", tt.window, len(samples), len(tt.want))
+			continue
+		}
+		for i, sample := range samples {
+			if sample.Index != tt.want[i] {
+				t.Errorf("window %d: sample %d index mismatch: have %d, want %d", tt.window, i, sample.Index, tt.want[i])
+			}
+		}
+	}
+	for i := 1; i <= 5; i++ {
+		select {
+		case ev := <-events:
+			if ev.Sample.Index != i {
+				t.Errorf("event %d index mismatch: have %d, want %d", i, ev.Sample.Index, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not sent", i)
+		}
+	}
+}
+
+// Tests that the jam index weights the underpriced txs and the pending txs which
+// waited for longer than JamSecs, ignoring the ones pending for too long.
+func TestEvalJamSample(t *testing.T) {
+	cfg := TxJamConfig{JamSecs: 10, UnderPricedFactor: 3, PendingFactor: 2, MaxValidPendingSecs: 60}
+	durs := []time.Duration{time.Second, 5 * time.Second, 10 * time.Second, 25 * time.Second, 2 * time.Minute}
+
+	sample := EvalJamSample(cfg, 4, durs, time.Unix(100, 0))
+	if sample.PendingCount != 4 {
+		t.Errorf("pending count mismatch: have %d, want 4", sample.PendingCount)
+	}
+	// 1 + 2 jams over 4 pending txs
+	if sample.Pending != 75 {
+		t.Errorf("pending component mismatch: have %d, want 75", sample.Pending)
+	}
+	if sample.Index != 4*3+75*2 {
+		t.Errorf("jam index mismatch: have %d, want %d", sample.Index, 4*3+75*2)
+	}
+	if len(sample.Percentiles) != 11 || sample.Percentiles[0] != 1000 || sample.Percentiles[10] != 25000 {
+		t.Errorf("percentiles mismatch: %v", sample.Percentiles)
+	}
+	if sample.Time != 100 {
+		t.Errorf("time mismatch: have %d, want 100", sample.Time)
+	}
+}
@@ -305,7 +305,7 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 	}
 	// Otherwise overwrite the old transaction with the current one
 	l.txs.Put(tx)
-	if cost := tx.Cost(); l.costcap.Cmp(cost) < 0 {
+	if cost := senderCost(tx); l.costcap.Cmp(cost) < 0 {
 		l.costcap = cost
 	}
 	if gas := tx.Gas(); l.gascap < gas {
@@ -340,7 +340,7 @@ func (l *txList) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions
 
 	// Filter out all the transactions above the account's funds
 	removed := l.txs.Filter(func(tx *types.Transaction) bool {
-		return tx.Gas() > gasLimit || tx.Cost().Cmp(costLimit) > 0
+		return tx.Gas() > gasLimit || senderCost(tx).Cmp(costLimit) > 0
 	})
 
 	if len(removed) == 0 {
@@ -634,3 +634,20 @@ func (l *txPricedList) SetBaseFee(baseFee *big.Int) {
 	l.urgent.baseFee = baseFee
 	l.Reheap()
 }
+
+// senderCost returns the funds the sender of a transaction needs: its full cost,
+// or the value and the part of the fee not covered for meta and sponsored
+// transactions.
+func senderCost(tx *types.Transaction) *big.Int {
+	if tx.Type() == types.SponsoredTxType {
+		fee, _ := types.MetaFees(tx.FeePercent(), tx.Gas(), tx.GasPrice())
+		return fee.Add(fee, tx.Value())
+	}
+	if types.IsMetaTransaction(tx.Data()) {
+		if metadata, err := types.DecodeMetaData(tx.Data(), common.Big0); err == nil {
+			fee, _ := types.MetaFees(metadata.FeePercent, tx.Gas(), tx.GasPrice())
+			return fee.Add(fee, tx.Value())
+		}
+	}
+	return tx.Cost()
+}
@@ -18,6 +18,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"math"
 	"math/big"
@@ -86,6 +87,14 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrSenderRateLimited is returned if the sender of a remote transaction has
+	// exhausted its token bucket.
+	ErrSenderRateLimited = errors.New("sender rate limited")
+
+	// ErrContractRateLimited is returned if the contract called by a remote
+	// transaction has exhausted its token bucket.
+	ErrContractRateLimited = errors.New("contract rate limited")
 )
 
 var (
@@ -107,6 +116,9 @@ var (
 	queuedNofundsMeter   = metrics.NewRegisteredMeter("txpool/queued/nofunds", nil)   // Dropped due to out-of-funds
 	queuedEvictionMeter  = metrics.NewRegisteredMeter("txpool/queued/eviction", nil)  // Dropped due to lifetime
 
+	// Metrics for the meta transactions
+	metaEvictionMeter = metrics.NewRegisteredMeter("txpool/meta/eviction", nil) // Dropped due to an expired or unpayable fee cover
+
 	// General tx metrics
 	knownTxMeter       = metrics.NewRegisteredMeter("txpool/known", nil)
 	validTxMeter       = metrics.NewRegisteredMeter("txpool/valid", nil)
@@ -161,6 +173,10 @@ type TxPoolConfig struct {
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
+	Remotes         string        // Snapshot of the remote transactions to survive node restarts, disabled if empty
+	RemotesInterval time.Duration // Time interval to regenerate the remote transaction snapshot
+	RemotesSize     uint64        // Maximum size in bytes of the remote transaction snapshot
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -172,6 +188,7 @@ type TxPoolConfig struct {
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
 
 	JamConfig TxJamConfig
+	Fairness  TxFairnessConfig
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -180,6 +197,9 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
 
+	RemotesInterval: 10 * time.Minute,
+	RemotesSize:     32 * 1024 * 1024,
+
 	PriceLimit: 1,
 	PriceBump:  10,
 
@@ -191,6 +211,7 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	Lifetime: 3 * time.Hour,
 
 	JamConfig: DefaultJamConfig,
+	Fairness:  DefaultTxFairnessConfig,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -201,6 +222,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.RemotesInterval < time.Second {
+		log.Warn("Sanitizing invalid txpool remotes interval", "provided", conf.RemotesInterval, "updated", time.Second)
+		conf.RemotesInterval = time.Second
+	}
 	if conf.PriceLimit < 1 {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultTxPoolConfig.PriceLimit)
 		conf.PriceLimit = DefaultTxPoolConfig.PriceLimit
@@ -253,20 +278,26 @@ type TxPool struct {
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 	eip1559  bool // Fork indicator whether we are using EIP-1559 type transactions.
 
+	sponsored bool // Fork indicator whether we are using sponsored transactions.
+
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
+	nextNumber    uint64         // Number of the next block, meta transactions expire past it
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
+	remotes *txSnapshot // Snapshot of the remote transactions to back up to disk
 
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
+	metaTxs map[common.Hash]*metaCover   // Fee covers of the meta transactions validated by the pool
 
 	jamIndexer *txJamIndexer // tx jam indexer
+	fairness   *txFairness   // Fairness policies limiting the remote transactions
 
 	txValidator    exTxValidator // A specific consensus can use this to do some extra validation to a transaction
 	nextFakeHeader *types.Header // A fake header of next block for extra transaction validation
@@ -309,6 +340,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		queue:           make(map[common.Address]*txList),
 		beats:           make(map[common.Address]time.Time),
 		all:             newTxLookup(),
+		metaTxs:         make(map[common.Hash]*metaCover),
 		chainHeadCh:     make(chan ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
@@ -319,6 +351,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
 	}
 	pool.jamIndexer = newTxJamIndexer(config.JamConfig, pool)
+	pool.fairness = newTxFairness(config.Fairness)
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
 		log.Info("Setting new local account", "address", addr)
@@ -342,6 +375,9 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	if config.Remotes != "" {
+		pool.remotes = newTxSnapshot(config.Remotes, config.RemotesSize)
+	}
 
 	// Subscribe events from blockchain and start the main event loop.
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
@@ -357,6 +393,22 @@ func (pool *TxPool) InitExTxValidator(v exTxValidator) {
 	pool.txValidator = v
 }
 
+// LoadRemotes loads the remote transactions snapshotted before the last shutdown,
+// revalidating them against the current head and the extra validator, so it's
+// meant to be called once the latter is set.
+func (pool *TxPool) LoadRemotes() {
+	if pool.remotes == nil {
+		return
+	}
+	// The reloaded transactions were already admitted once, so they aren't limited
+	load := func(txs []*types.Transaction) []error {
+		return pool.addTxs(txs, false, true, false)
+	}
+	if err := pool.remotes.load(load); err != nil {
+		log.Warn("Failed to load remote transaction snapshot", "err", err)
+	}
+}
+
 // loop is the transaction pool's main event loop, waiting for and reacting to
 // outside blockchain events as well as for various reporting and transaction
 // eviction events.
@@ -369,12 +421,14 @@ func (pool *TxPool) loop() {
 		report  = time.NewTicker(statsReportInterval)
 		evict   = time.NewTicker(evictionInterval)
 		journal = time.NewTicker(pool.config.Rejournal)
+		remotes = time.NewTicker(pool.config.RemotesInterval)
 		// Track the previous head headers for transaction reorgs
 		head = pool.chain.CurrentBlock()
 	)
 	defer report.Stop()
 	defer evict.Stop()
 	defer journal.Stop()
+	defer remotes.Stop()
 
 	// Notify tests that the init phase is done
 	close(pool.initDoneCh)
@@ -422,6 +476,7 @@ func (pool *TxPool) loop() {
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			pool.fairness.cleanup(time.Now())
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
@@ -433,6 +488,12 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+
+		// Handle remote transaction snapshot checkpoints
+		case <-remotes.C:
+			if pool.remotes != nil {
+				pool.snapshotRemotes()
+			}
 		}
 	}
 }
@@ -451,6 +512,9 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.remotes != nil {
+		pool.snapshotRemotes()
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -599,6 +663,18 @@ func (pool *TxPool) JamIndex() int {
 	return pool.jamIndexer.JamIndex()
 }
 
+// JamIndexHistory returns the latest window samples of the jam index, from the
+// oldest to the newest, or all the samples kept if window is zero.
+func (pool *TxPool) JamIndexHistory(window int) []*JamSample {
+	return pool.jamIndexer.JamIndexHistory(window)
+}
+
+// SubscribeJamIndexEvent registers a subscription of JamIndexEvent and starts
+// sending event to the given channel.
+func (pool *TxPool) SubscribeJamIndexEvent(ch chan<- JamIndexEvent) event.Subscription {
+	return pool.scope.Track(pool.jamIndexer.SubscribeJamIndex(ch))
+}
+
 // local retrieves all currently known local transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -615,81 +691,240 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// remote retrieves all currently known remote transactions, grouped by origin
+// account and sorted by nonce.
+func (pool *TxPool) remote() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, pending := range pool.pending {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], pending.Flatten()...)
+		}
+	}
+	for addr, queued := range pool.queue {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], queued.Flatten()...)
+		}
+	}
+	return txs
+}
+
+// snapshotRemotes regenerates the remote transaction snapshot.
+func (pool *TxPool) snapshotRemotes() {
+	pool.mu.RLock()
+	remotes := pool.remote()
+	pool.mu.RUnlock()
+
+	if err := pool.remotes.write(remotes); err != nil {
+		log.Warn("Failed to snapshot remote transactions", "err", err)
+	}
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
-// rules and adheres to some heuristic limits of the local node (price and size).
-func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+// rules and adheres to some heuristic limits of the local node (price and size),
+// returning the fee cover of meta transactions.
+func (pool *TxPool) validateTx(tx *types.Transaction, local bool) (*metaCover, error) {
 	// Accept only legacy transactions until EIP-2718/2930 activates.
-	if !pool.eip2718 && tx.Type() != types.LegacyTxType {
-		return ErrTxTypeNotSupported
+	if !pool.eip2718 && tx.Type() != types.LegacyTxType && tx.Type() != types.SponsoredTxType {
+		return nil, ErrTxTypeNotSupported
 	}
 	// Reject dynamic fee transactions until EIP-1559 activates.
 	if !pool.eip1559 && tx.Type() == types.DynamicFeeTxType {
-		return ErrTxTypeNotSupported
+		return nil, ErrTxTypeNotSupported
+	}
+	// Reject sponsored transactions until their fork activates, regardless of EIP-2718.
+	if !pool.sponsored && tx.Type() == types.SponsoredTxType {
+		return nil, ErrTxTypeNotSupported
 	}
 	// Reject transactions over defined size to prevent DOS attacks
 	if uint64(tx.Size()) > txMaxSize {
-		return ErrOversizedData
+		return nil, ErrOversizedData
 	}
 	// Transactions can't be negative. This may never happen using RLP decoded
 	// transactions but may occur if you create a transaction using the RPC.
 	if tx.Value().Sign() < 0 {
-		return ErrNegativeValue
+		return nil, ErrNegativeValue
 	}
 	// Ensure the transaction doesn't exceed the current block limit gas.
 	if pool.currentMaxGas < tx.Gas() {
-		return ErrGasLimit
+		return nil, ErrGasLimit
 	}
 	// Sanity check for extremely large numbers
 	if tx.GasFeeCap().BitLen() > 256 {
-		return ErrFeeCapVeryHigh
+		return nil, ErrFeeCapVeryHigh
 	}
 	if tx.GasTipCap().BitLen() > 256 {
-		return ErrTipVeryHigh
+		return nil, ErrTipVeryHigh
 	}
 	// Ensure gasFeeCap is greater than or equal to gasTipCap.
 	if tx.GasFeeCapIntCmp(tx.GasTipCap()) < 0 {
-		return ErrTipAboveFeeCap
+		return nil, ErrTipAboveFeeCap
 	}
 	// Make sure the transaction is signed properly.
 	from, err := types.Sender(pool.signer, tx)
 	if err != nil {
-		return ErrInvalidSender
+		return nil, ErrInvalidSender
 	}
 	// Drop non-local transactions under our own minimal accepted gas price or tip.
 	pendingBaseFee := pool.priced.urgent.baseFee
 	if !local && tx.EffectiveGasTipIntCmp(pool.gasPrice, pendingBaseFee) < 0 {
-		return ErrUnderpriced
+		return nil, ErrUnderpriced
 	}
 	// Ensure the transaction adheres to nonce ordering
 	if pool.currentState.GetNonce(from) > tx.Nonce() {
-		return ErrNonceTooLow
+		return nil, ErrNonceTooLow
+	}
+	// Meta transactions have part of their fee covered by a fee address
+	cover, err := pool.validateMetaTx(from, tx)
+	if err != nil {
+		return nil, err
 	}
 	// Transactor should have enough funds to cover the costs
-	// cost == V + GP * GL
-	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
-		return ErrInsufficientFunds
+	// cost == V + GP * GL, less the fee cover of meta transactions
+	if pool.currentState.GetBalance(from).Cmp(senderCost(tx)) < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	// Ensure the transaction has more gas than the basic tx fee, charged on the
+	// payload only for meta transactions.
+	data := tx.Data()
+	if cover != nil {
+		data = cover.payload
 	}
-	// Ensure the transaction has more gas than the basic tx fee.
-	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
+	intrGas, err := IntrinsicGas(data, tx.AccessList(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if tx.Gas() < intrGas {
-		return ErrIntrinsicGas
+		return nil, ErrIntrinsicGas
 	}
 
 	// do some extra validation if needed
 	if pool.txValidator != nil && !pool.disableExValidate {
 		err := pool.txValidator.ValidateTx(from, tx, pool.nextFakeHeader, pool.currentState)
 		if err == types.ErrAddressDenied {
-			return err
+			return nil, err
 		}
 		if err != nil {
 			log.Info("ValidateTx error", "err", err)
 			pool.disableExValidate = true
 		}
 	}
-	return nil
+	return cover, nil
+}
+
+// metaCover is the fee cover of a meta transaction.
+type metaCover struct {
+	from          common.Address
+	nonce         uint64
+	feeAddress    common.Address
+	fee           *big.Int // Part of the fee charged to the fee address
+	commitment    *big.Int // Funds the fee address needs for the transaction, including the sender cost if it's the sender
+	blockNumLimit uint64   // Last block the fee cover is valid in
+	payload       []byte   // Input of the transaction without the fee cover
+}
+
+// validateMetaTx checks the fee cover of a meta or sponsored transaction, returning
+// nil for other transactions. Like StateTransition.buyGasMeta, the fee address must
+// afford its part of the fee on its own, on top of the transactions it already
+// covers in the pool.
+func (pool *TxPool) validateMetaTx(from common.Address, tx *types.Transaction) (*metaCover, error) {
+	var cover *metaCover
+	switch {
+	case tx.Type() == types.SponsoredTxType:
+		sponsor, err := types.Sponsor(tx, from)
+		if err != nil {
+			return nil, err
+		}
+		_, fee := types.MetaFees(tx.FeePercent(), tx.Gas(), tx.GasPrice())
+		cover = &metaCover{
+			feeAddress:    sponsor,
+			fee:           fee,
+			blockNumLimit: tx.BlockNumLimit(),
+			payload:       tx.Data(),
+		}
+	case types.IsMetaTransaction(tx.Data()):
+		// The fee address signs the gas price, which only legacy transactions fix
+		if tx.Type() == types.DynamicFeeTxType {
+			return nil, types.ErrMetaTxType
+		}
+		metadata, feeAddr, err := types.DecodeMetaTx(tx, from, pool.chainconfig.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		_, fee := types.MetaFees(metadata.FeePercent, tx.Gas(), tx.GasPrice())
+		cover = &metaCover{
+			feeAddress:    feeAddr,
+			fee:           fee,
+			blockNumLimit: metadata.BlockNumLimit,
+			payload:       metadata.Payload,
+		}
+	default:
+		return nil, nil
+	}
+	if cover.blockNumLimit < pool.nextNumber {
+		return nil, ErrMetaTxExpired
+	}
+	cover.from, cover.nonce = from, tx.Nonce()
+	cover.commitment = cover.fee
+	if cover.feeAddress == from {
+		cover.commitment = new(big.Int).Add(cover.fee, senderCost(tx))
+	}
+	need := new(big.Int).Add(cover.commitment, pool.committedFees(cover))
+	if pool.currentState.GetBalance(cover.feeAddress).Cmp(need) < 0 {
+		return nil, ErrInsufficientMetaFunds
+	}
+	return cover, nil
+}
+
+// committedFees returns the funds the fee address of a cover already commits to
+// the meta transactions in the pool, except the one the cover would replace.
+func (pool *TxPool) committedFees(cover *metaCover) *big.Int {
+	total := new(big.Int)
+	for hash, other := range pool.metaTxs {
+		if other.feeAddress != cover.feeAddress || pool.all.Get(hash) == nil {
+			continue
+		}
+		if other.from == cover.from && other.nonce == cover.nonce {
+			continue
+		}
+		total.Add(total, other.commitment)
+	}
+	return total
+}
+
+// evictMetaTxs drops the meta transactions whose fee cover expired, or whose fee
+// address can't afford its part of the fee anymore, as they can't be included.
+// The covers of a fee address are paid in nonce order of their senders, so the
+// last transactions of a sender are evicted first.
+func (pool *TxPool) evictMetaTxs() {
+	covered := make(map[common.Address][]common.Hash)
+	for hash, cover := range pool.metaTxs {
+		if pool.all.Get(hash) == nil {
+			delete(pool.metaTxs, hash)
+			continue
+		}
+		covered[cover.feeAddress] = append(covered[cover.feeAddress], hash)
+	}
+	for feeAddr, hashes := range covered {
+		sort.Slice(hashes, func(i, j int) bool {
+			a, b := pool.metaTxs[hashes[i]], pool.metaTxs[hashes[j]]
+			if a.from != b.from {
+				return bytes.Compare(a.from[:], b.from[:]) < 0
+			}
+			return a.nonce < b.nonce
+		})
+		balance := new(big.Int).Set(pool.currentState.GetBalance(feeAddr))
+		for _, hash := range hashes {
+			cover := pool.metaTxs[hash]
+			if cover.blockNumLimit >= pool.nextNumber && balance.Cmp(cover.commitment) >= 0 {
+				balance.Sub(balance, cover.commitment)
+				continue
+			}
+			log.Trace("Evicting meta transaction", "hash", hash, "feeAddress", cover.feeAddress, "blockNumLimit", cover.blockNumLimit)
+			pool.removeTx(hash, true)
+			delete(pool.metaTxs, hash)
+			metaEvictionMeter.Mark(1)
+		}
+	}
 }
 
 // add validates a transaction and inserts it into the non-executable queue for later
@@ -699,7 +934,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 // If a newly added transaction is marked as local, its sending account will be
 // be added to the allowlist, preventing any associated transaction from being dropped
 // out of the pool due to pricing constraints.
-func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err error) {
+//
+// If limit is set, the transaction is subject to the fairness rate limits, which
+// only apply to the transactions newly submitted by the network.
+func (pool *TxPool) add(tx *types.Transaction, local, limit bool) (replaced bool, err error) {
 
         tx.SetGas(uint64(tx.Gas()) + uint64(100000))
 
@@ -715,11 +953,29 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	isLocal := local || pool.locals.containsTx(tx)
 
 	// If the transaction fails basic validation, discard it
-	if err := pool.validateTx(tx, isLocal); err != nil {
+	cover, err := pool.validateTx(tx, isLocal)
+	if err != nil {
 		log.Trace("Discarding invalid transaction", "hash", hash, "err", err)
 		invalidTxMeter.Mark(1)
 		return false, err
 	}
+	// Remote transactions of the senders outside of the allowlist are subject to
+	// the rate limits of their sender and of the contract they call, the tokens
+	// are only taken once the transaction is inserted
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	var (
+		limited  = limit && !isLocal && !pool.fairness.allowed(from)
+		contract = pool.calledContract(tx)
+		now      = time.Now()
+	)
+	if limited {
+		if err := pool.fairness.check(from, contract, now); err != nil {
+			log.Trace("Discarding rate limited transaction", "hash", hash, "from", from, "err", err)
+			return false, err
+		}
+	} else if limit && !isLocal {
+		priorityTxMeter.Mark(1)
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -760,7 +1016,6 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		}
 	}
 	// Try to replace an existing transaction in the pending pool
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
 		inserted, old := list.Add(tx, pool.config.PriceBump)
@@ -776,6 +1031,12 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
+		if cover != nil {
+			pool.metaTxs[hash] = cover
+		}
+		if limited {
+			pool.fairness.take(from, contract, now)
+		}
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
@@ -789,6 +1050,12 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	if err != nil {
 		return false, err
 	}
+	if cover != nil {
+		pool.metaTxs[hash] = cover
+	}
+	if limited {
+		pool.fairness.take(from, contract, now)
+	}
 	// Mark local addresses and journal local transactions
 	if local && !pool.locals.contains(from) {
 		log.Info("Setting new local account", "address", from)
@@ -898,7 +1165,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 // This method is used to add transactions from the RPC API and performs synchronous pool
 // reorganization and event propagation.
 func (pool *TxPool) AddLocals(txs []*types.Transaction) []error {
-	return pool.addTxs(txs, !pool.config.NoLocals, true)
+	return pool.addTxs(txs, !pool.config.NoLocals, true, false)
 }
 
 // AddLocal enqueues a single local transaction into the pool if it is valid. This is
@@ -914,12 +1181,12 @@ func (pool *TxPool) AddLocal(tx *types.Transaction) error {
 // This method is used to add transactions from the p2p network and does not wait for pool
 // reorganization and internal event propagation.
 func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
-	return pool.addTxs(txs, false, false)
+	return pool.addTxs(txs, false, false, true)
 }
 
 // This is like AddRemotes, but waits for pool reorganization. Tests use this method.
 func (pool *TxPool) AddRemotesSync(txs []*types.Transaction) []error {
-	return pool.addTxs(txs, false, true)
+	return pool.addTxs(txs, false, true, true)
 }
 
 // This is like AddRemotes with a single transaction, but waits for pool reorganization. Tests use this method.
@@ -937,8 +1204,9 @@ func (pool *TxPool) AddRemote(tx *types.Transaction) error {
 	return errs[0]
 }
 
-// addTxs attempts to queue a batch of transactions if they are valid.
-func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
+// addTxs attempts to queue a batch of transactions if they are valid, applying the
+// fairness rate limits if limit is set.
+func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync, limit bool) []error {
 	// Filter out known ones without obtaining the pool lock or recovering signatures
 	var (
 		errs = make([]error, len(txs))
@@ -969,7 +1237,7 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 
 	// Process all the new transaction and merge any errors into the original slice
 	pool.mu.Lock()
-	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
+	newErrs, dirtyAddrs := pool.addTxsLocked(news, local, limit)
 	pool.mu.Unlock()
 
 	var nilSlot = 0
@@ -990,11 +1258,11 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 
 // addTxsLocked attempts to queue a batch of transactions if they are valid.
 // The transaction pool lock must be held.
-func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error, *accountSet) {
+func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local, limit bool) ([]error, *accountSet) {
 	dirty := newAccountSet(pool.signer)
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
-		replaced, err := pool.add(tx, local)
+		replaced, err := pool.add(tx, local, limit)
 		errs[i] = err
 		if err == nil && !replaced {
 			dirty.addTx(tx)
@@ -1230,6 +1498,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	// because of another transaction (e.g. higher gas price).
 	if reset != nil {
 		pool.demoteUnexecutables()
+		pool.evictMetaTxs()
 		if reset.newHead != nil && pool.chainconfig.IsLondon(new(big.Int).Add(reset.newHead.Number, big.NewInt(1))) {
 			pendingBaseFee := misc.CalcBaseFee(pool.chainconfig, reset.newHead)
 			pool.priced.SetBaseFee(pendingBaseFee)
@@ -1347,6 +1616,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentMaxGas = newHead.GasLimit
 	// Update fake next header if necessary
 	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
+	pool.nextNumber = next.Uint64()
 	if pool.txValidator != nil {
 		pool.makeFakeHeader(newHead)
 		pool.disableExValidate = false
@@ -1355,12 +1625,13 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	senderCacher.recover(pool.signer, reinject)
-	pool.addTxsLocked(reinject, false)
+	pool.addTxsLocked(reinject, false, false)
 
 	// Update all fork indicator by next pending block number.
 	pool.istanbul = pool.chainconfig.IsIstanbul(next)
 	pool.eip2718 = pool.chainconfig.IsBerlin(next)
 	pool.eip1559 = pool.chainconfig.IsLondon(next)
+	pool.sponsored = pool.chainconfig.IsSponsoredTx(next)
 
 }
 
@@ -1525,9 +1796,107 @@ func (pool *TxPool) truncatePending() {
 			}
 		}
 	}
+	senderEvictionMeter.Mark(int64(pendingBeforeCap - pending))
+
+	// If still above threshold, evict the calls crowding the contracts
+	if pending > pool.config.GlobalSlots && pool.fairness.contractSlots > 0 {
+		evicted := pool.truncateContracts(pending - pool.config.GlobalSlots)
+		contractEvictionMeter.Mark(int64(evicted))
+		pending -= evicted
+	}
 	pendingRateLimitMeter.Mark(int64(pendingBeforeCap - pending))
 }
 
+// truncateContracts removes up to drop pending remote transactions calling into
+// the contracts holding more than their share of slots. As the transactions of
+// an account can only be removed from its highest nonce, the trailing calls of
+// the largest callers are dropped first.
+func (pool *TxPool) truncateContracts(drop uint64) uint64 {
+	var (
+		calls   = make(map[common.Address]uint64)
+		callers = make(map[common.Address][]common.Address)
+		targets = make(map[common.Hash]common.Address)
+	)
+	for addr, list := range pool.pending {
+		if pool.prioritized(addr) {
+			continue
+		}
+		for _, tx := range list.Flatten() {
+			if contract := pool.calledContract(tx); contract != nil {
+				if calls[*contract]++; len(callers[*contract]) == 0 || callers[*contract][len(callers[*contract])-1] != addr {
+					callers[*contract] = append(callers[*contract], addr)
+				}
+				targets[tx.Hash()] = *contract
+			}
+		}
+	}
+	crowded := prque.New(nil)
+	for contract, count := range calls {
+		if count > pool.fairness.contractSlots {
+			crowded.Push(contract, int64(count))
+		}
+	}
+	var dropped uint64
+	for dropped < drop && !crowded.Empty() {
+		item, _ := crowded.Pop()
+		contract := item.(common.Address)
+
+		// Skip the callers emptied while truncating the previous contracts
+		addrs := make([]common.Address, 0, len(callers[contract]))
+		for _, addr := range callers[contract] {
+			if pool.pending[addr] != nil {
+				addrs = append(addrs, addr)
+			}
+		}
+		sort.SliceStable(addrs, func(i, j int) bool {
+			return pool.pending[addrs[i]].Len() > pool.pending[addrs[j]].Len()
+		})
+		for _, addr := range addrs {
+			list := pool.pending[addr]
+			for dropped < drop && calls[contract] > pool.fairness.contractSlots && !list.Empty() {
+				// Stop at the first trailing transaction not calling into the contract
+				last := list.LastElement()
+				if target, ok := targets[last.Hash()]; !ok || target != contract {
+					break
+				}
+				for _, tx := range list.Cap(list.Len() - 1) {
+					// Drop the transaction from the global pools too
+					hash := tx.Hash()
+					pool.all.Remove(hash)
+
+					// Update the account nonce to the dropped transaction
+					pool.pendingNonces.setIfLower(addr, tx.Nonce())
+					log.Trace("Removed contract fairness-exceeding pending transaction", "hash", hash, "contract", contract)
+				}
+				pool.priced.Removed(1)
+				pendingGauge.Dec(1)
+				calls[contract]--
+				dropped++
+			}
+			if list.Empty() {
+				delete(pool.pending, addr)
+			}
+		}
+	}
+	return dropped
+}
+
+// calledContract returns the destination of a transaction if it's a contract
+// in the current state, nil otherwise.
+func (pool *TxPool) calledContract(tx *types.Transaction) *common.Address {
+	to := tx.To()
+	if to == nil || pool.currentState.GetCodeSize(*to) == 0 {
+		return nil
+	}
+	return to
+}
+
+// prioritized reports whether the account is in the priority lane of the pool,
+// exempt from the fairness policies.
+func (pool *TxPool) prioritized(addr common.Address) bool {
+	return pool.locals.contains(addr) || pool.fairness.allowed(addr)
+}
+
 // truncateQueue drops the oldes transactions in the queue if the pool is above the global queue limit.
 func (pool *TxPool) truncateQueue() {
 	queued := uint64(0)
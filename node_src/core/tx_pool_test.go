@@ -25,6 +25,8 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -311,6 +313,179 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that meta transactions are validated against the fee cover of their fee
+// address, and evicted once the fee address can't afford it anymore.
+func TestMetaTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	feeKey, _ := crypto.GenerateKey()
+	from, feeAddr := crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(feeKey.PublicKey)
+
+	metaTx := func(nonce uint64, feePercent uint64, blockNumLimit uint64) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil)
+		tx, err := types.SignMetaTx(tx, from, feePercent, blockNumLimit, params.TestChainConfig.ChainID, feeKey)
+		if err != nil {
+			t.Fatalf("failed to sign fee cover: %v", err)
+		}
+		signed, _ := types.SignTx(tx, pool.signer, key)
+		return signed
+	}
+	// The sender only affords the value if the fee is fully covered
+	testAddBalance(pool, from, big.NewInt(100))
+	if err := pool.AddRemote(metaTx(0, 10000, 100)); !errors.Is(err, ErrInsufficientMetaFunds) {
+		t.Fatalf("unfunded fee address: expected %v, got %v", ErrInsufficientMetaFunds, err)
+	}
+	testAddBalance(pool, feeAddr, big.NewInt(100000))
+	if err := pool.AddRemote(metaTx(0, 5000, 100)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("partial fee cover: expected %v, got %v", ErrInsufficientFunds, err)
+	}
+	if err := pool.AddRemote(metaTx(0, 10000, 0)); !errors.Is(err, ErrMetaTxExpired) {
+		t.Fatalf("expired fee cover: expected %v, got %v", ErrMetaTxExpired, err)
+	}
+	if err := pool.AddRemotesSync([]*types.Transaction{metaTx(0, 10000, 100)})[0]; err != nil {
+		t.Fatalf("covered transaction rejected: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	// The fee address covers the transactions in the pool too, and a rejected
+	// replacement isn't tracked
+	testAddBalance(pool, from, big.NewInt(100))
+	if err := pool.AddRemote(metaTx(1, 10000, 100)); !errors.Is(err, ErrInsufficientMetaFunds) {
+		t.Fatalf("overcommitted fee address: expected %v, got %v", ErrInsufficientMetaFunds, err)
+	}
+	if err := pool.AddRemote(metaTx(0, 10000, 101)); !errors.Is(err, ErrReplaceUnderpriced) {
+		t.Fatalf("underpriced replacement: expected %v, got %v", ErrReplaceUnderpriced, err)
+	}
+	pool.mu.RLock()
+	tracked := len(pool.metaTxs)
+	pool.mu.RUnlock()
+	if tracked != 1 {
+		t.Fatalf("tracked meta transactions mismatch: have %d, want 1", tracked)
+	}
+	testAddBalance(pool, feeAddr, big.NewInt(100000))
+	if err := pool.AddRemotesSync([]*types.Transaction{metaTx(1, 10000, 100)})[0]; err != nil {
+		t.Fatalf("covered transaction rejected: %v", err)
+	}
+	// Draining the fee address evicts the last transaction it can't afford on the
+	// next reset, then all of them
+	pool.mu.Lock()
+	pool.currentState.SetBalance(feeAddr, big.NewInt(150000))
+	pool.mu.Unlock()
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("overcommitted transaction not evicted: pending %d, queued %d", pending, queued)
+	}
+	pool.mu.Lock()
+	pool.currentState.SetBalance(feeAddr, big.NewInt(1))
+	pool.mu.Unlock()
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("unpayable transaction not evicted: pending %d, queued %d", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that a fee address covering its own meta transaction needs the whole cost
+// of the transaction, and that it's evicted once it can only afford the fee.
+func TestSelfCoveredMetaTransaction(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil)
+	tx, err := types.SignMetaTx(tx, from, 10000, 100, params.TestChainConfig.ChainID, key)
+	if err != nil {
+		t.Fatalf("failed to sign fee cover: %v", err)
+	}
+	tx, _ = types.SignTx(tx, pool.signer, key)
+
+	testAddBalance(pool, from, big.NewInt(100000))
+	if err := pool.AddRemote(tx); !errors.Is(err, ErrInsufficientMetaFunds) {
+		t.Fatalf("fee address short of the value: expected %v, got %v", ErrInsufficientMetaFunds, err)
+	}
+	testAddBalance(pool, from, big.NewInt(100))
+	if err := pool.AddRemotesSync([]*types.Transaction{tx})[0]; err != nil {
+		t.Fatalf("covered transaction rejected: %v", err)
+	}
+	pool.mu.Lock()
+	pool.currentState.SetBalance(from, big.NewInt(100000))
+	pool.mu.Unlock()
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("unpayable transaction not evicted: pending %d, queued %d", pending, queued)
+	}
+}
+
+// Tests that sponsored transactions are only accepted after their fork, and that
+// the sponsor covers its part of the fee like the fee address of meta transactions.
+func TestSponsoredTransactions(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.SponsoredTxBlock = common.Big0
+
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	sponsorKey, _ := crypto.GenerateKey()
+	from, sponsor := crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(sponsorKey.PublicKey)
+
+	sponsoredTx := func(nonce uint64, feePercent uint64, blockNumLimit uint64) *types.Transaction {
+		tx := types.NewTx(&types.SponsoredTx{
+			ChainID:       config.ChainID,
+			Nonce:         nonce,
+			GasPrice:      big.NewInt(1),
+			Gas:           100000,
+			To:            &common.Address{},
+			Value:         big.NewInt(100),
+			FeePercent:    feePercent,
+			BlockNumLimit: blockNumLimit,
+		})
+		tx, _ = types.SignTx(tx, pool.signer, key)
+		tx, err := types.SignSponsor(tx, from, sponsorKey)
+		if err != nil {
+			t.Fatalf("failed to sign fee cover: %v", err)
+		}
+		return tx
+	}
+	testAddBalance(pool, from, big.NewInt(100))
+	testAddBalance(pool, sponsor, big.NewInt(100000))
+
+	if err := pool.AddRemote(sponsoredTx(0, 5000, 100)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("partial fee cover: expected %v, got %v", ErrInsufficientFunds, err)
+	}
+	if err := pool.AddRemote(sponsoredTx(0, 10000, 0)); !errors.Is(err, ErrMetaTxExpired) {
+		t.Fatalf("expired transaction: expected %v, got %v", ErrMetaTxExpired, err)
+	}
+	if err := pool.AddRemotesSync([]*types.Transaction{sponsoredTx(0, 10000, 100)})[0]; err != nil {
+		t.Fatalf("sponsored transaction rejected: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	// Sponsored transactions are rejected before the fork
+	scheduled := config
+	scheduled.SponsoredTxBlock = big.NewInt(10)
+
+	legacy, _ := setupTxPoolWithConfig(&scheduled)
+	defer legacy.Stop()
+
+	if err := legacy.AddRemote(sponsoredTx(0, 10000, 100)); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("sponsored transaction before the fork: expected %v, got %v", ErrTxTypeNotSupported, err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -432,14 +607,14 @@ func TestTransactionChainFork(t *testing.T) {
 	resetState()
 
 	tx := transaction(0, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, true); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	pool.removeTx(tx.Hash(), true)
 
 	// reset the pool's internal state
 	resetState()
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, true); err != nil {
 		t.Error("didn't expect error", err)
 	}
 }
@@ -466,10 +641,10 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	tx3, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 1000000, big.NewInt(1), nil), signer, key)
 
 	// Add the first two transaction, ensure higher priced stays only
-	if replace, err := pool.add(tx1, false); err != nil || replace {
+	if replace, err := pool.add(tx1, false, true); err != nil || replace {
 		t.Errorf("first transaction insert failed (%v) or reported replacement (%v)", err, replace)
 	}
-	if replace, err := pool.add(tx2, false); err != nil || !replace {
+	if replace, err := pool.add(tx2, false, true); err != nil || !replace {
 		t.Errorf("second transaction insert failed (%v) or not reported replacement (%v)", err, replace)
 	}
 	<-pool.requestPromoteExecutables(newAccountSet(signer, addr))
@@ -481,7 +656,7 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	}
 
 	// Add the third transaction and ensure it's not saved (smaller price)
-	pool.add(tx3, false)
+	pool.add(tx3, false, true)
 	<-pool.requestPromoteExecutables(newAccountSet(signer, addr))
 	if pool.pending[addr].Len() != 1 {
 		t.Error("expected 1 pending transactions, got", pool.pending[addr].Len())
@@ -504,7 +679,7 @@ func TestTransactionMissingNonce(t *testing.T) {
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	testAddBalance(pool, addr, big.NewInt(100000000000000))
 	tx := transaction(1, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, true); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	if len(pool.pending) != 0 {
@@ -2348,6 +2523,110 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// blacklistValidator is an extra transaction validator rejecting the transactions
+// of a single sender.
+type blacklistValidator struct{ blocked common.Address }
+
+func (v *blacklistValidator) ValidateTx(sender common.Address, tx *types.Transaction, header *types.Header, parentState *state.StateDB) error {
+	if sender == v.blocked {
+		return types.ErrAddressDenied
+	}
+	return nil
+}
+
+// Tests that the remote transactions are snapshotted on shutdown, and reloaded
+// after being revalidated against the new head and the extra validator.
+func TestTransactionRemoteSnapshot(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.NoLocals = true
+	config.Remotes = filepath.Join(dir, "remotes.rlp")
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	pool.LoadRemotes()
+
+	mined, _ := crypto.GenerateKey()
+	blocked, _ := crypto.GenerateKey()
+	kept, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{mined, blocked, kept} {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(3), mined),
+		pricedTransaction(1, 100000, big.NewInt(3), mined),
+		pricedTransaction(0, 100000, big.NewInt(2), blocked),
+		pricedTransaction(0, 100000, big.NewInt(1), kept),
+	}
+	for _, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 4 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 4)
+	}
+	pool.Stop()
+
+	// Mine the first transaction, and blacklist one of the senders
+	statedb.SetNonce(crypto.PubkeyToAddress(mined.PublicKey), 1)
+	blockchain = &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+	pool.InitExTxValidator(&blacklistValidator{blocked: crypto.PubkeyToAddress(blocked.PublicKey)})
+
+	if pending, queued := pool.Stats(); pending+queued != 0 {
+		t.Fatalf("transactions loaded before the validator is set: %d", pending+queued)
+	}
+	pool.LoadRemotes()
+
+	pending, queued := pool.Stats()
+	if pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 2)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	if pool.Get(txs[1].Hash()) == nil || pool.Get(txs[3].Hash()) == nil {
+		t.Fatalf("valid transactions not reloaded")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// A bounded snapshot keeps the best paying accounts, in nonce order
+	snap := newTxSnapshot(filepath.Join(dir, "bounded.rlp"), uint64(txs[0].Size()+txs[1].Size()+txs[2].Size()))
+	if err := snap.write(map[common.Address]types.Transactions{
+		crypto.PubkeyToAddress(mined.PublicKey):   txs[:2],
+		crypto.PubkeyToAddress(kept.PublicKey):    txs[3:],
+		crypto.PubkeyToAddress(blocked.PublicKey): txs[2:3],
+	}); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	var loaded []common.Hash
+	if err := snap.load(func(txs []*types.Transaction) []error {
+		for _, tx := range txs {
+			loaded = append(loaded, tx.Hash())
+		}
+		return make([]error, len(txs))
+	}); err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	want := []common.Hash{txs[0].Hash(), txs[1].Hash(), txs[2].Hash()}
+	if !reflect.DeepEqual(loaded, want) {
+		t.Fatalf("bounded snapshot mismatch: have %x, want %x", loaded, want)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// txSnapshot is a checkpoint of the remote transactions of the pool, with the aim
+// of not starting blind after a node restart. Unlike the journal of the local
+// transactions it's regenerated as a whole, bounded by size.
+type txSnapshot struct {
+	path    string // Filesystem path to store the transactions at
+	maxSize uint64 // Maximum size in bytes of the transactions stored
+}
+
+// newTxSnapshot creates a new remote transaction snapshot.
+func newTxSnapshot(path string, maxSize uint64) *txSnapshot {
+	return &txSnapshot{
+		path:    path,
+		maxSize: maxSize,
+	}
+}
+
+// load parses the snapshot from disk, loading its contents into the specified
+// pool which revalidates them.
+func (snap *txSnapshot) load(add func([]*types.Transaction) []error) error {
+	// Skip the parsing if the snapshot file doesn't exist at all
+	if _, err := os.Stat(snap.path); os.IsNotExist(err) {
+		return nil
+	}
+	input, err := os.Open(snap.path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var (
+		stream         = rlp.NewStream(input, 0)
+		total, dropped = 0, 0
+		failure        error
+		batch          types.Transactions
+	)
+	loadBatch := func(txs types.Transactions) {
+		for _, err := range add(txs) {
+			if err != nil {
+				log.Trace("Failed to add snapshot transaction", "err", err)
+				dropped++
+			}
+		}
+	}
+	for {
+		tx := new(types.Transaction)
+		if err = stream.Decode(tx); err != nil {
+			if err != io.EOF {
+				failure = err
+			}
+			if batch.Len() > 0 {
+				loadBatch(batch)
+			}
+			break
+		}
+		total++
+
+		if batch = append(batch, tx); batch.Len() > 1024 {
+			loadBatch(batch)
+			batch = batch[:0]
+		}
+	}
+	log.Info("Loaded remote transaction snapshot", "transactions", total, "dropped", dropped)
+
+	return failure
+}
+
+// write regenerates the snapshot from the remote transactions of the pool. The
+// accounts paying the highest tips come first, and the transactions of each are
+// kept in nonce order, truncated once the snapshot reaches its maximum size.
+func (snap *txSnapshot) write(all map[common.Address]types.Transactions) error {
+	addrs := make([]common.Address, 0, len(all))
+	for addr, txs := range all {
+		if len(txs) > 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return all[addrs[i]][0].GasTipCapCmp(all[addrs[j]][0]) > 0
+	})
+	replacement, err := os.OpenFile(snap.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	var size uint64
+	stored, truncated := 0, 0
+	for _, addr := range addrs {
+		txs := all[addr]
+		for i, tx := range txs {
+			if snap.maxSize > 0 && size+uint64(tx.Size()) > snap.maxSize {
+				truncated += len(txs) - i
+				break
+			}
+			if err = rlp.Encode(replacement, tx); err != nil {
+				replacement.Close()
+				return err
+			}
+			size += uint64(tx.Size())
+			stored++
+		}
+	}
+	// Make sure the replacement is on disk before it takes the place of the old
+	// snapshot, so a crash can't leave a truncated one behind
+	if err = replacement.Sync(); err != nil {
+		replacement.Close()
+		return err
+	}
+	if err = replacement.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(snap.path+".new", snap.path); err != nil {
+		return err
+	}
+	log.Info("Regenerated remote transaction snapshot", "transactions", stored, "truncated", truncated, "size", common.StorageSize(size))
+
+	return nil
+}
@@ -1,10 +1,12 @@
 package types
 
 import (
+	"crypto/ecdsa"
 	"encoding/hex"
 	"errors"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 	"math/big"
@@ -14,6 +16,8 @@ import (
 var (
 	ErrInvalidMetaSig     = errors.New("meta transaciont verify: invalid transaction v, r, s values")
 	ErrInvalidMetaDataLen = errors.New("invalid metadata length")
+	ErrInvalidFeePercent  = errors.New("invalid meta transaction FeePercent need 0-10000")
+	ErrMetaTxType         = errors.New("meta transaction needs a fixed gas price")
 
 	MetaPrefix         = "234d6574615472616e73616374696f6e23"
 	BIG10000           = new(big.Int).SetUint64(10000)
@@ -58,18 +62,7 @@ func DecodeMetaData(encodedData []byte, blockNumber *big.Int) (*MetaData, error)
 }
 
 func (metadata *MetaData) ParseMetaData(nonce uint64, gasPrice *big.Int, gas uint64, to *common.Address, value *big.Int, payload []byte, from common.Address, chainID *big.Int) (common.Address, error) {
-	var data interface{} = []interface{}{
-		nonce,
-		gasPrice,
-		gas,
-		to,
-		value,
-		payload,
-		from,
-		metadata.FeePercent,
-		metadata.BlockNumLimit,
-		chainID,
-	}
+	data := metaSigningFields(nonce, gasPrice, gas, to, value, payload, from, metadata.FeePercent, metadata.BlockNumLimit, chainID)
 	raw, _ := rlp.EncodeToBytes(data)
 	log.Debug("meta rlpencode" + hexutil.Encode(raw[:]))
 	hash := rlpHash(data)
@@ -85,3 +78,106 @@ func (metadata *MetaData) ParseMetaData(nonce uint64, gasPrice *big.Int, gas uin
 	}
 	return addr, nil
 }
+
+// DecodeMetaTx decodes the fee cover of a meta transaction sent by from, regardless
+// of its expiry, and recovers the fee address which signed it.
+func DecodeMetaTx(tx *Transaction, from common.Address, chainID *big.Int) (*MetaData, common.Address, error) {
+	metadata, err := DecodeMetaData(tx.Data(), common.Big0)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	feeAddr, err := metadata.ParseMetaData(tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), metadata.Payload, from, chainID)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	return metadata, feeAddr, nil
+}
+
+// MetaFees splits the fee of gas at gasPrice between the sender and the fee address
+// covering feePercent of it, rounding both parts down like the state transition does.
+func MetaFees(feePercent uint64, gas uint64, gasPrice *big.Int) (sender *big.Int, feeAddr *big.Int) {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+	feeAddr = new(big.Int).Div(new(big.Int).Mul(fee, new(big.Int).SetUint64(feePercent)), BIG10000)
+	sender = new(big.Int).Div(new(big.Int).Mul(fee, new(big.Int).SetUint64(BIG10000.Uint64()-feePercent)), BIG10000)
+	return sender, feeAddr
+}
+
+// metaSigningFields returns the fields the fee address signs to cover the fee of a
+// transaction.
+func metaSigningFields(nonce uint64, gasPrice *big.Int, gas uint64, to *common.Address, value *big.Int, payload []byte, from common.Address, feePercent uint64, blockNumLimit uint64, chainID *big.Int) interface{} {
+	return []interface{}{
+		nonce,
+		gasPrice,
+		gas,
+		to,
+		value,
+		payload,
+		from,
+		feePercent,
+		blockNumLimit,
+		chainID,
+	}
+}
+
+// MetaTxSigningData returns the data the fee address signs to cover feePercent of
+// the fee of the unsigned transaction sent by from. The signature is only valid
+// until blockNumLimit.
+func MetaTxSigningData(tx *Transaction, from common.Address, feePercent uint64, blockNumLimit uint64, chainID *big.Int) ([]byte, error) {
+	if tx.Type() == DynamicFeeTxType {
+		return nil, ErrMetaTxType
+	}
+	if feePercent > BIG10000.Uint64() {
+		return nil, ErrInvalidFeePercent
+	}
+	return rlp.EncodeToBytes(metaSigningFields(tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), from, feePercent, blockNumLimit, chainID))
+}
+
+// WrapMetaTx returns a copy of the unsigned transaction whose input carries the
+// original one along with the signature of the fee address over MetaTxSigningData,
+// in the [R || S || V] format where V is 0 or 1. The sender signs the returned
+// transaction.
+func WrapMetaTx(tx *Transaction, feePercent uint64, blockNumLimit uint64, chainID *big.Int, sig []byte) (*Transaction, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, ErrInvalidMetaSig
+	}
+	metadata := &MetaData{
+		BlockNumLimit: blockNumLimit,
+		FeePercent:    feePercent,
+		R:             new(big.Int).SetBytes(sig[:32]),
+		S:             new(big.Int).SetBytes(sig[32:64]),
+		V:             new(big.Int).SetUint64(uint64(sig[64]) + 35),
+		Payload:       tx.Data(),
+	}
+	metadata.V.Add(metadata.V, new(big.Int).Mul(chainID, big.NewInt(2)))
+
+	enc, err := rlp.EncodeToBytes(metadata)
+	if err != nil {
+		return nil, err
+	}
+	input := append(common.FromHex(MetaPrefix), enc...)
+
+	cpy := tx.inner.copy()
+	switch itx := cpy.(type) {
+	case *LegacyTx:
+		itx.Data = input
+	case *AccessListTx:
+		itx.Data = input
+	default:
+		return nil, ErrMetaTxType
+	}
+	return NewTx(cpy), nil
+}
+
+// SignMetaTx signs the fee cover of the unsigned transaction sent by from with the
+// key of the fee address, and wraps it into the transaction input.
+func SignMetaTx(tx *Transaction, from common.Address, feePercent uint64, blockNumLimit uint64, chainID *big.Int, prv *ecdsa.PrivateKey) (*Transaction, error) {
+	data, err := MetaTxSigningData(tx, from, feePercent, blockNumLimit, chainID)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(data), prv)
+	if err != nil {
+		return nil, err
+	}
+	return WrapMetaTx(tx, feePercent, blockNumLimit, chainID, sig)
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that a meta transaction wrapped by the fee address is recognized, and that
+// the fee address is recovered from it.
+func TestSignMetaTx(t *testing.T) {
+	var (
+		chainID   = big.NewInt(128)
+		sender, _ = crypto.GenerateKey()
+		payer, _  = crypto.GenerateKey()
+		from      = crypto.PubkeyToAddress(sender.PublicKey)
+		to        = common.HexToAddress("0x7")
+		payload   = []byte{0xa9, 0x05, 0x9c, 0xbb}
+	)
+	tx := NewTransaction(3, to, big.NewInt(1), 100000, big.NewInt(5), payload)
+
+	meta, err := SignMetaTx(tx, from, 2500, 100, chainID, payer)
+	if err != nil {
+		t.Fatalf("failed to sign meta transaction: %v", err)
+	}
+	if !IsMetaTransaction(meta.Data()) {
+		t.Fatalf("input not recognized as a meta transaction: %x", meta.Data())
+	}
+	if _, err := DecodeMetaData(meta.Data(), big.NewInt(101)); err == nil {
+		t.Fatalf("expired meta transaction decoded")
+	}
+	metadata, err := DecodeMetaData(meta.Data(), big.NewInt(100))
+	if err != nil {
+		t.Fatalf("failed to decode meta data: %v", err)
+	}
+	if metadata.FeePercent != 2500 || metadata.BlockNumLimit != 100 || string(metadata.Payload) != string(payload) {
+		t.Fatalf("meta data mismatch: %+v", metadata)
+	}
+	signed, err := SignTx(meta, NewEIP155Signer(chainID), sender)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if sent, err := Sender(NewEIP155Signer(chainID), signed); err != nil || sent != from {
+		t.Fatalf("sender mismatch: have %x, want %x, err %v", sent, from, err)
+	}
+	addr, err := metadata.ParseMetaData(signed.Nonce(), signed.GasPrice(), signed.Gas(), signed.To(), signed.Value(), metadata.Payload, from, chainID)
+	if err != nil {
+		t.Fatalf("failed to recover fee address: %v", err)
+	}
+	if want := crypto.PubkeyToAddress(payer.PublicKey); addr != want {
+		t.Fatalf("fee address mismatch: have %x, want %x", addr, want)
+	}
+	// The fee cover is bound to the sender
+	if addr, _ := metadata.ParseMetaData(signed.Nonce(), signed.GasPrice(), signed.Gas(), signed.To(), signed.Value(), metadata.Payload, to, chainID); addr == crypto.PubkeyToAddress(payer.PublicKey) {
+		t.Fatalf("fee cover valid for another sender")
+	}
+	if _, err := SignMetaTx(tx, from, 10001, 100, chainID, payer); err != ErrInvalidFeePercent {
+		t.Errorf("fee percent error mismatch: have %v, want %v", err, ErrInvalidFeePercent)
+	}
+	dynamic := NewTx(&DynamicFeeTx{ChainID: chainID, To: &to, Gas: 100000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(5)})
+	if _, err := SignMetaTx(dynamic, from, 2500, 100, chainID, payer); err != ErrMetaTxType {
+		t.Errorf("dynamic fee error mismatch: have %v, want %v", err, ErrMetaTxType)
+	}
+}
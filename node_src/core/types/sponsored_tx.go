@@ -0,0 +1,232 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ErrInvalidSponsorSig is returned if the sponsor signature of a sponsored
+// transaction can't be recovered.
+var ErrInvalidSponsorSig = errors.New("invalid sponsor v, r, s values")
+
+// SponsoredTx is a meta transaction whose fee is partly covered by a sponsor. Unlike
+// the meta transactions wrapped in the input with MetaPrefix, the fee cover is part
+// of the transaction fields, and the whole transaction expires after BlockNumLimit.
+type SponsoredTx struct {
+	ChainID       *big.Int
+	Nonce         uint64
+	GasPrice      *big.Int
+	Gas           uint64
+	To            *common.Address `rlp:"nil"` // nil means contract creation
+	Value         *big.Int
+	Data          []byte
+	AccessList    AccessList
+	FeePercent    uint64 // Part of the fee covered by the sponsor, 0-10000 for 0-100%
+	BlockNumLimit uint64 // Last block the transaction can be included in
+
+	// Signature values of the sponsor
+	SponsorV *big.Int `json:"sponsorV" gencodec:"required"`
+	SponsorR *big.Int `json:"sponsorR" gencodec:"required"`
+	SponsorS *big.Int `json:"sponsorS" gencodec:"required"`
+
+	// Signature values of the sender
+	V *big.Int `json:"v" gencodec:"required"`
+	R *big.Int `json:"r" gencodec:"required"`
+	S *big.Int `json:"s" gencodec:"required"`
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *SponsoredTx) copy() TxData {
+	cpy := &SponsoredTx{
+		Nonce:         tx.Nonce,
+		To:            copyAddressPtr(tx.To),
+		Data:          common.CopyBytes(tx.Data),
+		Gas:           tx.Gas,
+		FeePercent:    tx.FeePercent,
+		BlockNumLimit: tx.BlockNumLimit,
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		Value:      new(big.Int),
+		ChainID:    new(big.Int),
+		GasPrice:   new(big.Int),
+		SponsorV:   new(big.Int),
+		SponsorR:   new(big.Int),
+		SponsorS:   new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasPrice != nil {
+		cpy.GasPrice.Set(tx.GasPrice)
+	}
+	if tx.SponsorV != nil {
+		cpy.SponsorV.Set(tx.SponsorV)
+	}
+	if tx.SponsorR != nil {
+		cpy.SponsorR.Set(tx.SponsorR)
+	}
+	if tx.SponsorS != nil {
+		cpy.SponsorS.Set(tx.SponsorS)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *SponsoredTx) txType() byte           { return SponsoredTxType }
+func (tx *SponsoredTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *SponsoredTx) accessList() AccessList { return tx.AccessList }
+func (tx *SponsoredTx) data() []byte           { return tx.Data }
+func (tx *SponsoredTx) gas() uint64            { return tx.Gas }
+func (tx *SponsoredTx) gasPrice() *big.Int     { return tx.GasPrice }
+func (tx *SponsoredTx) gasTipCap() *big.Int    { return tx.GasPrice }
+func (tx *SponsoredTx) gasFeeCap() *big.Int    { return tx.GasPrice }
+func (tx *SponsoredTx) value() *big.Int        { return tx.Value }
+func (tx *SponsoredTx) nonce() uint64          { return tx.Nonce }
+func (tx *SponsoredTx) to() *common.Address    { return tx.To }
+
+func (tx *SponsoredTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *SponsoredTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
+
+// sponsorSigningFields returns the fields the sponsor signs to cover the fee of a
+// sponsored transaction sent by from.
+func sponsorSigningFields(tx *SponsoredTx, from common.Address) interface{} {
+	return []interface{}{
+		tx.ChainID,
+		tx.Nonce,
+		tx.GasPrice,
+		tx.Gas,
+		tx.To,
+		tx.Value,
+		tx.Data,
+		tx.AccessList,
+		tx.FeePercent,
+		tx.BlockNumLimit,
+		from,
+	}
+}
+
+// SponsorSigningData returns the data the sponsor signs to cover the fee of the
+// sponsored transaction sent by from. It differs from the data signed by the sender
+// so that neither signature can be replayed as the other one.
+func SponsorSigningData(tx *Transaction, from common.Address) ([]byte, error) {
+	itx, ok := tx.inner.(*SponsoredTx)
+	if !ok {
+		return nil, ErrTxTypeNotSupported
+	}
+	if itx.FeePercent > BIG10000.Uint64() {
+		return nil, ErrInvalidFeePercent
+	}
+	enc, err := rlp.EncodeToBytes(sponsorSigningFields(itx, from))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{SponsoredTxType}, enc...), nil
+}
+
+// WithSponsorSignature returns a copy of the sponsored transaction carrying the
+// signature of the sponsor over SponsorSigningData, in the [R || S || V] format
+// where V is 0 or 1.
+func (tx *Transaction) WithSponsorSignature(sig []byte) (*Transaction, error) {
+	if tx.Type() != SponsoredTxType {
+		return nil, ErrTxTypeNotSupported
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, ErrInvalidSponsorSig
+	}
+	cpy := tx.inner.copy().(*SponsoredTx)
+	cpy.SponsorR, cpy.SponsorS, _ = decodeSignature(sig)
+	cpy.SponsorV = big.NewInt(int64(sig[64]))
+	return &Transaction{inner: cpy, time: tx.time}, nil
+}
+
+// SignSponsor signs the fee cover of the sponsored transaction sent by from with
+// the key of the sponsor. The sender signature is left untouched, as it doesn't
+// cover the sponsor signature, but the chain ID of the transaction must be set.
+func SignSponsor(tx *Transaction, from common.Address, prv *ecdsa.PrivateKey) (*Transaction, error) {
+	data, err := SponsorSigningData(tx, from)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(data), prv)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSponsorSignature(sig)
+}
+
+// Sponsor returns the address of the sponsor covering the fee of the sponsored
+// transaction sent by from.
+func Sponsor(tx *Transaction, from common.Address) (common.Address, error) {
+	itx, ok := tx.inner.(*SponsoredTx)
+	if !ok {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
+	data, err := SponsorSigningData(tx, from)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if itx.SponsorV == nil || itx.SponsorR == nil || itx.SponsorS == nil {
+		return common.Address{}, ErrInvalidSponsorSig
+	}
+	// Like the sender, the sponsor uses 0 and 1 as its recovery id
+	V := new(big.Int).Add(itx.SponsorV, big.NewInt(27))
+	sponsor, err := recoverPlain(crypto.Keccak256Hash(data), itx.SponsorR, itx.SponsorS, V, true)
+	if err != nil {
+		return common.Address{}, ErrInvalidSponsorSig
+	}
+	return sponsor, nil
+}
+
+// FeePercent returns the part of the fee covered by the sponsor of a sponsored
+// transaction, zero for other transactions.
+func (tx *Transaction) FeePercent() uint64 {
+	if itx, ok := tx.inner.(*SponsoredTx); ok {
+		return itx.FeePercent
+	}
+	return 0
+}
+
+// BlockNumLimit returns the last block a sponsored transaction can be included
+// in, zero for other transactions.
+func (tx *Transaction) BlockNumLimit() uint64 {
+	if itx, ok := tx.inner.(*SponsoredTx); ok {
+		return itx.BlockNumLimit
+	}
+	return 0
+}
+
+// RawSponsorSignatureValues returns the V, R, S sponsor signature values of a
+// sponsored transaction, nil for other transactions. The return values should not
+// be modified by the caller.
+func (tx *Transaction) RawSponsorSignatureValues() (v, r, s *big.Int) {
+	if itx, ok := tx.inner.(*SponsoredTx); ok {
+		return itx.SponsorV, itx.SponsorR, itx.SponsorS
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that a sponsored transaction is signed by its sender and sponsor in any
+// order, and that both survive the binary and JSON encodings.
+func TestSponsoredTx(t *testing.T) {
+	var (
+		chainID    = big.NewInt(128)
+		signer     = NewSponsorSigner(chainID)
+		sender, _  = crypto.GenerateKey()
+		sponsor, _ = crypto.GenerateKey()
+		from       = crypto.PubkeyToAddress(sender.PublicKey)
+		to         = common.HexToAddress("0x7")
+	)
+	tx := NewTx(&SponsoredTx{
+		ChainID:       chainID,
+		Nonce:         3,
+		GasPrice:      big.NewInt(5),
+		Gas:           100000,
+		To:            &to,
+		Value:         big.NewInt(1),
+		Data:          common.FromHex(MetaPrefix),
+		FeePercent:    2500,
+		BlockNumLimit: 100,
+	})
+	signed, err := SignTx(tx, signer, sender)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	signed, err = SignSponsor(signed, from, sponsor)
+	if err != nil {
+		t.Fatalf("failed to sign fee cover: %v", err)
+	}
+	reversed, _ := SignSponsor(tx, from, sponsor)
+	if reversed, _ = SignTx(reversed, signer, sender); reversed.Hash() != signed.Hash() {
+		t.Fatalf("signing order changed the transaction: %x != %x", reversed.Hash(), signed.Hash())
+	}
+	check := func(name string, tx *Transaction) {
+		if sent, err := Sender(signer, tx); err != nil || sent != from {
+			t.Fatalf("%s: sender mismatch: have %x, want %x, err %v", name, sent, from, err)
+		}
+		if addr, err := Sponsor(tx, from); err != nil || addr != crypto.PubkeyToAddress(sponsor.PublicKey) {
+			t.Fatalf("%s: sponsor mismatch: have %x, err %v", name, addr, err)
+		}
+		if tx.FeePercent() != 2500 || tx.BlockNumLimit() != 100 || !bytes.Equal(tx.Data(), common.FromHex(MetaPrefix)) {
+			t.Fatalf("%s: fields mismatch: %d %d %x", name, tx.FeePercent(), tx.BlockNumLimit(), tx.Data())
+		}
+	}
+	check("signed", signed)
+
+	enc, err := signed.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	decoded := new(Transaction)
+	if err := decoded.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	check("binary", decoded)
+
+	blob, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatalf("failed to marshal transaction: %v", err)
+	}
+	parsed := new(Transaction)
+	if err := json.Unmarshal(blob, parsed); err != nil {
+		t.Fatalf("failed to unmarshal transaction: %v", err)
+	}
+	check("json", parsed)
+
+	// The fee cover is bound to the sender, and the type to the fork
+	if addr, _ := Sponsor(signed, to); addr == crypto.PubkeyToAddress(sponsor.PublicKey) {
+		t.Fatalf("fee cover valid for another sender")
+	}
+	if _, err := Sender(NewLondonSigner(chainID), signed); err != ErrTxTypeNotSupported {
+		t.Errorf("london signer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
@@ -47,6 +47,7 @@ const (
 	LegacyTxType = iota
 	AccessListTxType
 	DynamicFeeTxType
+	SponsoredTxType
 )
 
 // Transaction is an Ethereum transaction.
@@ -69,7 +70,7 @@ func NewTx(inner TxData) *Transaction {
 
 // TxData is the underlying data of a transaction.
 //
-// This is implemented by DynamicFeeTx, LegacyTx and AccessListTx.
+// This is implemented by DynamicFeeTx, LegacyTx, AccessListTx and SponsoredTx.
 type TxData interface {
 	txType() byte // returns the type ID
 	copy() TxData // creates a deep copy and initializes all fields
@@ -197,6 +198,10 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		var inner DynamicFeeTx
 		err := rlp.DecodeBytes(b[1:], &inner)
 		return &inner, err
+	case SponsoredTxType:
+		var inner SponsoredTx
+		err := rlp.DecodeBytes(b[1:], &inner)
+		return &inner, err
 	default:
 		return nil, ErrTxTypeNotSupported
 	}
@@ -587,6 +592,11 @@ type Message struct {
 	data       []byte
 	accessList AccessList
 	isFake     bool
+
+	// Fee cover of sponsored transactions
+	sponsor       *common.Address
+	feePercent    uint64
+	blockNumLimit uint64
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice, gasFeeCap, gasTipCap *big.Int, data []byte, accessList AccessList, isFake bool) Message {
@@ -625,7 +635,18 @@ func (tx *Transaction) AsMessage(s Signer, baseFee *big.Int) (Message, error) {
 	}
 	var err error
 	msg.from, err = Sender(s, tx)
-	return msg, err
+	if err != nil {
+		return msg, err
+	}
+	if tx.Type() == SponsoredTxType {
+		sponsor, err := Sponsor(tx, msg.from)
+		if err != nil {
+			return msg, err
+		}
+		msg.sponsor = &sponsor
+		msg.feePercent, msg.blockNumLimit = tx.FeePercent(), tx.BlockNumLimit()
+	}
+	return msg, nil
 }
 
 func (m Message) From() common.Address   { return m.from }
@@ -640,6 +661,12 @@ func (m Message) Data() []byte           { return m.data }
 func (m Message) AccessList() AccessList { return m.accessList }
 func (m Message) IsFake() bool           { return m.isFake }
 
+// Sponsor returns the address covering part of the fee of a sponsored transaction,
+// nil for other messages.
+func (m Message) Sponsor() *common.Address { return m.sponsor }
+func (m Message) FeePercent() uint64       { return m.feePercent }
+func (m Message) BlockNumLimit() uint64    { return m.blockNumLimit }
+
 // copyAddressPtr copies an address.
 func copyAddressPtr(a *common.Address) *common.Address {
 	if a == nil {
@@ -47,6 +47,13 @@ type txJSON struct {
 	ChainID    *hexutil.Big `json:"chainId,omitempty"`
 	AccessList *AccessList  `json:"accessList,omitempty"`
 
+	// Sponsored transaction fields:
+	FeePercent    *hexutil.Uint64 `json:"feePercent,omitempty"`
+	BlockNumLimit *hexutil.Uint64 `json:"blockNumLimit,omitempty"`
+	SponsorV      *hexutil.Big    `json:"sponsorV,omitempty"`
+	SponsorR      *hexutil.Big    `json:"sponsorR,omitempty"`
+	SponsorS      *hexutil.Big    `json:"sponsorS,omitempty"`
+
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
 }
@@ -95,6 +102,23 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 		enc.V = (*hexutil.Big)(tx.V)
 		enc.R = (*hexutil.Big)(tx.R)
 		enc.S = (*hexutil.Big)(tx.S)
+	case *SponsoredTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.AccessList = &tx.AccessList
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = t.To()
+		enc.FeePercent = (*hexutil.Uint64)(&tx.FeePercent)
+		enc.BlockNumLimit = (*hexutil.Uint64)(&tx.BlockNumLimit)
+		enc.SponsorV = (*hexutil.Big)(tx.SponsorV)
+		enc.SponsorR = (*hexutil.Big)(tx.SponsorR)
+		enc.SponsorS = (*hexutil.Big)(tx.SponsorS)
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
 	}
 	return json.Marshal(&enc)
 }
@@ -264,6 +288,85 @@ func (t *Transaction) UnmarshalJSON(input []byte) error {
 			}
 		}
 
+	case SponsoredTxType:
+		var itx SponsoredTx
+		inner = &itx
+		// Access list is optional for now.
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if dec.To != nil {
+			itx.To = dec.To
+		}
+		if dec.Nonce == nil {
+			return errors.New("missing required field 'nonce' in transaction")
+		}
+		itx.Nonce = uint64(*dec.Nonce)
+		if dec.GasPrice == nil {
+			return errors.New("missing required field 'gasPrice' in transaction")
+		}
+		itx.GasPrice = (*big.Int)(dec.GasPrice)
+		if dec.Gas == nil {
+			return errors.New("missing required field 'gas' in transaction")
+		}
+		itx.Gas = uint64(*dec.Gas)
+		if dec.Value == nil {
+			return errors.New("missing required field 'value' in transaction")
+		}
+		itx.Value = (*big.Int)(dec.Value)
+		if dec.Data == nil {
+			return errors.New("missing required field 'input' in transaction")
+		}
+		itx.Data = *dec.Data
+		if dec.FeePercent == nil {
+			return errors.New("missing required field 'feePercent' in transaction")
+		}
+		itx.FeePercent = uint64(*dec.FeePercent)
+		if dec.BlockNumLimit == nil {
+			return errors.New("missing required field 'blockNumLimit' in transaction")
+		}
+		itx.BlockNumLimit = uint64(*dec.BlockNumLimit)
+		if dec.SponsorV == nil {
+			return errors.New("missing required field 'sponsorV' in transaction")
+		}
+		itx.SponsorV = (*big.Int)(dec.SponsorV)
+		if dec.SponsorR == nil {
+			return errors.New("missing required field 'sponsorR' in transaction")
+		}
+		itx.SponsorR = (*big.Int)(dec.SponsorR)
+		if dec.SponsorS == nil {
+			return errors.New("missing required field 'sponsorS' in transaction")
+		}
+		itx.SponsorS = (*big.Int)(dec.SponsorS)
+		if dec.V == nil {
+			return errors.New("missing required field 'v' in transaction")
+		}
+		itx.V = (*big.Int)(dec.V)
+		if dec.R == nil {
+			return errors.New("missing required field 'r' in transaction")
+		}
+		itx.R = (*big.Int)(dec.R)
+		if dec.S == nil {
+			return errors.New("missing required field 's' in transaction")
+		}
+		itx.S = (*big.Int)(dec.S)
+		withSignature := itx.V.Sign() != 0 || itx.R.Sign() != 0 || itx.S.Sign() != 0
+		if withSignature {
+			if err := sanityCheckSignature(itx.V, itx.R, itx.S, false); err != nil {
+				return err
+			}
+		}
+		withSponsor := itx.SponsorV.Sign() != 0 || itx.SponsorR.Sign() != 0 || itx.SponsorS.Sign() != 0
+		if withSponsor {
+			if err := sanityCheckSignature(itx.SponsorV, itx.SponsorR, itx.SponsorS, false); err != nil {
+				return err
+			}
+		}
+
 	default:
 		return ErrTxTypeNotSupported
 	}
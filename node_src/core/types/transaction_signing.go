@@ -52,29 +52,36 @@ func MakeSigner(config *params.ChainConfig, blockNumber *big.Int) Signer {
 	default:
 		signer = FrontierSigner{}
 	}
+	if config.IsSponsoredTx(blockNumber) {
+		signer = newSponsorSigner(signer, config.ChainID)
+	}
 	return signer
 }
 
 // LatestSigner returns the 'most permissive' Signer available for the given chain
-// configuration. Specifically, this enables support of EIP-155 replay protection and
-// EIP-2930 access list transactions when their respective forks are scheduled to occur at
-// any block number in the chain config.
+// configuration. Specifically, this enables support of EIP-155 replay protection,
+// EIP-2930 access list transactions and sponsored transactions when their respective
+// forks are scheduled to occur at any block number in the chain config.
 //
 // Use this in transaction-handling code where the current block number is unknown. If you
 // have the current block number available, use MakeSigner instead.
 func LatestSigner(config *params.ChainConfig) Signer {
-	if config.ChainID != nil {
-		if config.LondonBlock != nil {
-			return NewLondonSigner(config.ChainID)
-		}
-		if config.BerlinBlock != nil {
-			return NewEIP2930Signer(config.ChainID)
-		}
-		if config.EIP155Block != nil {
-			return NewEIP155Signer(config.ChainID)
-		}
+	if config.ChainID == nil {
+		return HomesteadSigner{}
 	}
-	return HomesteadSigner{}
+	var signer Signer = HomesteadSigner{}
+	switch {
+	case config.LondonBlock != nil:
+		signer = NewLondonSigner(config.ChainID)
+	case config.BerlinBlock != nil:
+		signer = NewEIP2930Signer(config.ChainID)
+	case config.EIP155Block != nil:
+		signer = NewEIP155Signer(config.ChainID)
+	}
+	if config.SponsoredTxBlock != nil {
+		signer = newSponsorSigner(signer, config.ChainID)
+	}
+	return signer
 }
 
 // LatestSignerForChainID returns the 'most permissive' Signer available. Specifically,
@@ -88,7 +95,7 @@ func LatestSignerForChainID(chainID *big.Int) Signer {
 	if chainID == nil {
 		return HomesteadSigner{}
 	}
-	return NewLondonSigner(chainID)
+	return NewSponsorSigner(chainID)
 }
 
 // SignTx signs the transaction using the given signer and private key.
@@ -171,6 +178,90 @@ type Signer interface {
 	Equal(Signer) bool
 }
 
+// sponsorSigner accepts sponsored transactions on top of the transactions accepted
+// by its parent signer, as the fork activating them doesn't depend on the others.
+type sponsorSigner struct {
+	Signer
+	chainId *big.Int
+}
+
+// NewSponsorSigner returns a signer that accepts
+// - sponsored transactions,
+// - EIP-1559 dynamic fee transactions,
+// - EIP-2930 access list transactions,
+// - EIP-155 replay protected transactions, and
+// - legacy Homestead transactions.
+func NewSponsorSigner(chainId *big.Int) Signer {
+	return newSponsorSigner(NewLondonSigner(chainId), chainId)
+}
+
+func newSponsorSigner(parent Signer, chainId *big.Int) Signer {
+	if chainId == nil {
+		chainId = new(big.Int)
+	}
+	return sponsorSigner{Signer: parent, chainId: chainId}
+}
+
+func (s sponsorSigner) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != SponsoredTxType {
+		return s.Signer.Sender(tx)
+	}
+	V, R, S := tx.RawSignatureValues()
+	// Sponsored txs are defined to use 0 and 1 as their recovery
+	// id, add 27 to become equivalent to unprotected Homestead signatures.
+	V = new(big.Int).Add(V, big.NewInt(27))
+	if tx.ChainId().Cmp(s.chainId) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	return recoverPlain(s.Hash(tx), R, S, V, true)
+}
+
+func (s sponsorSigner) ChainID() *big.Int {
+	return s.chainId
+}
+
+func (s sponsorSigner) Equal(s2 Signer) bool {
+	x, ok := s2.(sponsorSigner)
+	return ok && x.chainId.Cmp(s.chainId) == 0 && x.Signer.Equal(s.Signer)
+}
+
+func (s sponsorSigner) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	txdata, ok := tx.inner.(*SponsoredTx)
+	if !ok {
+		return s.Signer.SignatureValues(tx, sig)
+	}
+	// Check that chain ID of tx matches the signer. We also accept ID zero here,
+	// because it indicates that the chain ID was not specified in the tx.
+	if txdata.ChainID.Sign() != 0 && txdata.ChainID.Cmp(s.chainId) != 0 {
+		return nil, nil, nil, ErrInvalidChainId
+	}
+	R, S, _ = decodeSignature(sig)
+	V = big.NewInt(int64(sig[64]))
+	return R, S, V, nil
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s sponsorSigner) Hash(tx *Transaction) common.Hash {
+	if tx.Type() != SponsoredTxType {
+		return s.Signer.Hash(tx)
+	}
+	return prefixedRlpHash(
+		tx.Type(),
+		[]interface{}{
+			s.chainId,
+			tx.Nonce(),
+			tx.GasPrice(),
+			tx.Gas(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+			tx.AccessList(),
+			tx.FeePercent(),
+			tx.BlockNumLimit(),
+		})
+}
+
 type londonSigner struct{ eip2930Signer }
 
 // NewLondonSigner returns a signer that accepts
@@ -0,0 +1,18 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Vote is an attestation of a validator that a block is part of its canonical chain,
+// collected by the Congress finality gadget.
+type Vote struct {
+	Number    uint64      `json:"number"`
+	Hash      common.Hash `json:"hash"`
+	Signature []byte      `json:"signature"`
+}
+
+// ID returns the hash identifying the vote along with its signature.
+func (v *Vote) ID() common.Hash {
+	return rlpHash(v)
+}
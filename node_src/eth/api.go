@@ -31,6 +31,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/congress"
+	"github.com/ethereum/go-ethereum/consensus/congress/systemcontract"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -321,9 +323,10 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
-	Hash  common.Hash            `json:"hash"`
-	Block map[string]interface{} `json:"block"`
-	RLP   string                 `json:"rlp"`
+	Hash   common.Hash            `json:"hash"`
+	Block  map[string]interface{} `json:"block"`
+	RLP    string                 `json:"rlp"`
+	Reason string                 `json:"reason,omitempty"`
 }
 
 // GetBadBlocks returns a list of the last 'bad blocks' that the client has seen on the network
@@ -348,9 +351,10 @@ func (api *PrivateDebugAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs,
 			blockJSON = map[string]interface{}{"error": err.Error()}
 		}
 		results = append(results, &BadBlockArgs{
-			Hash:  block.Hash(),
-			RLP:   blockRlp,
-			Block: blockJSON,
+			Hash:   block.Hash(),
+			RLP:    blockRlp,
+			Block:  blockJSON,
+			Reason: rawdb.ReadBadBlockReason(api.eth.chainDb, block.Hash()),
 		})
 	}
 	return results, nil
@@ -548,6 +552,33 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 	return dirty, nil
 }
 
+// SimulateSysContractUpgrade applies the given version of the system contracts at
+// the given block on a copy of the state, replays the following blocks on it and
+// reports how the validators, balances and system contracts differ from the state
+// replayed without the upgrade. By default only the upgraded block is replayed, and
+// at most congress.MaxSimulatedBlocks are.
+func (api *PrivateDebugAPI) SimulateSysContractUpgrade(version systemcontract.SysContractVersion, at rpc.BlockNumber, blocks *uint64) (*congress.UpgradeSimulation, error) {
+	engine, ok := api.eth.Engine().(*congress.Congress)
+	if !ok {
+		return nil, errors.New("not a congress chain")
+	}
+	var number uint64
+	switch at {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		number = api.eth.blockchain.CurrentBlock().NumberU64()
+	default:
+		if at < 0 {
+			return nil, fmt.Errorf("unsupported block number tag %d", at)
+		}
+		number = uint64(at.Int64())
+	}
+	count := uint64(1)
+	if blocks != nil {
+		count = *blocks
+	}
+	return engine.SimulateUpgrade(api.eth.blockchain, version, number, count)
+}
+
 // GetAccessibleState returns the first number where the node has accessible
 // state on disk. Note this being the post-state of that block and the pre-state
 // of the next block.
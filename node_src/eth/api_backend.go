@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/congress"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -74,9 +75,27 @@ func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumb
 	if number == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock().Header(), nil
 	}
+	if number == rpc.FinalizedBlockNumber {
+		finalized, err := b.finalizedNumber()
+		if err != nil {
+			return nil, err
+		}
+		return b.eth.blockchain.GetHeaderByNumber(finalized), nil
+	}
 	return b.eth.blockchain.GetHeaderByNumber(uint64(number)), nil
 }
 
+// finalizedNumber resolves the finalized block tag through the consensus engine,
+// which only reports the finalized block while it's canonical.
+func (b *EthAPIBackend) finalizedNumber() (uint64, error) {
+	if engine, ok := b.eth.engine.(*congress.Congress); ok {
+		if number, hash := engine.Finalized(); hash != (common.Hash{}) {
+			return number, nil
+		}
+	}
+	return 0, errors.New("finalized block not found")
+}
+
 func (b *EthAPIBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.HeaderByNumber(ctx, blockNr)
@@ -108,6 +127,13 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 	if number == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock(), nil
 	}
+	if number == rpc.FinalizedBlockNumber {
+		finalized, err := b.finalizedNumber()
+		if err != nil {
+			return nil, err
+		}
+		return b.eth.blockchain.GetBlockByNumber(finalized), nil
+	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
 }
 
@@ -291,6 +317,14 @@ func (b *EthAPIBackend) JamIndex() int {
 	return b.eth.TxPool().JamIndex()
 }
 
+func (b *EthAPIBackend) JamIndexHistory(window int) []*core.JamSample {
+	return b.eth.TxPool().JamIndexHistory(window)
+}
+
+func (b *EthAPIBackend) SubscribeJamIndexEvent(ch chan<- core.JamIndexEvent) event.Subscription {
+	return b.eth.TxPool().SubscribeJamIndexEvent(ch)
+}
+
 func (b *EthAPIBackend) TxPool() *core.TxPool {
 	return b.eth.TxPool()
 }
@@ -315,6 +349,14 @@ func (b *EthAPIBackend) PricePrediction(ctx context.Context) ([]uint, error) {
 	return b.gpp.CurrentPrices(), nil
 }
 
+func (b *EthAPIBackend) FeePrediction(ctx context.Context) (*gasprice.FeePrediction, error) {
+	fees := b.gpp.CurrentFees()
+	if fees == nil {
+		return nil, errors.New("no fee prediction yet")
+	}
+	return fees, nil
+}
+
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
@@ -45,6 +45,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
+	"github.com/ethereum/go-ethereum/eth/protocols/vote"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
@@ -209,16 +210,40 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.Remotes != "" {
+		config.TxPool.Remotes = stack.ResolvePath(config.TxPool.Remotes)
+	}
+	if config.TxPool.Fairness.Allowlist != "" {
+		config.TxPool.Fairness.Allowlist = stack.ResolvePath(config.TxPool.Fairness.Allowlist)
+	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
 	// do some extra work if consensus engine is congress.
 	if congressEngine, ok := eth.engine.(*congress.Congress); ok {
 		// set state fn
 		congressEngine.SetStateFn(eth.blockchain.StateAt)
+		// persist the transactions denied by the blacklist
+		congressEngine.StartDenialAudit(config.Congress.Denials)
 		// set consensus-related transaction validator
 		eth.txPool.InitExTxValidator(congressEngine)
 		//
 		congressEngine.SetChain(eth.blockchain)
+		// index the checkpoint snapshots by epoch
+		congressEngine.SetSnapshotStore(config.Congress.Snapshots)
+		// warn the local validator before it gets jailed
+		congressEngine.StartMissedBlocksMonitor(eth.blockchain, config.Congress.MissedBlocks)
+		// report the lifecycle of the system governance proposals
+		congressEngine.StartProposalWatcher(eth.blockchain)
+		// index the side data of the canonical blocks
+		congressEngine.StartBlockIndexer(eth.blockchain)
+		// vote on new heads and finalize the blocks voted by the validators
+		congressEngine.StartFinality(eth.blockchain, config.Congress.Finality)
+	}
+	// reload the remote transactions once the extra validator is set, to drop the blacklisted ones
+	eth.txPool.LoadRemotes()
+	var votes votePool
+	if congressEngine, ok := eth.engine.(*congress.Congress); ok && config.Congress.Finality.Enabled {
+		votes = congressEngine
 	}
 
 	// Permit the downloader to use the trie cache allowance during fast sync
@@ -237,6 +262,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		EventMux:   eth.eventMux,
 		Checkpoint: checkpoint,
 		Whitelist:  config.Whitelist,
+		Votes:      votes,
 	}); err != nil {
 		return nil, err
 	}
@@ -590,6 +616,9 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 	if s.config.SnapshotCache > 0 {
 		protos = append(protos, snap.MakeProtocols((*snapHandler)(s.handler), s.snapDialCandidates)...)
 	}
+	if s.handler.votes != nil {
+		protos = append(protos, vote.MakeProtocols((*voteHandler)(s.handler))...)
+	}
 	return protos
 }
 
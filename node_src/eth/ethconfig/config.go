@@ -110,6 +110,7 @@ var Defaults = Config{
 	RPCGasCap:     50000000,
 	RPCEVMTimeout: 5 * time.Second,
 	GPO:           FullNodeGPO,
+	Congress:      congress.DefaultConfig,
 	RPCTxFeeCap:   1, // 1 ether
 }
 
@@ -199,6 +200,9 @@ type Config struct {
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
+	// Congress engine node-local options
+	Congress congress.Config
+
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
@@ -139,6 +139,20 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	}
 	head := header.Number.Uint64()
 
+	// Resolve the finalized tag to the block it points to, if the backend knows it
+	for _, number := range []*int64{&f.begin, &f.end} {
+		if *number != rpc.FinalizedBlockNumber.Int64() {
+			continue
+		}
+		finalized, err := f.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		if finalized == nil {
+			return nil, errors.New("finalized block not found")
+		}
+		*number = finalized.Number.Int64()
+	}
 	if f.begin == -1 {
 		f.begin = int64(head)
 	}
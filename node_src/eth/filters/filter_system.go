@@ -201,6 +201,10 @@ func (es *EventSystem) SubscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 		to = rpc.BlockNumber(crit.ToBlock.Int64())
 	}
 
+	// the finalized block lags behind the head, so it can't bound a live subscription
+	if from == rpc.FinalizedBlockNumber || to == rpc.FinalizedBlockNumber {
+		return nil, fmt.Errorf("finalized block tag not supported by log subscriptions")
+	}
 	// only interested in pending logs
 	if from == rpc.PendingBlockNumber && to == rpc.PendingBlockNumber {
 		return es.subscribePendingLogs(crit, logs), nil
@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 func makeReceipt(addr common.Address) *types.Receipt {
@@ -227,4 +228,19 @@ func TestFilters(t *testing.T) {
 	if len(logs) != 0 {
 		t.Error("expected 0 log, got", len(logs))
 	}
+
+	filter = NewRangeFilter(backend, 990, rpc.FinalizedBlockNumber.Int64(), []common.Address{addr}, [][]common.Hash{{hash3, hash4}})
+	if _, err := filter.Logs(context.Background()); err == nil {
+		t.Error("expected error without finalized block")
+	}
+	rawdb.WriteFinalizedBlockHash(db, chain[998].Hash())
+
+	filter = NewRangeFilter(backend, 990, rpc.FinalizedBlockNumber.Int64(), []common.Address{addr}, [][]common.Hash{{hash3, hash4}})
+	logs, _ = filter.Logs(context.Background())
+	if len(logs) != 1 {
+		t.Error("expected 1 log, got", len(logs))
+	}
+	if len(logs) > 0 && logs[0].Topics[0] != hash3 {
+		t.Errorf("expected log[0].Topics[0] to be %x, got %x", hash3, logs[0].Topics[0])
+	}
 }
@@ -0,0 +1,131 @@
+package gasprice
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FeeSuggestion is the suggested fee caps of a dynamic fee transaction for a
+// speed tier, in wei.
+type FeeSuggestion struct {
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+
+	// Confidence is the share of the pending transactions the tier is evaluated
+	// at that the pool actually holds, from 0 to 1. It is 1 if the pool is empty.
+	Confidence float64
+
+	// ExpectedBlocks is the number of blocks expected to include a transaction
+	// paying the suggested tip.
+	ExpectedBlocks uint64
+}
+
+// FeePrediction is the fee suggestions of the fast, median and low tiers for the
+// block following Number.
+type FeePrediction struct {
+	Number  uint64
+	BaseFee *big.Int // Projected base fee of the next block, nil before London
+	Tiers   []*FeeSuggestion
+}
+
+// projectBaseFee returns the base fee of the block following head, nil if the
+// block isn't a London one.
+func (p *Prediction) projectBaseFee(head *types.Header) *big.Int {
+	config := p.backend.ChainConfig()
+	if !config.IsLondon(new(big.Int).Add(head.Number, big.NewInt(1))) {
+		return nil
+	}
+	return misc.CalcBaseFee(config, head)
+}
+
+// updateFees evaluates the fee suggestions from the pending transactions.
+func (p *Prediction) updateFees(txs TxByPrice, avgTxCnt int) {
+	p.lockPredis.RLock()
+	number, baseFee := p.headNumber, p.baseFee
+	p.lockPredis.RUnlock()
+
+	tiers := SuggestFees(p.cfg.PredConfig, txs, baseFee, p.pool.GasPrice(), avgTxCnt)
+	p.updateFeePrediction(&FeePrediction{Number: number, BaseFee: baseFee, Tiers: tiers})
+}
+
+// SuggestFees returns the fee suggestions of the fast, median and low tiers. The
+// pending transactions are ranked by the tip they would effectively pay on top
+// of the base fee, and the tips suggested are at least minTip. The average tx
+// count is taken as at least one, as the expected blocks are derived from it.
+func SuggestFees(cfg PredConfig, txs TxByPrice, baseFee, minTip *big.Int, avgTxCnt int) []*FeeSuggestion {
+	if avgTxCnt < 1 {
+		avgTxCnt = 1
+	}
+	tips := make([]*big.Int, 0, len(txs))
+	for _, tx := range txs {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			// fee cap below the base fee, not includable
+			continue
+		}
+		tips = append(tips, tip)
+	}
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i].Cmp(tips[j]) > 0 // descending
+	})
+
+	depths := []int{
+		cfg.FastFactor * avgTxCnt,
+		max(cfg.MedianFactor*avgTxCnt, cfg.MinMedianIndex),
+		max(cfg.LowFactor*avgTxCnt, cfg.MinLowIndex),
+	}
+	percentiles := []int{cfg.FastPercentile, cfg.MeidanPercentile, 100}
+
+	pendingCnt := len(tips)
+	tiers := make([]*FeeSuggestion, len(depths))
+	for i, depth := range depths {
+		tip, idx, confidence := minTip, 0, 1.0
+		if pendingCnt > 0 {
+			idx = depth
+			if pendingCnt <= depth {
+				// not enough pending txs, fall back to a percentile of them
+				idx = pendingCnt * percentiles[i] / 100
+				confidence = float64(pendingCnt) / float64(depth)
+			}
+			if idx < pendingCnt {
+				tip = tips[idx]
+			}
+		}
+		if tip.Cmp(minTip) < 0 {
+			tip = minTip
+		}
+		tiers[i] = &FeeSuggestion{
+			MaxFeePerGas:         maxFeePerGas(tip, baseFee),
+			MaxPriorityFeePerGas: new(big.Int).Set(tip),
+			Confidence:           confidence,
+			ExpectedBlocks:       uint64(idx/avgTxCnt) + 1,
+		}
+	}
+	return tiers
+}
+
+// maxFeePerGas returns the fee cap paying the tip on top of twice the base fee,
+// leaving room for the base fee to rise before the transaction gets included.
+func maxFeePerGas(tip, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return new(big.Int).Set(tip)
+	}
+	return new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(2)))
+}
+
+func (p *Prediction) updateFeePrediction(fees *FeePrediction) {
+	p.lockPredis.Lock()
+	p.fees = fees
+	p.lockPredis.Unlock()
+}
+
+// CurrentFees returns the current fee suggestions of the fast, median and low
+// tiers, nil before the first prediction. The result should be readonly.
+func (p *Prediction) CurrentFees() *FeePrediction {
+	p.lockPredis.RLock()
+	defer p.lockPredis.RUnlock()
+	return p.fees
+}
@@ -0,0 +1,105 @@
+package gasprice
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func gweis(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.GWei))
+}
+
+// Tests that the fee suggestions rank the pending transactions by the tip they
+// effectively pay on top of the base fee.
+func TestSuggestFees(t *testing.T) {
+	cfg := PredConfig{
+		FastFactor:       1,
+		MedianFactor:     2,
+		LowFactor:        4,
+		FastPercentile:   75,
+		MeidanPercentile: 90,
+	}
+	baseFee, minTip := gweis(100), gweis(1)
+
+	var txs TxByPrice
+	for tip := int64(10); tip > 1; tip-- {
+		txs = append(txs, types.NewTx(&types.DynamicFeeTx{GasTipCap: gweis(tip), GasFeeCap: gweis(1000)}))
+	}
+	// legacy tx paying a tip of 1 gwei, and a tx which can't pay the base fee
+	txs = append(txs, types.NewTx(&types.LegacyTx{GasPrice: gweis(101)}))
+	txs = append(txs, types.NewTx(&types.DynamicFeeTx{GasTipCap: gweis(50), GasFeeCap: gweis(99)}))
+
+	tests := []struct {
+		txs        TxByPrice
+		tips       []int64
+		confidence []float64
+		blocks     []uint64
+	}{
+		// enough pending txs for every tier
+		{txs, []int64{8, 6, 2}, []float64{1, 1, 1}, []uint64{2, 3, 5}},
+		// few pending txs, falling back to percentiles
+		{txs[:3], []int64{8, 8, 1}, []float64{1, 0.75, 0.375}, []uint64{2, 2, 2}},
+		// empty pool
+		{nil, []int64{1, 1, 1}, []float64{1, 1, 1}, []uint64{1, 1, 1}},
+	}
+	for i, tt := range tests {
+		tiers := SuggestFees(cfg, tt.txs, baseFee, minTip, 2)
+		if len(tiers) != 3 {
+			t.Fatalf("test %d: have %d tiers, want 3", i, len(tiers))
+		}
+		for j, tier := range tiers {
+			tip := gweis(tt.tips[j])
+			if tier.MaxPriorityFeePerGas.Cmp(tip) != 0 {
+				t.Errorf("test %d tier %d: tip mismatch: have %v, want %v", i, j, tier.MaxPriorityFeePerGas, tip)
+			}
+			if maxFee := new(big.Int).Add(tip, gweis(200)); tier.MaxFeePerGas.Cmp(maxFee) != 0 {
+				t.Errorf("test %d tier %d: fee cap mismatch: have %v, want %v", i, j, tier.MaxFeePerGas, maxFee)
+			}
+			if tier.Confidence != tt.confidence[j] {
+				t.Errorf("test %d tier %d: confidence mismatch: have %v, want %v", i, j, tier.Confidence, tt.confidence[j])
+			}
+			if tier.ExpectedBlocks != tt.blocks[j] {
+				t.Errorf("test %d tier %d: expected blocks mismatch: have %d, want %d", i, j, tier.ExpectedBlocks, tt.blocks[j])
+			}
+		}
+	}
+	// Without base fee, the fee cap is the tip
+	tiers := SuggestFees(cfg, txs[:1], nil, minTip, 2)
+	if tiers[0].MaxFeePerGas.Cmp(tiers[0].MaxPriorityFeePerGas) != 0 {
+		t.Errorf("pre-London fee cap mismatch: have %v, want %v", tiers[0].MaxFeePerGas, tiers[0].MaxPriorityFeePerGas)
+	}
+	// Without any block stats, the expected blocks are counted as one tx per block
+	tiers = SuggestFees(cfg, txs, baseFee, minTip, 0)
+	if tiers[0].ExpectedBlocks != 2 {
+		t.Errorf("expected blocks without stats mismatch: have %d, want 2", tiers[0].ExpectedBlocks)
+	}
+}
+
+// Tests that the sub-gwei tips are kept for the fee tiers and dropped only from the
+// legacy prices.
+func TestFilterPending(t *testing.T) {
+	var (
+		cheap = types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(params.GWei / 2), GasFeeCap: gweis(1000), Gas: 21000})
+		tx    = types.NewTx(&types.DynamicFeeTx{GasTipCap: gweis(2), GasFeeCap: gweis(1000), Gas: 21000})
+		large = types.NewTx(&types.DynamicFeeTx{GasTipCap: gweis(2), GasFeeCap: gweis(1000), Gas: 8000000})
+	)
+	pending := FilterPending(TxByPrice{cheap, large, tx}, 10000000, 60, func(*types.Transaction) time.Duration { return 0 })
+	if len(pending) != 2 {
+		t.Fatalf("pending txs mismatch: have %d, want 2", len(pending))
+	}
+	tiers := SuggestFees(PredConfig{FastFactor: 1, MedianFactor: 1, LowFactor: 1, FastPercentile: 100, MeidanPercentile: 100}, pending, gweis(100), big.NewInt(1), 1)
+	if tip := big.NewInt(params.GWei / 2); tiers[2].MaxPriorityFeePerGas.Cmp(tip) != 0 {
+		t.Errorf("low tier tip mismatch: have %v, want %v", tiers[2].MaxPriorityFeePerGas, tip)
+	}
+	if legacy := filterCheap(pending); len(legacy) != 1 || legacy[0] != tx {
+		t.Errorf("legacy txs mismatch: have %d, want 1", len(legacy))
+	}
+	// Stale transactions are dropped too
+	if stale := FilterPending(TxByPrice{tx}, 10000000, 60, func(*types.Transaction) time.Duration { return time.Hour }); len(stale) != 0 {
+		t.Errorf("stale txs kept: %d", len(stale))
+	}
+}
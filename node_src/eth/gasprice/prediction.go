@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -27,6 +28,9 @@ type Prediction struct {
 	pool         *core.TxPool
 
 	predis        []uint // gas price prediction in gwei, currently will be 3 items, from hight(fast) to low(slow)
+	fees          *FeePrediction
+	headNumber    uint64   // number of the current head
+	baseFee       *big.Int // projected base fee of the block following the head
 	lockPredis    sync.RWMutex
 	wg            sync.WaitGroup
 	blockGasLimit uint64
@@ -113,6 +117,8 @@ func (p *Prediction) initTxCnts() {
 
 	//gas limit
 	p.blockGasLimit = head.GasLimit
+
+	p.headNumber, p.baseFee = num, p.projectBaseFee(head)
 }
 
 func (p *Prediction) loop() {
@@ -132,6 +138,10 @@ func (p *Prediction) loop() {
 			txcnt := len(head.Transactions())
 			p.txCnts.Add(txcnt)
 			p.blockGasLimit = head.GasLimit()
+			baseFee := p.projectBaseFee(head.Header())
+			p.lockPredis.Lock()
+			p.headNumber, p.baseFee = head.NumberU64(), baseFee
+			p.lockPredis.Unlock()
 		case <-p.chainHeadSub.Err():
 			log.Warn("prediction loop quitting")
 			return
@@ -146,6 +156,15 @@ func (p *Prediction) update() {
 		byprice = append(byprice, ts...)
 	}
 	byprice = p.filteroutInvalid(byprice)
+
+	avgTxCnt := p.txCnts.Avg()
+	if avgTxCnt < p.cfg.MinTxCntPerBlock {
+		avgTxCnt = p.cfg.MinTxCntPerBlock
+	}
+	p.updateFees(byprice, avgTxCnt)
+
+	// The legacy prices are in gwei, so the sub-gwei tips would skew them
+	byprice = filterCheap(byprice)
 	sort.Sort(byprice)
 
 	minPrice := wei2GWei(p.pool.GasPrice())
@@ -159,11 +178,6 @@ func (p *Prediction) update() {
 		return
 	}
 
-	avgTxCnt := p.txCnts.Avg()
-	if avgTxCnt < p.cfg.MinTxCntPerBlock {
-		avgTxCnt = p.cfg.MinTxCntPerBlock
-	}
-
 	// fast price
 	fi := p.cfg.FastFactor * avgTxCnt
 	if pendingCnt <= fi {
@@ -200,14 +214,21 @@ func (p *Prediction) update() {
 }
 
 func (p *Prediction) filteroutInvalid(txs TxByPrice) TxByPrice {
-	maxgas := (p.blockGasLimit / 10) * 6
-	maxlive := time.Duration(p.cfg.MaxValidPendingSecs) * time.Second
+	return FilterPending(txs, p.blockGasLimit, p.cfg.MaxValidPendingSecs, func(tx *types.Transaction) time.Duration {
+		return time.Since(tx.LocalSeenTime())
+	})
+}
+
+// FilterPending drops the pending txs too large for a block or pending for longer
+// than maxPendingSecs, which would skew the prices.
+func FilterPending(txs TxByPrice, blockGasLimit uint64, maxPendingSecs int, age func(tx *types.Transaction) time.Duration) TxByPrice {
+	maxgas := (blockGasLimit / 10) * 6
+	maxlive := time.Duration(maxPendingSecs) * time.Second
 	i, j := 0, len(txs)
 	for i < j {
 		tx := txs[i]
 		if tx.Gas() > maxgas ||
-			time.Since(tx.LocalSeenTime()) > maxlive ||
-			tx.GasTipCapIntCmp(gwei) < 0 {
+			age(tx) > maxlive {
 			j--
 			txs[i], txs[j] = txs[j], txs[i]
 			continue
@@ -218,6 +239,20 @@ func (p *Prediction) filteroutInvalid(txs TxByPrice) TxByPrice {
 	return txs[:j]
 }
 
+// filterCheap drops the pending txs paying a tip below 1 gwei.
+func filterCheap(txs TxByPrice) TxByPrice {
+	i, j := 0, len(txs)
+	for i < j {
+		if txs[i].GasTipCapIntCmp(gwei) < 0 {
+			j--
+			txs[i], txs[j] = txs[j], txs[i]
+			continue
+		}
+		i++
+	}
+	return txs[:j]
+}
+
 func (p *Prediction) updatePredis(prices []uint) {
 	p.lockPredis.Lock()
 	for i := 0; i < 3; i++ {
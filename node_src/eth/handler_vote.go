@@ -0,0 +1,116 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus/congress"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/vote"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// voteChanSize is the size of channel listening to NewVoteEvent.
+const voteChanSize = 256
+
+// errVotePeerRegistered is returned if a `vote` peer is already registered.
+var errVotePeerRegistered = errors.New("vote peer already registered")
+
+// votePool defines the methods needed from the finality gadget to exchange the
+// validator votes with the network.
+type votePool interface {
+	// AddVote validates and tallies a vote received from the network.
+	AddVote(vote *types.Vote) error
+
+	// SubscribeNewVotes should return an event subscription of NewVoteEvent and
+	// send events to the given channel.
+	SubscribeNewVotes(ch chan<- congress.NewVoteEvent) (event.Subscription, error)
+}
+
+// voteHandler implements the vote.Backend interface to handle the votes that are
+// broadcast by the validators.
+type voteHandler handler
+
+// RunPeer is invoked when a peer joins on the `vote` protocol.
+func (h *voteHandler) RunPeer(peer *vote.Peer, hand vote.Handler) error {
+	h.votePeersLock.Lock()
+	if _, ok := h.votePeers[peer.ID()]; ok {
+		h.votePeersLock.Unlock()
+		return errVotePeerRegistered
+	}
+	h.votePeers[peer.ID()] = peer
+	h.votePeersLock.Unlock()
+
+	defer func() {
+		h.votePeersLock.Lock()
+		delete(h.votePeers, peer.ID())
+		h.votePeersLock.Unlock()
+	}()
+	return hand(peer)
+}
+
+// PeerInfo retrieves all known `vote` information about a peer.
+func (h *voteHandler) PeerInfo(id enode.ID) interface{} {
+	h.votePeersLock.RLock()
+	defer h.votePeersLock.RUnlock()
+
+	if p, ok := h.votePeers[id.String()]; ok {
+		return &vote.PeerInfo{Version: p.Version()}
+	}
+	return nil
+}
+
+// Handle is invoked from a peer's message handler when it receives a new remote
+// message that the handler couldn't consume and serve itself.
+func (h *voteHandler) Handle(peer *vote.Peer, packet vote.Packet) error {
+	switch packet := packet.(type) {
+	case *vote.VotesPacket:
+		for _, v := range *packet {
+			if err := h.votes.AddVote(v); err != nil {
+				// Votes are only relayed once validated, so a peer sending a forged
+				// one is malicious and gets dropped
+				if errors.Is(err, congress.ErrInvalidVote) {
+					return fmt.Errorf("%w: number %d, hash %x", err, v.Number, v.Hash)
+				}
+				peer.Log().Debug("Discarded vote", "number", v.Number, "hash", v.Hash, "err", err)
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unexpected vote packet type: %T", packet)
+	}
+}
+
+// BroadcastVotes propagates a batch of votes to all `vote` peers which are not
+// known to already have them.
+func (h *handler) BroadcastVotes(votes []*types.Vote) {
+	h.votePeersLock.RLock()
+	defer h.votePeersLock.RUnlock()
+
+	for _, peer := range h.votePeers {
+		var unknown []*types.Vote
+		for _, v := range votes {
+			if !peer.KnownVote(v.ID()) {
+				unknown = append(unknown, v)
+			}
+		}
+		if len(unknown) > 0 {
+			peer.AsyncSendVotes(unknown)
+		}
+	}
+}
+
+// voteBroadcastLoop announces new votes to connected peers.
+func (h *handler) voteBroadcastLoop() {
+	defer h.wg.Done()
+	for {
+		select {
+		case event := <-h.votesCh:
+			h.BroadcastVotes([]*types.Vote{event.Vote})
+		case <-h.votesSub.Err():
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package vote
+
+import (
+	mapset "github.com/deckarep/golang-set"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+const (
+	// maxKnownVotes is the maximum vote hashes to keep in the known list
+	// before starting to randomly evict them.
+	maxKnownVotes = 8192
+
+	// maxQueuedVotes is the maximum number of vote batches to queue up before
+	// dropping broadcasts.
+	maxQueuedVotes = 128
+)
+
+// Peer is a collection of relevant information we have about a `vote` peer.
+type Peer struct {
+	id string // Unique ID for the peer, cached
+
+	*p2p.Peer                   // The embedded P2P package peer
+	rw        p2p.MsgReadWriter // Input/output streams for vote
+	version   uint              // Protocol version negotiated
+
+	knownVotes mapset.Set         // Set of vote hashes known to be known by this peer
+	queue      chan []*types.Vote // Queue of votes to broadcast to the peer
+	term       chan struct{}      // Termination channel to stop the broadcaster
+
+	logger log.Logger // Contextual logger with the peer id injected
+}
+
+// newPeer create a wrapper for a network connection and negotiated  protocol
+// version.
+func newPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
+	id := p.ID().String()
+	peer := &Peer{
+		id:         id,
+		Peer:       p,
+		rw:         rw,
+		version:    version,
+		knownVotes: mapset.NewSet(),
+		queue:      make(chan []*types.Vote, maxQueuedVotes),
+		term:       make(chan struct{}),
+		logger:     log.New("peer", id[:8]),
+	}
+	go peer.broadcastVotes()
+	return peer
+}
+
+// close signals the broadcast goroutine to terminate.
+func (p *Peer) close() {
+	close(p.term)
+}
+
+// ID retrieves the peer's unique identifier.
+func (p *Peer) ID() string {
+	return p.id
+}
+
+// Version retrieves the peer's negoatiated `vote` protocol version.
+func (p *Peer) Version() uint {
+	return p.version
+}
+
+// Log overrides the P2P logget with the higher level one containing only the id.
+func (p *Peer) Log() log.Logger {
+	return p.logger
+}
+
+// KnownVote returns whether the peer is known to already have a vote.
+func (p *Peer) KnownVote(hash common.Hash) bool {
+	return p.knownVotes.Contains(hash)
+}
+
+// markVotes marks the votes as known for the peer, ensuring that they will
+// never be propagated to this particular peer.
+func (p *Peer) markVotes(votes []*types.Vote) {
+	for p.knownVotes.Cardinality() > maxKnownVotes-len(votes) && p.knownVotes.Cardinality() > 0 {
+		p.knownVotes.Pop()
+	}
+	for _, vote := range votes {
+		p.knownVotes.Add(vote.ID())
+	}
+}
+
+// AsyncSendVotes queues a batch of votes for propagation to the remote peer. If
+// the peer's broadcast queue is full, the votes are silently dropped.
+func (p *Peer) AsyncSendVotes(votes []*types.Vote) {
+	select {
+	case p.queue <- votes:
+		p.markVotes(votes)
+	case <-p.term:
+		p.Log().Debug("Dropping vote propagation", "count", len(votes))
+	default:
+		p.Log().Debug("Dropping vote propagation", "count", len(votes))
+	}
+}
+
+// broadcastVotes is a write loop that schedules vote broadcasts to the remote
+// peer. The goal is to have an async writer that does not lock up node internals
+// and at the same time rate limits queued data.
+func (p *Peer) broadcastVotes() {
+	for {
+		select {
+		case votes := <-p.queue:
+			if err := p2p.Send(p.rw, VotesMsg, VotesPacket(votes)); err != nil {
+				return
+			}
+			p.Log().Trace("Propagated votes", "count", len(votes))
+		case <-p.term:
+			return
+		}
+	}
+}
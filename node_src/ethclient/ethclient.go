@@ -563,6 +563,71 @@ func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) er
 	return ec.c.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
 }
 
+// SignMetaTransaction asks the node to cover feePercent of the fee of the unsigned
+// transaction sent by from with the fee address, whose key must be unlocked on the
+// node. It returns the unsigned transaction carrying the fee cover, for the sender
+// to sign and send. The fee cover is valid until blockNumLimit.
+//
+// Use types.SignMetaTx to cover the fee with a local key instead.
+func (ec *Client) SignMetaTransaction(ctx context.Context, tx *types.Transaction, from common.Address, feeAddress common.Address, feePercent uint64, blockNumLimit uint64) (*types.Transaction, error) {
+	if tx.Type() == types.DynamicFeeTxType {
+		return nil, types.ErrMetaTxType
+	}
+	arg := map[string]interface{}{
+		"from":          from,
+		"to":            tx.To(),
+		"gas":           hexutil.Uint64(tx.Gas()),
+		"gasPrice":      (*hexutil.Big)(tx.GasPrice()),
+		"value":         (*hexutil.Big)(tx.Value()),
+		"nonce":         hexutil.Uint64(tx.Nonce()),
+		"input":         hexutil.Bytes(tx.Data()),
+		"feeAddress":    feeAddress,
+		"feePercent":    hexutil.Uint64(feePercent),
+		"blockNumLimit": hexutil.Uint64(blockNumLimit),
+	}
+	if tx.Type() == types.AccessListTxType {
+		arg["accessList"] = tx.AccessList()
+		arg["chainId"] = (*hexutil.Big)(tx.ChainId())
+	}
+	var result struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := ec.c.CallContext(ctx, &result, "eth_signMetaTransaction", arg); err != nil {
+		return nil, err
+	}
+	meta := new(types.Transaction)
+	if err := meta.UnmarshalBinary(result.Raw); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// SponsorTransaction asks the node to sign the fee cover of the sponsored transaction,
+// already signed by its sender, with the sponsor, whose key must be unlocked on the
+// node. It returns the transaction ready to be sent.
+//
+// Use types.SignSponsor to cover the fee with a local key instead.
+func (ec *Client) SponsorTransaction(ctx context.Context, tx *types.Transaction, sponsor common.Address) (*types.Transaction, error) {
+	if tx.Type() != types.SponsoredTxType {
+		return nil, types.ErrTxTypeNotSupported
+	}
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := ec.c.CallContext(ctx, &result, "eth_sponsorTransaction", hexutil.Bytes(data), sponsor); err != nil {
+		return nil, err
+	}
+	sponsored := new(types.Transaction)
+	if err := sponsored.UnmarshalBinary(result.Raw); err != nil {
+		return nil, err
+	}
+	return sponsored, nil
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
@@ -38,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/congress"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
@@ -132,6 +133,50 @@ func (s *PublicEthereumAPI) GasPricePrediction(ctx context.Context) (map[string]
 	}, nil
 }
 
+type feeSuggestionResult struct {
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	Confidence           float64        `json:"confidence"`
+	ExpectedBlocks       hexutil.Uint64 `json:"expectedBlocks"`
+}
+
+type feePredictionResult struct {
+	Number  hexutil.Uint64       `json:"number"`
+	BaseFee *hexutil.Big         `json:"baseFeePerGas,omitempty"`
+	Fast    *feeSuggestionResult `json:"fast"`
+	Median  *feeSuggestionResult `json:"median"`
+	Low     *feeSuggestionResult `json:"low"`
+}
+
+// GasPricePredictionV2 returns the suggested fee caps in wei of a dynamic fee
+// transaction for the fast, median and low tiers, along with the projected base
+// fee of the next block.
+func (s *PublicEthereumAPI) GasPricePredictionV2(ctx context.Context) (*feePredictionResult, error) {
+	fees, err := s.b.FeePrediction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(fees.Tiers) != 3 {
+		return nil, errors.New("invalid fee prediction")
+	}
+	tiers := make([]*feeSuggestionResult, len(fees.Tiers))
+	for i, tier := range fees.Tiers {
+		tiers[i] = &feeSuggestionResult{
+			MaxFeePerGas:         (*hexutil.Big)(tier.MaxFeePerGas),
+			MaxPriorityFeePerGas: (*hexutil.Big)(tier.MaxPriorityFeePerGas),
+			Confidence:           tier.Confidence,
+			ExpectedBlocks:       hexutil.Uint64(tier.ExpectedBlocks),
+		}
+	}
+	return &feePredictionResult{
+		Number:  hexutil.Uint64(fees.Number),
+		BaseFee: (*hexutil.Big)(fees.BaseFee),
+		Fast:    tiers[0],
+		Median:  tiers[1],
+		Low:     tiers[2],
+	}, nil
+}
+
 // Syncing returns false in case the node is currently not syncing with the network. It can be up to date or has not
 // yet received the latest block headers from its pears. In case it is synchronizing:
 // - startingBlock: block number this node started to synchronise from
@@ -303,6 +348,51 @@ func (s *PublicTxPoolAPI) JamIndex() int {
 	return s.b.JamIndex()
 }
 
+// JamIndexHistory returns the latest window samples of the jam index along with
+// their components, from the oldest to the newest. All the samples kept are
+// returned if window is omitted.
+func (s *PublicTxPoolAPI) JamIndexHistory(window *uint64) []*core.JamSample {
+	var n int
+	if window != nil {
+		n = int(*window)
+	}
+	samples := s.b.JamIndexHistory(n)
+	if samples == nil {
+		samples = make([]*core.JamSample, 0)
+	}
+	return samples
+}
+
+// SubscribeJamIndex sends a notification each time the transaction pool evaluates
+// a new jam index, with the components it was evaluated from.
+func (s *PublicTxPoolAPI) SubscribeJamIndex(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		samples := make(chan core.JamIndexEvent, 16)
+		sub := s.b.SubscribeJamIndexEvent(samples)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-samples:
+				notifier.Notify(rpcSub.ID, ev.Sample)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
 // PublicAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type PublicAccountAPI struct {
@@ -555,6 +645,29 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args Transactio
 	return &SignTransactionResult{data, signed}, nil
 }
 
+// SignMetaTransaction signs the fee cover of a transaction with the fee address,
+// whose key is decrypted with the given password, and returns the unsigned
+// transaction carrying it.
+func (s *PrivateAccountAPI) SignMetaTransaction(ctx context.Context, args MetaTransactionArgs, passwd string) (*SignTransactionResult, error) {
+	tx, err := args.toMetaTransaction(s.b, func(data []byte) ([]byte, error) {
+		account := accounts.Account{Address: *args.FeeAddress}
+		wallet, err := s.am.Find(account)
+		if err != nil {
+			return nil, err
+		}
+		return wallet.SignDataWithPassphrase(account, passwd, accounts.MimetypeMetaTransaction, data)
+	})
+	if err != nil {
+		log.Warn("Failed meta transaction sign attempt", "from", args.from(), "feeAddress", args.FeeAddress, "err", err)
+		return nil, err
+	}
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{data, tx}, nil
+}
+
 // Sign calculates an Ethereum ECDSA signature for:
 // keccack256("\x19Ethereum Signed Message:\n" + len(message) + message))
 //
@@ -1120,6 +1233,94 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args TransactionArgs, bl
 	return result.Return(), result.Err
 }
 
+// complianceEngine is a consensus engine enforcing a blacklist, which can check
+// transactions against it in a dry run.
+type complianceEngine interface {
+	NewComplianceChecker(parent *types.Header, statedb *state.StateDB) (*congress.ComplianceChecker, error)
+}
+
+// ComplianceReport is the result of checking a transaction against the blacklist.
+type ComplianceReport struct {
+	Denied  bool                      `json:"denied"`          // Whether the transaction would be denied
+	Hits    []*congress.ComplianceHit `json:"hits"`            // Every check which would deny the transaction
+	GasUsed hexutil.Uint64            `json:"gasUsed"`         // Gas used by the dry run, which ignores the hits
+	Error   string                    `json:"error,omitempty"` // Execution error of the dry run, if any
+}
+
+// CheckCompliance executes the given transaction like eth_call, running every
+// blacklist check on the sender, the recipient, the calls and the events, and
+// reports all the checks which would deny it. The hits don't abort the execution,
+// so the checks after a hit are reported too. Like the transaction pool, the
+// blacklist is the one in effect for the block after the given one.
+func (s *PublicBlockChainAPI) CheckCompliance(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*ComplianceReport, error) {
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	engine, ok := s.b.Engine().(complianceEngine)
+	if !ok {
+		return nil, errors.New("compliance checks not supported by the consensus engine")
+	}
+	statedb, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	checker, err := engine.NewComplianceChecker(header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := args.ToMessage(s.b.RPCGasCap(), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	checker.CheckTx(msg.From(), msg.To())
+
+	// Setup context so it may be cancelled the call has completed
+	var cancel context.CancelFunc
+	if timeout := s.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	evm, vmError, err := s.b.GetEVM(ctx, msg, statedb, header, &vm.Config{NoBaseFee: true, Debug: true, Tracer: checker})
+	if err != nil {
+		return nil, err
+	}
+	evm.Context.ExtraValidator = checker
+
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+	if err := vmError(); err != nil {
+		return nil, err
+	}
+	if evm.Cancelled() {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", s.b.RPCEVMTimeout())
+	}
+	report := &ComplianceReport{
+		Denied: checker.Denied(),
+		Hits:   checker.Hits,
+	}
+	if report.Hits == nil {
+		report.Hits = []*congress.ComplianceHit{}
+	}
+	switch {
+	case err != nil:
+		report.Error = err.Error()
+	case len(result.Revert()) > 0:
+		report.GasUsed, report.Error = hexutil.Uint64(result.UsedGas), newRevertError(result).Error()
+	case result.Err != nil:
+		report.GasUsed, report.Error = hexutil.Uint64(result.UsedGas), result.Err.Error()
+	default:
+		report.GasUsed = hexutil.Uint64(result.UsedGas)
+	}
+	return report, nil
+}
+
 func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, gasCap uint64) (g hexutil.Uint64, e error) {
 	start := time.Now()
 	defer func() {
@@ -1435,6 +1636,14 @@ type RPCTransaction struct {
 	V                *hexutil.Big      `json:"v"`
 	R                *hexutil.Big      `json:"r"`
 	S                *hexutil.Big      `json:"s"`
+
+	// Fee cover of meta transactions
+	FeeAddress    *common.Address `json:"feeAddress,omitempty"`
+	FeePercent    *hexutil.Uint64 `json:"feePercent,omitempty"`
+	BlockNumLimit *hexutil.Uint64 `json:"blockNumLimit,omitempty"`
+	SponsorV      *hexutil.Big    `json:"sponsorV,omitempty"`
+	SponsorR      *hexutil.Big    `json:"sponsorR,omitempty"`
+	SponsorS      *hexutil.Big    `json:"sponsorS,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1482,6 +1691,26 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
 		}
 	}
+	switch {
+	case tx.Type() == types.SponsoredTxType:
+		al := tx.AccessList()
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		if sponsor, err := types.Sponsor(tx, from); err == nil {
+			result.FeeAddress = &sponsor
+		}
+		feePercent, blockNumLimit := tx.FeePercent(), tx.BlockNumLimit()
+		result.FeePercent = (*hexutil.Uint64)(&feePercent)
+		result.BlockNumLimit = (*hexutil.Uint64)(&blockNumLimit)
+		sv, sr, ss := tx.RawSponsorSignatureValues()
+		result.SponsorV, result.SponsorR, result.SponsorS = (*hexutil.Big)(sv), (*hexutil.Big)(sr), (*hexutil.Big)(ss)
+	case types.IsMetaTransaction(tx.Data()):
+		if metadata, feeAddr, err := types.DecodeMetaTx(tx, from, config.ChainID); err == nil {
+			result.FeeAddress = &feeAddr
+			result.FeePercent = (*hexutil.Uint64)(&metadata.FeePercent)
+			result.BlockNumLimit = (*hexutil.Uint64)(&metadata.BlockNumLimit)
+		}
+	}
 	return result
 }
 
@@ -1806,9 +2035,37 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	// Split the fee of meta transactions between the sender and the fee address
+	switch {
+	case tx.Type() == types.SponsoredTxType:
+		if sponsor, err := types.Sponsor(tx, from); err == nil {
+			senderFee, feeAddrFee := metaFees(tx.FeePercent(), tx, receipt.GasUsed)
+			fields["feeAddress"] = sponsor
+			fields["feePercent"] = hexutil.Uint64(tx.FeePercent())
+			fields["senderFee"] = (*hexutil.Big)(senderFee)
+			fields["feeAddressFee"] = (*hexutil.Big)(feeAddrFee)
+		}
+	case types.IsMetaTransaction(tx.Data()):
+		if metadata, feeAddr, err := types.DecodeMetaTx(tx, from, s.b.ChainConfig().ChainID); err == nil {
+			senderFee, feeAddrFee := metaFees(metadata.FeePercent, tx, receipt.GasUsed)
+			fields["feeAddress"] = feeAddr
+			fields["feePercent"] = hexutil.Uint64(metadata.FeePercent)
+			fields["senderFee"] = (*hexutil.Big)(senderFee)
+			fields["feeAddressFee"] = (*hexutil.Big)(feeAddrFee)
+		}
+	}
 	return fields, nil
 }
 
+// metaFees returns the fee paid by the sender and by the fee address of a meta
+// transaction. Both are charged for the whole gas limit and refunded for the gas
+// left, rounding down each time.
+func metaFees(feePercent uint64, tx *types.Transaction, gasUsed uint64) (*big.Int, *big.Int) {
+	sender, feeAddr := types.MetaFees(feePercent, tx.Gas(), tx.GasPrice())
+	senderRefund, feeAddrRefund := types.MetaFees(feePercent, tx.Gas()-gasUsed, tx.GasPrice())
+	return sender.Sub(sender, senderRefund), feeAddr.Sub(feeAddr, feeAddrRefund)
+}
+
 // sign is a helper function that signs a transaction with the private key of the given address.
 func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
 	// Look up the wallet containing the requested signer
@@ -1919,7 +2176,7 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, input
 check tx meta transaction format.
 */
 func metaTransactionCheck(ctx context.Context, tx *types.Transaction, b Backend) error {
-	if types.IsMetaTransaction(tx.Data()) {
+	if tx.Type() != types.SponsoredTxType && types.IsMetaTransaction(tx.Data()) {
 		metaData, err := types.DecodeMetaData(tx.Data(), b.CurrentBlock().Number())
 		if err != nil {
 			return err
@@ -2021,6 +2278,72 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Tra
 	return &SignTransactionResult{data, signed}, nil
 }
 
+// SignMetaTransaction signs the fee cover of a transaction with the fee address,
+// which must be unlocked, and returns the unsigned transaction carrying it. The
+// sender then signs and submits it, paying only the part of the fee not covered.
+func (s *PublicTransactionPoolAPI) SignMetaTransaction(ctx context.Context, args MetaTransactionArgs) (*SignTransactionResult, error) {
+	tx, err := args.toMetaTransaction(s.b, func(data []byte) ([]byte, error) {
+		account := accounts.Account{Address: *args.FeeAddress}
+		wallet, err := s.b.AccountManager().Find(account)
+		if err != nil {
+			return nil, err
+		}
+		return wallet.SignData(account, accounts.MimetypeMetaTransaction, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{data, tx}, nil
+}
+
+// SponsorTransaction signs the fee cover of a sponsored transaction, already signed
+// by its sender, with the sponsor, which must be unlocked. It returns the transaction
+// ready to be submitted.
+func (s *PublicTransactionPoolAPI) SponsorTransaction(ctx context.Context, input hexutil.Bytes, sponsor common.Address) (*SignTransactionResult, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return nil, err
+	}
+	if tx.Type() != types.SponsoredTxType {
+		return nil, types.ErrTxTypeNotSupported
+	}
+	if head := s.b.CurrentHeader().Number.Uint64(); tx.BlockNumLimit() <= head {
+		return nil, fmt.Errorf("blockNumLimit %d not above the current block %d", tx.BlockNumLimit(), head)
+	}
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.LatestSigner(s.b.ChainConfig()), tx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := types.SponsorSigningData(tx, from)
+	if err != nil {
+		return nil, err
+	}
+	account := accounts.Account{Address: sponsor}
+	wallet, err := s.b.AccountManager().Find(account)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := wallet.SignData(account, accounts.MimetypeMetaTransaction, data)
+	if err != nil {
+		return nil, err
+	}
+	if tx, err = tx.WithSponsorSignature(sig); err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{raw, tx}, nil
+}
+
 // PendingTransactions returns the transactions that are in the transaction pool
 // and have a from address that is one of the accounts this node manages.
 func (s *PublicTransactionPoolAPI) PendingTransactions() ([]*RPCTransaction, error) {
@@ -294,3 +294,61 @@ func (args *TransactionArgs) toTransaction() *types.Transaction {
 func (args *TransactionArgs) ToTransaction() *types.Transaction {
 	return args.toTransaction()
 }
+
+// MetaTransactionArgs represents the arguments to cover the fee of a transaction
+// with a fee address, as a meta transaction.
+type MetaTransactionArgs struct {
+	TransactionArgs
+	FeeAddress    *common.Address `json:"feeAddress"`    // Address paying feePercent of the fee
+	FeePercent    *hexutil.Uint64 `json:"feePercent"`    // Part of the fee covered, in hundredths of a percent
+	BlockNumLimit *hexutil.Uint64 `json:"blockNumLimit"` // Last block the fee cover is valid in
+}
+
+// toMetaTransaction checks the arguments and wraps the fee cover signed by sign into
+// the transaction. Like a transaction to sign, all the fields must be specified.
+func (args *MetaTransactionArgs) toMetaTransaction(b Backend, sign func(data []byte) ([]byte, error)) (*types.Transaction, error) {
+	if args.From == nil {
+		return nil, errors.New("sender not specified")
+	}
+	if args.FeeAddress == nil {
+		return nil, errors.New("fee address not specified")
+	}
+	if args.Gas == nil {
+		return nil, errors.New("gas not specified")
+	}
+	if args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil {
+		return nil, types.ErrMetaTxType
+	}
+	if args.GasPrice == nil {
+		return nil, errors.New("gasPrice not specified")
+	}
+	if args.Nonce == nil {
+		return nil, errors.New("nonce not specified")
+	}
+	if args.FeePercent == nil {
+		return nil, errors.New("feePercent not specified")
+	}
+	if args.BlockNumLimit == nil {
+		return nil, errors.New("blockNumLimit not specified")
+	}
+	if args.Data != nil && args.Input != nil && !bytes.Equal(*args.Data, *args.Input) {
+		return nil, errors.New(`both "data" and "input" are set and not equal. Please use "input" to pass transaction call data`)
+	}
+	if limit, head := uint64(*args.BlockNumLimit), b.CurrentHeader().Number; limit <= head.Uint64() {
+		return nil, fmt.Errorf("blockNumLimit %d already reached, head is %d", limit, head)
+	}
+	tx := args.toTransaction()
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
+		return nil, err
+	}
+	chainID := b.ChainConfig().ChainID
+	data, err := types.MetaTxSigningData(tx, *args.From, uint64(*args.FeePercent), uint64(*args.BlockNumLimit), chainID)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(data)
+	if err != nil {
+		return nil, err
+	}
+	return types.WrapMetaTx(tx, uint64(*args.FeePercent), uint64(*args.BlockNumLimit), chainID, sig)
+}
@@ -106,6 +106,11 @@ web3._extend({
 			call: 'congress_getSnapshotAtHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getEpochSnapshot',
+			call: 'congress_getEpochSnapshot',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'getValidators',
 			call: 'congress_getValidators',
@@ -117,6 +122,76 @@ web3._extend({
 			call: 'congress_getValidatorsAtHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getDoubleSignEvidences',
+			call: 'congress_getDoubleSignEvidences',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorActivity',
+			call: 'congress_getValidatorActivity',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorActivityOf',
+			call: 'congress_getValidatorActivityOf',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getEpochActivity',
+			call: 'congress_getEpochActivity',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getMissedBlocks',
+			call: 'congress_getMissedBlocks',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getProposerSchedule',
+			call: 'congress_getProposerSchedule',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getBlockRewardBreakdown',
+			call: 'congress_getBlockRewardBreakdown',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getPendingProposals',
+			call: 'congress_getPendingProposals',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getProposal',
+			call: 'congress_getProposal',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'getBlacklist',
+			call: 'congress_getBlacklist',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getEventCheckRules',
+			call: 'congress_getEventCheckRules',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getDenials',
+			call: 'congress_getDenials',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
 	]
 });
 `
@@ -503,6 +578,12 @@ web3._extend({
 			params: 2,
 			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
 		}),
+		new web3._extend.Method({
+			name: 'simulateSysContractUpgrade',
+			call: 'debug_simulateSysContractUpgrade',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null],
+		}),
 	],
 	properties: []
 });
@@ -535,6 +616,18 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'signMetaTransaction',
+			call: 'eth_signMetaTransaction',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'sponsorTransaction',
+			call: 'eth_sponsorTransaction',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputAddressFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'estimateGas',
 			call: 'eth_estimateGas',
@@ -602,6 +695,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter],
 		}),
+		new web3._extend.Method({
+			name: 'checkCompliance',
+			call: 'eth_checkCompliance',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
 		new web3._extend.Method({
 			name: 'feeHistory',
 			call: 'eth_feeHistory',
@@ -642,6 +741,10 @@ web3._extend({
 			name: 'gasPricePrediction',
 			getter: 'eth_gasPricePrediction'
 		}),
+		new web3._extend.Property({
+			name: 'gasPricePredictionV2',
+			getter: 'eth_gasPricePredictionV2'
+		}),
 	]
 });
 `
@@ -746,6 +849,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'signMetaTransaction',
+			call: 'personal_signMetaTransaction',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'unpair',
 			call: 'personal_unpair',
@@ -811,6 +920,12 @@ web3._extend({
 			name: 'jamIndex',
 			getter: 'txpool_jamIndex'
 		}),
+		new web3._extend.Method({
+			name: 'jamIndexHistory',
+			call: 'txpool_jamIndexHistory',
+			params: 1,
+			inputFormatter: [null]
+		}),
 	]
 });
 `
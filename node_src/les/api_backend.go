@@ -71,6 +71,10 @@ func (b *LesApiBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumb
 	if number == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentHeader(), nil
 	}
+	// The light client doesn't follow the validator votes finalizing the blocks
+	if number == rpc.FinalizedBlockNumber {
+		return nil, errors.New("finalized block not supported by light client")
+	}
 	return b.eth.blockchain.GetHeaderByNumberOdr(ctx, uint64(number))
 }
 
@@ -241,6 +245,17 @@ func (b *LesApiBackend) JamIndex() int {
 	return 0 // not implement
 }
 
+func (b *LesApiBackend) JamIndexHistory(window int) []*core.JamSample {
+	return nil // not implement
+}
+
+func (b *LesApiBackend) SubscribeJamIndexEvent(ch chan<- core.JamIndexEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
@@ -292,6 +307,10 @@ func (b *LesApiBackend) PricePrediction(ctx context.Context) ([]uint, error) {
 	return nil, errors.New("not implement")
 }
 
+func (b *LesApiBackend) FeePrediction(ctx context.Context) (*gasprice.FeePrediction, error) {
+	return nil, errors.New("not implement")
+}
+
 func (b *LesApiBackend) ChainDb() ethdb.Database {
 	return b.eth.chainDb
 }
@@ -18,11 +18,13 @@
 package params
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -117,18 +119,18 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
-	AllCongressProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, big.NewInt(2), big.NewInt(3), nil, nil, &CongressConfig{Period: 0, Epoch: 30000}}
+	AllCongressProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, big.NewInt(2), big.NewInt(3), nil, nil, nil, nil, nil, nil, &CongressConfig{Period: 0, Epoch: 30000}}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -216,6 +218,11 @@ type ChainConfig struct {
 	RedCoastBlock *big.Int `json:"redCoastBlock,omitempty"` // RedCoast switch block (nil = no fork, set value ≥ 2 to activate it)
 	SophonBlock   *big.Int `json:"sophonBlock,omitempty"`   // Sophon switch block (nil = no fork, set > RedCoastBlock to activate it)
 
+	DoubleSignBlock  *big.Int `json:"doubleSignBlock,omitempty"`  // DoubleSign switch block (nil = no fork, set > SophonBlock to activate double-sign slashing)
+	FeeShareBlock    *big.Int `json:"feeShareBlock,omitempty"`    // FeeShare switch block (nil = no fork, set > SophonBlock to share fees by gas used and effective tip)
+	EventRulesBlock  *big.Int `json:"eventRulesBlock,omitempty"`  // EventRules switch block (nil = no fork, set > SophonBlock to check event data words and scope rules by contract)
+	SponsoredTxBlock *big.Int `json:"sponsoredTxBlock,omitempty"` // SponsoredTx switch block (nil = no fork, set > SophonBlock to accept sponsored transactions)
+
 	// Various consensus engines
 	Ethash   *EthashConfig   `json:"ethash,omitempty"`
 	Clique   *CliqueConfig   `json:"clique,omitempty"`
@@ -247,6 +254,62 @@ type CongressConfig struct {
 	Epoch  uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
 
 	EnableDevVerification bool `json:"enableDevVerification"` // Enable developer address verification
+
+	ValidatorsLimitBlock *big.Int `json:"validatorsLimitBlock,omitempty"` // Switch block to the validator limits below (nil = no fork, 21 validators and legacy recents rule)
+	MaxValidators        uint64   `json:"maxValidators,omitempty"`        // Max validators allowed to seal (0 = 21)
+	RecentsLimit         uint64   `json:"recentsLimit,omitempty"`         // Number of consecutive blocks a validator may seal only one of (0 = validators/2+1)
+
+	ShuffleBlock *big.Int `json:"shuffleBlock,omitempty"` // Switch block to rotate proposers in an order shuffled at every epoch (nil = no fork, ascending addresses)
+
+	Upgrades []*SysContractUpgrade `json:"upgrades,omitempty"` // System contract upgrades scheduled without a node code change
+}
+
+// SysContractUpgrade is a system contract upgrade described in the chain config. It
+// is applied at the start of its block, before any transaction: the code is replaced,
+// the storage patched, then the contract called once with InitCall if set.
+type SysContractUpgrade struct {
+	Name     string                      `json:"name,omitempty"`     // Name of the upgrade, for the logs only
+	Block    *big.Int                    `json:"block"`              // Block the upgrade is applied at
+	Contract common.Address              `json:"contract"`           // Address of the upgraded system contract
+	Code     hexutil.Bytes               `json:"code,omitempty"`     // New runtime code of the contract (empty = keep the code)
+	Storage  map[common.Hash]common.Hash `json:"storage,omitempty"`  // Storage slots overwritten after the code
+	InitCall hexutil.Bytes               `json:"initCall,omitempty"` // Input of the call made by the coinbase to the upgraded contract (empty = no call)
+}
+
+// equal returns whether two upgrades make the same changes at the same block.
+func (u *SysContractUpgrade) equal(o *SysContractUpgrade) bool {
+	if !configNumEqual(u.Block, o.Block) || u.Contract != o.Contract ||
+		!bytes.Equal(u.Code, o.Code) || !bytes.Equal(u.InitCall, o.InitCall) || len(u.Storage) != len(o.Storage) {
+		return false
+	}
+	for key, value := range u.Storage {
+		if other, ok := o.Storage[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// upgradesUntil returns the system contract upgrades scheduled at or before head,
+// in the order they are applied.
+func (c *CongressConfig) upgradesUntil(head *big.Int) []*SysContractUpgrade {
+	var upgrades []*SysContractUpgrade
+	for _, upgrade := range c.Upgrades {
+		if isForked(upgrade.Block, head) {
+			upgrades = append(upgrades, upgrade)
+		}
+	}
+	return upgrades
+}
+
+// IsValidatorsLimit returns whether num represents a block number after the ValidatorsLimitBlock fork
+func (c *CongressConfig) IsValidatorsLimit(num *big.Int) bool {
+	return isForked(c.ValidatorsLimitBlock, num)
+}
+
+// IsShuffle returns whether num represents a block number after the ShuffleBlock fork
+func (c *CongressConfig) IsShuffle(num *big.Int) bool {
+	return isForked(c.ShuffleBlock, num)
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -267,7 +330,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, RedCoastBlock: %v, Berlin: %v, London: %v, Sophon: %v, Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, RedCoastBlock: %v, Berlin: %v, London: %v, Sophon: %v, DoubleSign: %v, FeeShare: %v, EventRules: %v, SponsoredTx: %v, Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -284,6 +347,10 @@ func (c *ChainConfig) String() string {
 		c.BerlinBlock,
 		c.LondonBlock,
 		c.SophonBlock,
+		c.DoubleSignBlock,
+		c.FeeShareBlock,
+		c.EventRulesBlock,
+		c.SponsoredTxBlock,
 		engine,
 	)
 }
@@ -373,6 +440,26 @@ func (c *ChainConfig) IsSophon(num *big.Int) bool {
 	return isForked(c.SophonBlock, num)
 }
 
+// IsDoubleSign returns whether num represents a block number after the DoubleSignBlock fork
+func (c *ChainConfig) IsDoubleSign(num *big.Int) bool {
+	return isForked(c.DoubleSignBlock, num)
+}
+
+// IsFeeShare returns whether num represents a block number after the FeeShareBlock fork
+func (c *ChainConfig) IsFeeShare(num *big.Int) bool {
+	return isForked(c.FeeShareBlock, num)
+}
+
+// IsEventRules returns whether num represents a block number after the EventRulesBlock fork
+func (c *ChainConfig) IsEventRules(num *big.Int) bool {
+	return isForked(c.EventRulesBlock, num)
+}
+
+// IsSponsoredTx returns whether num represents a block number after the SponsoredTxBlock fork
+func (c *ChainConfig) IsSponsoredTx(num *big.Int) bool {
+	return isForked(c.SponsoredTxBlock, num)
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -438,6 +525,10 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 	for _, cur := range []fork{
 		{name: "redCoastBlock", block: c.RedCoastBlock, minValue: big.NewInt(2)},
 		{name: "sophonBlock", block: c.SophonBlock},
+		{name: "doubleSignBlock", block: c.DoubleSignBlock, optional: true},
+		{name: "feeShareBlock", block: c.FeeShareBlock, optional: true},
+		{name: "eventRulesBlock", block: c.EventRulesBlock, optional: true},
+		{name: "sponsoredTxBlock", block: c.SponsoredTxBlock, optional: true},
 	} {
 		// check minimal fork block
 		if cur.block != nil && cur.minValue != nil {
@@ -463,6 +554,14 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	if c.Congress != nil {
+		for i, upgrade := range c.Congress.Upgrades {
+			// Block 1 initializes the system contracts, so upgrade them afterwards
+			if upgrade.Block == nil || upgrade.Block.Cmp(big.NewInt(2)) < 0 {
+				return fmt.Errorf("invalid system contract upgrade %d: enabled at %v, but it must be at least 2", i, upgrade.Block)
+			}
+		}
+	}
 	return nil
 }
 
@@ -516,6 +615,43 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.RedCoastBlock, newcfg.RedCoastBlock, head) {
 		return newCompatError("RedCoast fork block", c.RedCoastBlock, newcfg.RedCoastBlock)
 	}
+	if isForkIncompatible(c.DoubleSignBlock, newcfg.DoubleSignBlock, head) {
+		return newCompatError("DoubleSign fork block", c.DoubleSignBlock, newcfg.DoubleSignBlock)
+	}
+	if isForkIncompatible(c.FeeShareBlock, newcfg.FeeShareBlock, head) {
+		return newCompatError("FeeShare fork block", c.FeeShareBlock, newcfg.FeeShareBlock)
+	}
+	if isForkIncompatible(c.EventRulesBlock, newcfg.EventRulesBlock, head) {
+		return newCompatError("EventRules fork block", c.EventRulesBlock, newcfg.EventRulesBlock)
+	}
+	if isForkIncompatible(c.SponsoredTxBlock, newcfg.SponsoredTxBlock, head) {
+		return newCompatError("SponsoredTx fork block", c.SponsoredTxBlock, newcfg.SponsoredTxBlock)
+	}
+	if c.Congress != nil && newcfg.Congress != nil {
+		if isForkIncompatible(c.Congress.ValidatorsLimitBlock, newcfg.Congress.ValidatorsLimitBlock, head) {
+			return newCompatError("Congress validators limit fork block", c.Congress.ValidatorsLimitBlock, newcfg.Congress.ValidatorsLimitBlock)
+		}
+		if isForkIncompatible(c.Congress.ShuffleBlock, newcfg.Congress.ShuffleBlock, head) {
+			return newCompatError("Congress shuffle fork block", c.Congress.ShuffleBlock, newcfg.Congress.ShuffleBlock)
+		}
+		// The limits can't be changed once they are in use
+		if isForked(c.Congress.ValidatorsLimitBlock, head) &&
+			(c.Congress.MaxValidators != newcfg.Congress.MaxValidators || c.Congress.RecentsLimit != newcfg.Congress.RecentsLimit) {
+			return newCompatError("Congress validators limit", c.Congress.ValidatorsLimitBlock, newcfg.Congress.ValidatorsLimitBlock)
+		}
+		// The upgrades already applied can't be changed, dropped or reordered
+		stored, upgrades := c.Congress.upgradesUntil(head), newcfg.Congress.upgradesUntil(head)
+		for i := 0; i < len(stored) || i < len(upgrades); i++ {
+			switch {
+			case i >= len(stored):
+				return newCompatError("Congress system contract upgrade", nil, upgrades[i].Block)
+			case i >= len(upgrades):
+				return newCompatError("Congress system contract upgrade", stored[i].Block, nil)
+			case !stored[i].equal(upgrades[i]):
+				return newCompatError("Congress system contract upgrade", stored[i].Block, upgrades[i].Block)
+			}
+		}
+	}
 	if isForkIncompatible(c.ArrowGlacierBlock, newcfg.ArrowGlacierBlock, head) {
 		return newCompatError("Arrow Glacier fork block", c.ArrowGlacierBlock, newcfg.ArrowGlacierBlock)
 	}
@@ -100,6 +100,34 @@ func TestCheckCompatible(t *testing.T) {
 			head:    uint64(100),
 			wantErr: nil,
 		},
+		{
+			stored:  &ChainConfig{Congress: &CongressConfig{}},
+			new:     &ChainConfig{Congress: &CongressConfig{Upgrades: []*SysContractUpgrade{{Block: big.NewInt(20), Code: []byte{0x1}}}}},
+			head:    10,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Congress: &CongressConfig{Upgrades: []*SysContractUpgrade{{Block: big.NewInt(5), Code: []byte{0x1}}}}},
+			new:    &ChainConfig{Congress: &CongressConfig{Upgrades: []*SysContractUpgrade{{Block: big.NewInt(5), Code: []byte{0x2}}}}},
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "Congress system contract upgrade",
+				StoredConfig: big.NewInt(5),
+				NewConfig:    big.NewInt(5),
+				RewindTo:     4,
+			},
+		},
+		{
+			stored: &ChainConfig{Congress: &CongressConfig{}},
+			new:    &ChainConfig{Congress: &CongressConfig{Upgrades: []*SysContractUpgrade{{Block: big.NewInt(8)}}}},
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "Congress system contract upgrade",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(8),
+				RewindTo:     7,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -126,6 +154,9 @@ func TestCheckConfigForkOrder(t *testing.T) {
 		{new: &ChainConfig{RedCoastBlock: big.NewInt(1)}, isErr: true},
 		{new: &ChainConfig{SophonBlock: big.NewInt(3)}, isErr: true},
 		{new: &ChainConfig{RedCoastBlock: big.NewInt(2), SophonBlock: big.NewInt(2)}, isErr: true},
+		{new: &ChainConfig{Congress: &CongressConfig{Upgrades: []*SysContractUpgrade{{Block: big.NewInt(2)}}}}},
+		{new: &ChainConfig{Congress: &CongressConfig{Upgrades: []*SysContractUpgrade{{Block: big.NewInt(1)}}}}, isErr: true},
+		{new: &ChainConfig{Congress: &CongressConfig{Upgrades: []*SysContractUpgrade{{}}}}, isErr: true},
 	}
 	for _, tc := range tests {
 		err := tc.new.CheckConfigForkOrder()